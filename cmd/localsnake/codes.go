@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+)
+
+const (
+	codeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	codeLength   = 9
+)
+
+func newCodesCommand() *cobra.Command {
+	codesCmd := &cobra.Command{
+		Use:   "codes",
+		Short: "Manage team login codes",
+		Long:  "Manage team login codes",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	var count int
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate new login codes",
+		Long:  "Generate random 9-letter login codes, store them and print one per line.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if count < 1 {
+				return fmt.Errorf("--count must be positive")
+			}
+
+			ctx := cmd.Context()
+			if err := openDB(ctx); err != nil {
+				return err
+			}
+			defer database.Close()
+
+			codes := controllers.NewCodeController(database.DB)
+			for generated := 0; generated < count; {
+				code, err := randomCode()
+				if err != nil {
+					return err
+				}
+				existing, err := codes.FindCode(ctx, code)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					continue
+				}
+				if _, err := codes.CreateCode(ctx, code); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), code)
+				generated++
+			}
+			return nil
+		},
+	}
+	generateCmd.Flags().IntVarP(&count, "count", "n", 20, "Number of codes to generate")
+
+	codesCmd.AddCommand(generateCmd)
+	return codesCmd
+}
+
+func randomCode() (string, error) {
+	code := make([]byte, codeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
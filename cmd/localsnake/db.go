@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	schema "github.com/secomp2025/localsnake/sql"
+)
+
+// Login code and name of the admin team, as created by scripts/setup_db.sh.
+const (
+	adminCode     = "ADMBSNAKE"
+	adminTeamName = "Administração"
+)
+
+// openDB opens the database selected with --db.
+func openDB(ctx context.Context) error {
+	initCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return database.Init(initCtx, dbPath)
+}
+
+func newDBCommand() *cobra.Command {
+	dbCmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the database",
+		Long:  "Manage the database",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Create or upgrade the database schema",
+		Long:  "Create missing tables and indexes and make sure the admin team exists. Safe to run on a database in use.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := openDB(ctx); err != nil {
+				return err
+			}
+			defer database.Close()
+
+			if err := database.Migrate(ctx, schema.Schema); err != nil {
+				return err
+			}
+			if err := ensureAdminTeam(ctx); err != nil {
+				return fmt.Errorf("create admin team: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Database up to date:", dbPath)
+			return nil
+		},
+	}
+
+	dbCmd.AddCommand(migrateCmd)
+	return dbCmd
+}
+
+func ensureAdminTeam(ctx context.Context) error {
+	codes := controllers.NewCodeController(database.DB)
+	teams := controllers.NewTeamController(database.DB)
+
+	code, err := codes.FindCode(ctx, adminCode)
+	if err != nil {
+		return err
+	}
+	if code == nil {
+		if code, err = codes.CreateCode(ctx, adminCode); err != nil {
+			return err
+		}
+	}
+
+	team, err := teams.GetTeamByCode(ctx, code.ID)
+	if err != nil || team != nil {
+		return err
+	}
+	_, err = teams.CreateAdminTeam(ctx, adminTeamName, code.ID)
+	return err
+}
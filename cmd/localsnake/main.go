@@ -0,0 +1,76 @@
+// Command localsnake runs the localsnake web server and the tools admins use
+// from a terminal on the event machine.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/logging"
+)
+
+// dbPath is the SQLite database used by every subcommand.
+var dbPath string
+
+// logLevel and logFormat configure the logger of every subcommand.
+var logLevel, logFormat string
+
+// mapsDir holds the custom maps the games can be played on.
+var mapsDir string
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	// a second signal kills the process right away
+	context.AfterFunc(ctx, stop)
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:          "localsnake",
+		Short:        "Battlesnake competition server",
+		Long:         "Run the localsnake web server, local games and the admin tools.",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := logging.Setup(os.Stderr, logLevel, logFormat); err != nil {
+				return err
+			}
+			// serve loads them itself, as the directory uploads go to
+			if cmd.Name() != "serve" {
+				if _, err := game.LoadCustomMaps(mapsDir); err != nil {
+					slog.Warn("Some custom maps were not loaded", "dir", mapsDir, "err", err)
+				}
+			}
+			return nil
+		},
+	}
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "a.db", "Path of the SQLite database")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", os.Getenv("LOG_LEVEL"), "Least level logged: debug, info, warn or error (default from LOG_LEVEL, else info)")
+	rootCmd.PersistentFlags().StringVar(&mapsDir, "maps-dir", controllers.DefaultMapsDir, "Directory of the custom JSON and YAML maps")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", os.Getenv("LOG_FORMAT"), "Log output: text or json (default from LOG_FORMAT, else text)")
+
+	mapCmd := game.NewMapCommand()
+	mapCmd.AddCommand(game.NewMapListCommand(), game.NewMapInfoCommand())
+
+	rootCmd.AddCommand(
+		newServeCommand(),
+		game.NewPlayCommand(),
+		game.NewBatchCommand(),
+		mapCmd,
+		newDBCommand(),
+		newCodesCommand(),
+		newReplayCommand(),
+	)
+	return rootCmd
+}
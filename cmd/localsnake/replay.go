@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+)
+
+func newReplayCommand() *cobra.Command {
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Manage game replays",
+		Long:  "Manage game replays",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	var output, format string
+	exportCmd := &cobra.Command{
+		Use:   "export [flags] game_id",
+		Short: "Export the replay of a recorded game",
+		Long:  "Export the replay of a recorded game, either as the raw JSONL file or as a single JSON document.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "jsonl" && format != "json" {
+				return fmt.Errorf("unknown format %q, use jsonl or json", format)
+			}
+
+			ctx := cmd.Context()
+			if err := openDB(ctx); err != nil {
+				return err
+			}
+			defer database.Close()
+
+			games := controllers.NewGameController(database.DB)
+			g, err := games.GetGame(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			if g == nil {
+				return fmt.Errorf("game %s not found", args[0])
+			}
+			if !g.ReplayPath.Valid {
+				return fmt.Errorf("game %s has no replay", g.ID)
+			}
+
+			src, err := os.Open(g.ReplayPath.String)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			dst := cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				dst = f
+			}
+
+			if format == "jsonl" {
+				_, err = io.Copy(dst, src)
+				return err
+			}
+
+			replay, err := game.ReadReplay(src)
+			if err != nil {
+				return fmt.Errorf("read replay %s: %w", g.ReplayPath.String, err)
+			}
+			enc := json.NewEncoder(dst)
+			enc.SetIndent("", "  ")
+			return enc.Encode(replay)
+		},
+	}
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "File to write to instead of stdout")
+	exportCmd.Flags().StringVarP(&format, "format", "f", "jsonl", "Output format: jsonl or json")
+
+	replayCmd.AddCommand(exportCmd)
+	return replayCmd
+}
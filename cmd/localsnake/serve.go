@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/server"
+)
+
+func newServeCommand() *cobra.Command {
+	cfg := server.Config{}
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the web server",
+		Long:  "Run the web server until interrupted, then shut down gracefully.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.DBPath = dbPath
+			cfg.MapsDir = mapsDir
+			return server.Run(cmd.Context(), cfg)
+		},
+	}
+
+	serveCmd.Flags().StringVar(&cfg.Addr, "addr", ":3000", "Address to listen on")
+	serveCmd.Flags().IntVar(&cfg.MaxGames, "max-games", controllers.DefaultMaxRunningGames, "Games that may run at the same time, more are refused as busy")
+	cfg.Timeouts.TimeoutPolicy = game.TimeoutRepeatLast
+	serveCmd.Flags().Var(&cfg.Timeouts.TimeoutPolicy, "timeout-policy", "Move of a snake that times out: repeat, random-safe or eliminate")
+	serveCmd.Flags().IntVar(&cfg.Timeouts.MaxTimeouts, "max-timeouts", game.DefaultMaxTimeouts, "Consecutive timeouts that eliminate a snake with --timeout-policy eliminate")
+	serveCmd.Flags().IntVar(&cfg.Timeouts.TimeBank, "time-bank", 0, "Extra milliseconds each snake may spend over a game on slow moves")
+	cfg.SnakeRuntime = controllers.RuntimeHTTP
+	serveCmd.Flags().Var(&cfg.SnakeRuntime, "snake-runtime", "How uploaded snakes are played: http, a server per snake on a reserved port, or stdio, a process per snake and per game")
+	limits := controllers.DefaultResourceLimits
+	cfg.ResourceLimits = &limits
+	serveCmd.Flags().Float64Var(&limits.CPUPercent, "snake-max-cpu", limits.CPUPercent, "CPU percent (100 for a core) a snake process may use before it is killed, 0 for no limit; it is warned at 75%")
+	serveCmd.Flags().IntVar(&limits.RSSMB, "snake-max-rss", limits.RSSMB, "Resident memory in MB a snake process may use before it is killed, 0 for no limit; it is warned at 75%")
+	serveCmd.Flags().IntVar(&limits.Threads, "snake-max-threads", limits.Threads, "Threads a snake process may run before it is killed, 0 for no limit; it is warned at 75%")
+	serveCmd.Flags().IntVar(&limits.OpenFiles, "snake-max-files", limits.OpenFiles, "Files a snake process may keep open before it is killed, 0 for no limit; it is warned at 75%")
+	serveCmd.Flags().BoolVar(&cfg.DevMode, "dev", os.Getenv("DEV_MODE") == "1", "Serve ./static from disk and skip the shutdown drain (default from DEV_MODE=1)")
+
+	return serveCmd
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/secomp2025/localsnake/database"
+)
+
+// Audit event actions.
+const (
+	AuditLogin       = "login"
+	AuditLoginFailed = "login.failed"
+	AuditAdminLogin  = "admin.login"
+	AuditLogout      = "logout"
+	AuditRegister    = "register"
+	AuditUpload      = "upload"
+	AuditRerun       = "rerun"
+	AuditBattle      = "battle.start"
+	AuditGame        = "game.start"
+	AuditRematch     = "game.rematch"
+	AuditGameAbort   = "game.abort"
+	AuditGamePause   = "game.pause"
+	AuditGameResume  = "game.resume"
+	AuditGameStep    = "game.step"
+	AuditGameDelay   = "game.delay"
+	AuditBatch       = "batch.start"
+	AuditTokenCreate = "token.create"
+	AuditTokenRevoke = "token.revoke"
+	AuditExport      = "audit.export"
+	AuditMapUpload   = "map.upload"
+)
+
+// AuditActions lists every audit action, in the order shown in filters.
+var AuditActions = []string{
+	AuditLogin,
+	AuditLoginFailed,
+	AuditAdminLogin,
+	AuditLogout,
+	AuditRegister,
+	AuditUpload,
+	AuditRerun,
+	AuditBattle,
+	AuditGame,
+	AuditRematch,
+	AuditGameAbort,
+	AuditGamePause,
+	AuditGameResume,
+	AuditGameStep,
+	AuditGameDelay,
+	AuditBatch,
+	AuditTokenCreate,
+	AuditTokenRevoke,
+	AuditExport,
+	AuditMapUpload,
+}
+
+type AuditController struct {
+	queries *database.Queries
+}
+
+func NewAuditController(db database.DBTX) *AuditController {
+	return &AuditController{queries: database.New(database.Timed(db))}
+}
+
+func (c *AuditController) RecordEvent(ctx context.Context, event database.CreateAuditEventParams) (*database.AuditEvent, error) {
+	event_model, err := c.queries.CreateAuditEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	return &event_model, nil
+}
+
+// ListEvents returns the events matching the filter, newest first. A
+// negative limit returns every match.
+func (c *AuditController) ListEvents(ctx context.Context, filter database.ListAuditEventsParams) ([]database.AuditEvent, error) {
+	return c.queries.ListAuditEvents(ctx, filter)
+}
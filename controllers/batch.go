@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/secomp2025/localsnake/game"
+)
+
+// BatchJob is a batch of headless games started by an admin.
+type BatchJob struct {
+	ID          int64
+	Snakes      []game.Snake
+	Games       int
+	Parallelism int
+	Seed        int64
+	Finished    int
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Stats       *game.BatchStats
+	Err         error
+
+	cancel context.CancelFunc
+}
+
+// Running reports whether the batch is still playing games.
+func (job *BatchJob) Running() bool {
+	return job.FinishedAt.IsZero()
+}
+
+type batchManager struct {
+	lock   sync.Mutex
+	nextID int64
+	jobs   []*BatchJob
+}
+
+// maxBatchJobs is how many batch jobs are kept in memory, finished jobs
+// beyond it are dropped oldest first.
+const maxBatchJobs = 20
+
+var globalBatchManager = &batchManager{nextID: 1}
+
+type BatchController struct {
+	manager *batchManager
+}
+
+func NewBatchController() *BatchController {
+	return &BatchController{manager: globalBatchManager}
+}
+
+// StartBatch plays games between snakes in the background.
+func (c *BatchController) StartBatch(snakes []game.Snake, games int, parallelism int, seed int64) *BatchJob {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.manager.lock.Lock()
+	job := &BatchJob{
+		ID:          c.manager.nextID,
+		Snakes:      snakes,
+		Games:       games,
+		Parallelism: parallelism,
+		Seed:        seed,
+		StartedAt:   time.Now(),
+		cancel:      cancel,
+	}
+	c.manager.nextID++
+	c.manager.jobs = append(c.manager.jobs, job)
+	c.manager.prune()
+	c.manager.lock.Unlock()
+
+	template := game.GameState{
+		Width:           11,
+		Height:          11,
+		Timeout:         500,
+		GameType:        "standard",
+		MapName:         "standard",
+		Seed:            seed,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+	}
+	for _, snake := range snakes {
+		template.Names = append(template.Names, snake.Name)
+		template.URLs = append(template.URLs, snake.URL)
+	}
+
+	go func() {
+		defer cancel()
+		stats, err := game.RunBatch(ctx, game.BatchOptions{
+			Template:    template,
+			Games:       games,
+			Parallelism: parallelism,
+			OnGameEnd: func(finished int, _ game.Result) {
+				c.manager.lock.Lock()
+				job.Finished = finished
+				c.manager.lock.Unlock()
+			},
+		})
+
+		c.manager.lock.Lock()
+		job.Stats = stats
+		job.Err = err
+		job.FinishedAt = time.Now()
+		c.manager.lock.Unlock()
+	}()
+
+	return job
+}
+
+// CancelBatch stops a job: the running games are aborted and no new one is
+// started. The stats of the job only cover the games played to their end.
+func (c *BatchController) CancelBatch(id int64) bool {
+	c.manager.lock.Lock()
+	defer c.manager.lock.Unlock()
+	for _, job := range c.manager.jobs {
+		if job.ID == id {
+			job.cancel()
+			return true
+		}
+	}
+	return false
+}
+
+// ListBatches returns a snapshot of the jobs, newest first.
+func (c *BatchController) ListBatches() []BatchJob {
+	c.manager.lock.Lock()
+	defer c.manager.lock.Unlock()
+	jobs := make([]BatchJob, 0, len(c.manager.jobs))
+	for _, job := range slices.Backward(c.manager.jobs) {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// prune drops the oldest finished jobs over maxBatchJobs. The caller holds
+// the lock.
+func (m *batchManager) prune() {
+	for len(m.jobs) > maxBatchJobs {
+		i := slices.IndexFunc(m.jobs, func(job *BatchJob) bool { return !job.Running() })
+		if i < 0 {
+			return
+		}
+		m.jobs = slices.Delete(m.jobs, i, i+1)
+	}
+}
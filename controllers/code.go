@@ -12,7 +12,7 @@ type CodeController struct {
 }
 
 func NewCodeController(db database.DBTX) *CodeController {
-	return &CodeController{queries: database.New(db)}
+	return &CodeController{queries: database.New(database.Timed(db))}
 }
 
 func (c *CodeController) GetCode(ctx context.Context, id int64) (*database.Code, error) {
@@ -37,6 +37,14 @@ func (c *CodeController) FindCode(ctx context.Context, code string) (*database.C
 	return &code_model, nil
 }
 
+func (c *CodeController) CreateCode(ctx context.Context, code string) (*database.Code, error) {
+	code_model, err := c.queries.CreateCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return &code_model, nil
+}
+
 func (c *CodeController) ListCodes(ctx context.Context) ([]database.Code, error) {
 	codes, err := c.queries.ListCodes(ctx)
 	if err != nil {
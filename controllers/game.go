@@ -1,55 +1,627 @@
 package controllers
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/secomp2025/localsnake/database"
 	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+// replayPath is where finished games are exported as JSONL replays.
+const replayPath = "replays"
+
+const (
+	// finishedGameTTL is how long a finished game stays in memory, so that
+	// spectators can still watch it, before only its replay is left.
+	finishedGameTTL = 10 * time.Minute
+	// maxFinishedGames bounds the finished games kept in memory, the oldest
+	// ones are archived first when many games end within finishedGameTTL.
+	maxFinishedGames = 50
+	// a finished game is archived only once its result is recorded, failed
+	// writes are retried every recordRetryDelay up to maxRecordAttempts times
+	recordRetryDelay  = 30 * time.Second
+	maxRecordAttempts = 5
+)
+
+var gamesFinished = metrics.NewCounter(
+	"localsnake_games_finished_total",
+	"Games played to the end, by outcome: win, draw, aborted or error.",
+	"outcome",
+)
+
+// DefaultMaxRunningGames is how many games may be played at the same time
+// unless SetMaxRunningGames says otherwise.
+const DefaultMaxRunningGames = 32
+
+// GamePhase is the step of its lifecycle a game is in.
+type GamePhase string
+
+const (
+	// GameCreated games are waiting for their snakes to answer /start.
+	GameCreated GamePhase = "created"
+	// GameRunning games are playing turns.
+	GameRunning GamePhase = "running"
+	// GameFinished games are over but still in memory for spectators.
+	GameFinished GamePhase = "finished"
+	// GameArchived games only exist in the database and as a replay.
+	GameArchived GamePhase = "archived"
 )
 
 type gameManager struct {
 	lock  sync.RWMutex
 	games map[string]GameInfo
+	// running counts the games whose result is not recorded yet
+	running sync.WaitGroup
+	// active counts the created and running games, up to maxRunning
+	active     int
+	maxRunning int
+	timeouts   game.TimeoutOptions
+	closed     bool // set by ShutdownGames, no game starts anymore
 }
 
+var (
+	// ErrGamesClosed is returned by CreateGame once the server shuts down.
+	ErrGamesClosed = errors.New("the server is shutting down")
+	// ErrServerBusy is returned by CreateGame when the limit of running
+	// games is reached.
+	ErrServerBusy = errors.New("too many games running")
+	// ErrInvalidGame is returned by CreateGame when the settings of the
+	// game, such as its map, fog or squads, do not allow playing it.
+	ErrInvalidGame = errors.New("invalid game")
+	// ErrGameNotRunning is returned by ControlGame for the games not being
+	// played.
+	ErrGameNotRunning = errors.New("the game is not running")
+)
+
 type GameInfo struct {
-	ID     string
-	Server *game.BoardServer
-	Snakes []game.Snake
+	ID        string
+	Server    *game.BoardServer
+	Snakes    []game.Snake
+	State     *game.GameState
+	StartedAt time.Time
+	// FinishedAt is set once the game is over.
+	FinishedAt time.Time
+
+	cancel    context.CancelCauseFunc
+	done      chan struct{} // closed once the game is over
+	persisted bool          // the result is recorded, the game may be archived
+}
+
+// Running reports whether the game is still being played.
+func (g GameInfo) Running() bool {
+	select {
+	case <-g.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Phase returns the step of its lifecycle the game is in. Games still in
+// memory are never GameArchived.
+func (g GameInfo) Phase() GamePhase {
+	switch {
+	case !g.Running():
+		return GameFinished
+	case g.State.Started():
+		return GameRunning
+	default:
+		return GameCreated
+	}
 }
 
 type GameController struct {
 	manager *gameManager
+	queries *database.Queries
 }
 
 var globalGameManager *gameManager
 
 func init() {
 	globalGameManager = &gameManager{
-		games: make(map[string]GameInfo),
+		games:      make(map[string]GameInfo),
+		maxRunning: DefaultMaxRunningGames,
 	}
 }
 
-func NewGameController() *GameController {
+// SetMaxRunningGames changes how many games may be played at the same time.
+// CreateGame returns ErrServerBusy beyond it.
+func SetMaxRunningGames(n int) {
+	globalGameManager.lock.Lock()
+	defer globalGameManager.lock.Unlock()
+	globalGameManager.maxRunning = n
+}
+
+func NewGameController(db database.DBTX) *GameController {
 	return &GameController{
 		manager: globalGameManager,
+		queries: database.New(database.Timed(db)),
+	}
+}
+
+// GameOptions are the settings of a game kept with it, so that a rematch
+// plays with the same ones.
+type GameOptions struct {
+	// Seed of the game, a new one when zero.
+	Seed int64
+	// Map is the ID of the map, the standard map when empty.
+	Map        string
+	Fog        game.FogOptions
+	SquadRules game.SquadRules
+}
+
+// StoredGameOptions returns the options a recorded game was played with.
+func StoredGameOptions(g *database.Game) GameOptions {
+	// the rules were checked before the game was recorded
+	squadRules, _ := game.ParseSquadRules(g.SquadRules.String)
+	return GameOptions{
+		Seed: g.Seed,
+		Map:  g.Map.String,
+		Fog: game.FogOptions{
+			Fog:           game.FogMode(g.Fog.String),
+			VisionRadius:  int(g.VisionRadius.Int64),
+			VisionOverlay: g.VisionOverlay.Bool,
+		},
+		SquadRules: squadRules,
 	}
 }
 
-func (c *GameController) CreateGame(snakes []game.Snake) GameInfo {
+// CreateGame starts a game between the given snakes and records it in the
+// database. The game keeps running even if recording it fails. ctx only
+// scopes the database writes, the game runs until it ends, AbortGame or
+// ShutdownGames.
+//
+// It fails with ErrServerBusy when SetMaxRunningGames games are already
+// being played, with ErrGamesClosed once the server shuts down and with
+// ErrInvalidGame when the game cannot be played; no game starts then.
+func (c *GameController) CreateGame(ctx context.Context, snakes []game.Snake, opts GameOptions) (GameInfo, error) {
+	// the slot of the game is taken under the lock, the game is set up and
+	// recorded out of it so that a slow database stalls no other game
 	c.manager.lock.Lock()
-	defer c.manager.lock.Unlock()
+	if c.manager.closed {
+		c.manager.lock.Unlock()
+		return GameInfo{}, ErrGamesClosed
+	}
+	if c.manager.active >= c.manager.maxRunning {
+		c.manager.lock.Unlock()
+		return GameInfo{}, ErrServerBusy
+	}
+	c.manager.running.Add(1)
+	c.manager.active++
+	timeouts := c.manager.timeouts
+	c.manager.lock.Unlock()
 
-	gameID, boardServer := game.CreateGame(snakes)
-	// go func() {
-	// 	defer boardServer.Shutdown()
-	// }()
+	gameCtx, cancel := context.WithCancelCause(context.Background())
+	done := make(chan struct{})
+	// closed once the game row exists, its result is recorded after it
+	recorded := make(chan struct{})
+	gameState, boardServer, err := game.CreateGame(gameCtx, snakes, game.CreateOptions{
+		OutputDir: replayPath,
+		OnEnd: func(result game.Result) {
+			defer c.manager.running.Done()
+			defer cancel(nil)
+			<-recorded
+			c.endGame(result, done)
+		},
+		Seed:       opts.Seed,
+		Timeouts:   timeouts,
+		Map:        opts.Map,
+		Fog:        opts.Fog,
+		SquadRules: opts.SquadRules,
+	})
+	if err != nil {
+		cancel(nil)
+		c.manager.lock.Lock()
+		c.manager.active--
+		c.manager.lock.Unlock()
+		c.manager.running.Done()
+		return GameInfo{}, fmt.Errorf("%w: %w", ErrInvalidGame, err)
+	}
+	defer close(recorded)
 
 	gameInfo := GameInfo{
-		ID:     gameID,
-		Server: boardServer,
-		Snakes: snakes,
+		ID:        gameState.ID(),
+		Server:    boardServer,
+		Snakes:    snakes,
+		State:     gameState,
+		StartedAt: time.Now(),
+		cancel:    cancel,
+		done:      done,
+	}
+	c.manager.lock.Lock()
+	c.manager.games[gameInfo.ID] = gameInfo
+	c.manager.lock.Unlock()
+
+	// games without fog of war leave its columns empty
+	fog := gameState.Fog != game.FogOff
+	_, err = c.queries.CreateGame(ctx, database.CreateGameParams{
+		ID:            gameInfo.ID,
+		Seed:          gameState.Seed,
+		ReplayPath:    sql.NullString{String: gameState.OutputPath, Valid: gameState.OutputPath != ""},
+		Map:           sql.NullString{String: gameState.MapName, Valid: true},
+		Fog:           sql.NullString{String: string(gameState.Fog), Valid: fog},
+		VisionRadius:  sql.NullInt64{Int64: int64(gameState.VisionRadius), Valid: fog && gameState.VisionRadius > 0},
+		VisionOverlay: sql.NullBool{Bool: gameState.VisionOverlay, Valid: fog},
+		SquadRules:    sql.NullString{String: gameState.SquadRules.String(), Valid: len(gameState.Squads) > 0},
+	})
+	if err != nil {
+		return gameInfo, err
+	}
+
+	for _, snake := range snakes {
+		_, err := c.queries.CreateGameSnake(ctx, database.CreateGameSnakeParams{
+			GameID:  gameInfo.ID,
+			SnakeID: sql.NullInt64{Int64: snake.SnakeID, Valid: snake.SnakeID != 0},
+			Name:    snake.Name,
+			Url:     snake.URL,
+			Squad:   sql.NullString{String: snake.Squad, Valid: snake.Squad != ""},
+		})
+		if err != nil {
+			return gameInfo, err
+		}
 	}
-	c.manager.games[gameID] = gameInfo
 
-	return gameInfo
+	return gameInfo, nil
+}
+
+// MaxRunningGames returns how many games may be played at the same time.
+func (c *GameController) MaxRunningGames() int {
+	c.manager.lock.RLock()
+	defer c.manager.lock.RUnlock()
+	return c.manager.maxRunning
+}
+
+// SetTimeoutOptions changes how the games started from now on treat snakes
+// that answer /move late.
+func SetTimeoutOptions(opts game.TimeoutOptions) {
+	globalGameManager.lock.Lock()
+	defer globalGameManager.lock.Unlock()
+	globalGameManager.timeouts = opts
+}
+
+// LiveGame returns a game kept in memory: created, running or finished less
+// than finishedGameTTL ago.
+func (c *GameController) LiveGame(id string) (GameInfo, bool) {
+	c.manager.lock.RLock()
+	defer c.manager.lock.RUnlock()
+
+	g, ok := c.manager.games[id]
+	return g, ok
+}
+
+// Phase returns the step of its lifecycle a game is in, GameArchived once it
+// is not in memory anymore.
+func (c *GameController) Phase(id string) GamePhase {
+	if g, ok := c.LiveGame(id); ok {
+		return g.Phase()
+	}
+	return GameArchived
+}
+
+// endGame records the result of a game and moves it to GameFinished. It runs
+// on the game goroutine.
+func (c *GameController) endGame(result game.Result, done chan struct{}) {
+	gamesFinished.Inc(gameOutcome(result))
+	err := c.finishGame(result)
+	if err != nil {
+		slog.Error("Error recording result for game", "game_id", result.GameID, "err", err)
+	}
+
+	c.manager.lock.Lock()
+	defer c.manager.lock.Unlock()
+
+	c.manager.active--
+	close(done)
+	g := c.manager.games[result.GameID]
+	g.FinishedAt = time.Now()
+	c.manager.games[result.GameID] = g
+
+	if err == nil {
+		c.persisted(result.GameID)
+	} else {
+		time.AfterFunc(recordRetryDelay, func() { c.retryFinishGame(result, 1) })
+	}
+}
+
+// gameOutcome sums up how a game ended for the games finished metric.
+func gameOutcome(result game.Result) string {
+	switch {
+	case result.Err != nil:
+		if _, ok := game.AbortReason(result.Err); ok {
+			return "aborted"
+		}
+		return "error"
+	case result.IsDraw:
+		return "draw"
+	default:
+		return "win"
+	}
+}
+
+// retryFinishGame records the result of a game whose first write failed. The
+// game is archived anyway after maxRecordAttempts, its replay is on disk.
+func (c *GameController) retryFinishGame(result game.Result, attempt int) {
+	err := c.finishGame(result)
+	if err != nil {
+		slog.Error("Error recording result for game", "game_id", result.GameID, "attempt", attempt+1, "err", err)
+	}
+
+	c.manager.lock.Lock()
+	defer c.manager.lock.Unlock()
+
+	switch {
+	case err == nil:
+		c.persisted(result.GameID)
+	case attempt+1 >= maxRecordAttempts || c.manager.closed:
+		slog.Warn("Giving up recording result for game", "game_id", result.GameID)
+		delete(c.manager.games, result.GameID)
+	default:
+		time.AfterFunc(recordRetryDelay, func() { c.retryFinishGame(result, attempt+1) })
+	}
+}
+
+// persisted marks a finished game as recorded, it is archived after
+// finishedGameTTL or earlier when more than maxFinishedGames are kept.
+// The caller holds the lock.
+func (c *GameController) persisted(id string) {
+	g, ok := c.manager.games[id]
+	if !ok {
+		return
+	}
+	g.persisted = true
+	c.manager.games[id] = g
+	time.AfterFunc(finishedGameTTL, func() { c.archiveGame(id) })
+
+	var finished []GameInfo
+	for _, g := range c.manager.games {
+		if g.persisted {
+			finished = append(finished, g)
+		}
+	}
+	if len(finished) <= maxFinishedGames {
+		return
+	}
+	slices.SortFunc(finished, func(a, b GameInfo) int { return a.FinishedAt.Compare(b.FinishedAt) })
+	for _, g := range finished[:len(finished)-maxFinishedGames] {
+		delete(c.manager.games, g.ID)
+	}
+}
+
+// archiveGame forgets a finished game, its row and replay are left.
+func (c *GameController) archiveGame(id string) {
+	c.manager.lock.Lock()
+	defer c.manager.lock.Unlock()
+	delete(c.manager.games, id)
+}
+
+// RunningGames lists the games being played, oldest first.
+func (c *GameController) RunningGames() []GameInfo {
+	c.manager.lock.RLock()
+	defer c.manager.lock.RUnlock()
+
+	var games []GameInfo
+	for _, g := range c.manager.games {
+		if g.Running() {
+			games = append(games, g)
+		}
+	}
+	slices.SortFunc(games, func(a, b GameInfo) int { return a.StartedAt.Compare(b.StartedAt) })
+	return games
+}
+
+// LiveGames lists every game still in memory, running or finished, oldest
+// first.
+func (c *GameController) LiveGames() []GameInfo {
+	c.manager.lock.RLock()
+	defer c.manager.lock.RUnlock()
+
+	games := slices.Collect(maps.Values(c.manager.games))
+	slices.SortFunc(games, func(a, b GameInfo) int { return a.StartedAt.Compare(b.StartedAt) })
+	return games
+}
+
+// runningGame returns the game with the given ID if it is being played.
+func (c *GameController) runningGame(id string) (GameInfo, bool) {
+	c.manager.lock.RLock()
+	defer c.manager.lock.RUnlock()
+
+	g, ok := c.manager.games[id]
+	if !ok || !g.Running() {
+		return GameInfo{}, false
+	}
+	return g, true
+}
+
+// AbortGame stops a running game before its next turn. The game is recorded
+// as aborted with the given reason. It returns false when the game is not
+// running.
+func (c *GameController) AbortGame(id string, reason string) bool {
+	g, ok := c.runningGame(id)
+	if !ok {
+		return false
+	}
+	slog.Info("Aborting game", "game_id", id, "reason", reason)
+	g.cancel(game.Abort(reason))
+	return true
+}
+
+// ControlGame applies a command to a running game, see
+// game.GameState.Control, and tells its viewers the new state of the
+// controls.
+func (c *GameController) ControlGame(id string, cmd game.ControlCommand) error {
+	g, ok := c.runningGame(id)
+	if !ok {
+		return ErrGameNotRunning
+	}
+	if err := g.State.Control(cmd); err != nil {
+		return err
+	}
+	slog.Info("Game control", "game_id", id, "command", cmd.Command, "delay_ms", cmd.DelayMS)
+	g.Server.SendEvent(g.State.ControlEvent())
+	return nil
+}
+
+// PauseGame holds a running game before its next turn until ResumeGame.
+func (c *GameController) PauseGame(id string) bool {
+	return c.ControlGame(id, game.ControlCommand{Command: game.ControlPause}) == nil
+}
+
+// ResumeGame continues a game paused with PauseGame.
+func (c *GameController) ResumeGame(id string) bool {
+	return c.ControlGame(id, game.ControlCommand{Command: game.ControlResume}) == nil
+}
+
+// IsPaused reports whether a running game is paused.
+func (c *GameController) IsPaused(id string) bool {
+	g, ok := c.runningGame(id)
+	return ok && g.State.Paused()
+}
+
+// TurnDelay returns the turn delay of a running game, in milliseconds.
+func (c *GameController) TurnDelay(id string) int {
+	g, ok := c.runningGame(id)
+	if !ok {
+		return 0
+	}
+	return g.State.CurrentTurnDelay()
+}
+
+// ShutdownGames lets the running games end until ctx is done, then aborts
+// the remaining ones and waits for their results to be recorded.
+func ShutdownGames(ctx context.Context) {
+	globalGameManager.lock.Lock()
+	globalGameManager.closed = true
+	globalGameManager.lock.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		globalGameManager.running.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return
+	case <-ctx.Done():
+	}
+
+	games := NewGameController(database.DB).RunningGames()
+	slog.Info("Aborting running games", "games", len(games))
+	for _, g := range games {
+		g.cancel(game.Abort("server shutdown"))
+	}
+	<-drained
+}
+
+func (c *GameController) GetGame(ctx context.Context, id string) (*database.Game, error) {
+	game_model, err := c.queries.GetGame(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &game_model, nil
+}
+
+func (c *GameController) ListGames(ctx context.Context, limit, offset int64) ([]database.Game, error) {
+	return c.queries.ListGames(ctx, database.ListGamesParams{Limit: limit, Offset: offset})
+}
+
+func (c *GameController) CountGames(ctx context.Context) (int64, error) {
+	return c.queries.CountGames(ctx)
+}
+
+func (c *GameController) ListGameSnakes(ctx context.Context, game_id string) ([]database.GameSnake, error) {
+	return c.queries.ListGameSnakes(ctx, game_id)
+}
+
+// finishGame stores the outcome of a game, once CreateGame recorded it.
+func (c *GameController) finishGame(result game.Result) error {
+	status := "finished"
+	var abortReason sql.NullString
+	if reason, ok := game.AbortReason(result.Err); ok {
+		status = "aborted"
+		abortReason = sql.NullString{String: reason, Valid: true}
+	} else if result.Err != nil {
+		status = "error"
+	}
+
+	ctx := context.Background()
+	err := c.queries.FinishGame(ctx, database.FinishGameParams{
+		Status:        status,
+		Turns:         int64(result.Turns),
+		Winner:        sql.NullString{String: result.WinnerName, Valid: result.WinnerName != ""},
+		IsDraw:        sql.NullBool{Bool: result.IsDraw, Valid: true},
+		AbortReason:   abortReason,
+		TimeoutPolicy: sql.NullString{String: string(result.TimeoutPolicy), Valid: result.TimeoutPolicy != ""},
+		WinnerSquad:   sql.NullString{String: result.WinnerSquad, Valid: result.WinnerSquad != ""},
+		ID:            result.GameID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the rows of the snakes are in the order of the snakes of the game, a
+	// snake may play the same game twice under the same URL
+	rows, err := c.queries.ListGameSnakes(ctx, result.GameID)
+	if err != nil {
+		return fmt.Errorf("listing game snakes: %w", err)
+	}
+	if len(rows) != len(result.Snakes) {
+		return fmt.Errorf("recorded %d snakes for the %d of the game", len(rows), len(result.Snakes))
+	}
+
+	for i, snake := range result.Snakes {
+		if len(snake.Latencies) == 0 {
+			continue
+		}
+		histogram, err := json.Marshal(game.NewLatencyHistogram(snake.Latencies))
+		if err != nil {
+			return err
+		}
+		err = c.queries.SetGameSnakeStats(ctx, database.SetGameSnakeStatsParams{
+			Moves:            int64(len(snake.Latencies)),
+			Timeouts:         int64(snake.Timeouts),
+			StatusErrors:     int64(snake.StatusErrors),
+			DecodeErrors:     int64(snake.DecodeErrors),
+			LatencyHistogram: sql.NullString{String: string(histogram), Valid: true},
+			GameID:           result.GameID,
+			ID:               rows[i].ID,
+		})
+		if err != nil {
+			return fmt.Errorf("recording move stats: %w", err)
+		}
+	}
+
+	if result.WinnerURL != "" {
+		// the winner is the snake left
+		for i, snake := range result.Snakes {
+			if snake.EliminatedCause != rules.NotEliminated {
+				continue
+			}
+			err := c.queries.SetGameWinner(ctx, database.SetGameWinnerParams{GameID: result.GameID, ID: rows[i].ID})
+			if err != nil {
+				return fmt.Errorf("recording winner: %w", err)
+			}
+		}
+	}
+	if result.WinnerSquad != "" {
+		err := c.queries.SetSquadWinner(ctx, database.SetSquadWinnerParams{GameID: result.GameID, Squad: sql.NullString{String: result.WinnerSquad, Valid: true}})
+		if err != nil {
+			return fmt.Errorf("recording winner squad: %w", err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+	schema "github.com/secomp2025/localsnake/sql"
+)
+
+// gatedDB holds the writes to the database until open is closed.
+type gatedDB struct {
+	*sql.DB
+	open chan struct{}
+}
+
+func (db gatedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	<-db.open
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func (db gatedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	<-db.open
+	return db.DB.QueryRowContext(ctx, query, args...)
+}
+
+// testDatabase opens a database with the schema, for the length of the test.
+func testDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+	if err := database.Init(ctx, filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(ctx, schema.Schema); err != nil {
+		t.Fatal(err)
+	}
+	return database.DB
+}
+
+// funcSnake plays up until it leaves the board.
+func funcSnake(name string) game.Snake {
+	return game.Snake{
+		Name: name,
+		URL:  "func://" + name,
+		Provider: game.FuncProvider{MoveFunc: func(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, error) {
+			return client.MoveResponse{Move: "up"}, nil
+		}},
+	}
+}
+
+func TestCreateGameSlowDatabase(t *testing.T) {
+	t.Chdir(t.TempDir())
+	db := gatedDB{DB: testDatabase(t), open: make(chan struct{})}
+	c := &GameController{
+		manager: &gameManager{games: map[string]GameInfo{}, maxRunning: 2},
+		queries: database.New(db),
+	}
+
+	created := make(chan error, 1)
+	go func() {
+		_, err := c.CreateGame(context.Background(), []game.Snake{funcSnake("one"), funcSnake("two")}, GameOptions{Seed: 1})
+		created <- err
+	}()
+
+	// the game is listed and the other games can be looked up while its row
+	// is being written
+	listed := make(chan string)
+	go func() {
+		for {
+			if games := c.LiveGames(); len(games) > 0 {
+				c.LiveGame("other")
+				listed <- games[0].ID
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	var id string
+	select {
+	case id = <-listed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the games cannot be looked up while the database is slow")
+	}
+
+	close(db.open)
+	if err := <-created; err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	// the result is recorded once the game row exists
+	waitForRecord(t, c, id)
+	row, err := c.GetGame(context.Background(), id)
+	if err != nil || row == nil || row.Status != "finished" {
+		t.Errorf("got game row %+v and error %v, want the game finished", row, err)
+	}
+}
+
+// waitForRecord waits for the result of a game to be recorded.
+func waitForRecord(t *testing.T, c *GameController, id string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.manager.lock.RLock()
+		g := c.manager.games[id]
+		c.manager.lock.RUnlock()
+		if g.persisted {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the game is %s, want it finished and recorded", c.Phase(id))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFinishMirrorMatch(t *testing.T) {
+	t.Chdir(t.TempDir())
+	c := &GameController{
+		manager: &gameManager{games: map[string]GameInfo{}, maxRunning: 2},
+		queries: database.New(testDatabase(t)),
+	}
+	// the same snake twice, under the same URL
+	gameInfo, err := c.CreateGame(context.Background(), []game.Snake{funcSnake("one"), funcSnake("one")}, GameOptions{Seed: 1})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	waitForRecord(t, c, gameInfo.ID)
+
+	rows, err := c.ListGameSnakes(context.Background(), gameInfo.ID)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("got rows %+v and error %v, want the two snakes", rows, err)
+	}
+	result := gameInfo.State.Result()
+	winners := 0
+	for i, row := range rows {
+		if row.IsWinner.Bool {
+			winners++
+		}
+		if row.Moves != int64(len(result.Snakes[i].Latencies)) {
+			t.Errorf("snake %d: got %d moves recorded, want its %d", i, row.Moves, len(result.Snakes[i].Latencies))
+		}
+	}
+	if winners != 1 {
+		t.Errorf("got %d winners recorded, want 1", winners)
+	}
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"sync"
+
+	"github.com/secomp2025/localsnake/game"
+)
+
+// DefaultMapsDir is where the custom maps are loaded from and uploaded to.
+const DefaultMapsDir = "maps"
+
+var (
+	mapsDirLock sync.Mutex
+	mapsDir     = DefaultMapsDir
+)
+
+// LoadMaps registers the custom maps of dir, see game.LoadCustomMaps, and
+// makes it the directory SaveMap stores uploads in.
+func LoadMaps(dir string) ([]game.CustomMap, error) {
+	mapsDirLock.Lock()
+	defer mapsDirLock.Unlock()
+	mapsDir = dir
+	return game.LoadCustomMaps(dir)
+}
+
+// SaveMap validates an uploaded map file, stores it in the maps directory and
+// registers it, replacing the map with the same ID.
+func SaveMap(filename string, data []byte) (game.CustomMap, error) {
+	mapsDirLock.Lock()
+	defer mapsDirLock.Unlock()
+	return game.SaveCustomMap(mapsDir, filename, data)
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+)
+
+// MoveStats sums up the /move requests of one snake version over all its
+// recorded games.
+type MoveStats struct {
+	SnakeID      int64
+	TeamID       int64
+	Games        int
+	Moves        int
+	Timeouts     int
+	StatusErrors int
+	DecodeErrors int
+	Latency      game.LatencyHistogram
+}
+
+// Budget is the time a snake has to answer /move in the games started by
+// the server.
+func (s *MoveStats) Budget() time.Duration {
+	return game.DefaultMoveTimeout * time.Millisecond
+}
+
+// P50, P95 and P99 are latency percentiles, rounded up to 10ms.
+func (s *MoveStats) P50() time.Duration { return s.Latency.Percentile(50) }
+func (s *MoveStats) P95() time.Duration { return s.Latency.Percentile(95) }
+func (s *MoveStats) P99() time.Duration { return s.Latency.Percentile(99) }
+
+// add counts the requests of one game.
+func (s *MoveStats) add(moves, timeouts, statusErrors, decodeErrors int64, histogram sql.NullString) {
+	s.Games++
+	s.Moves += int(moves)
+	s.Timeouts += int(timeouts)
+	s.StatusErrors += int(statusErrors)
+	s.DecodeErrors += int(decodeErrors)
+	if !histogram.Valid {
+		return
+	}
+	var h game.LatencyHistogram
+	if err := json.Unmarshal([]byte(histogram.String), &h); err != nil {
+		slog.Error("Error decoding latency histogram of snake", "snake_id", s.SnakeID, "err", err)
+		return
+	}
+	s.Latency.Merge(h)
+}
+
+// SnakeMoveStats returns the move stats of every snake version that played a
+// recorded game, by snake ID.
+func (c *GameController) SnakeMoveStats(ctx context.Context) (map[int64]*MoveStats, error) {
+	rows, err := c.queries.ListSnakeMoveStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sumMoveStats(rows), nil
+}
+
+// TeamMoveStats returns the move stats of the snake versions of a team,
+// newest first.
+func (c *GameController) TeamMoveStats(ctx context.Context, team_id int64) ([]MoveStats, error) {
+	team_rows, err := c.queries.ListTeamSnakeMoveStats(ctx, team_id)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]database.ListSnakeMoveStatsRow, len(team_rows))
+	for i, row := range team_rows {
+		rows[i] = database.ListSnakeMoveStatsRow(row)
+	}
+
+	var stats []MoveStats
+	for _, s := range sumMoveStats(rows) {
+		stats = append(stats, *s)
+	}
+	// snake IDs grow with every upload
+	slices.SortFunc(stats, func(a, b MoveStats) int { return int(b.SnakeID - a.SnakeID) })
+	return stats, nil
+}
+
+// sumMoveStats adds up the per game rows of each snake.
+func sumMoveStats(rows []database.ListSnakeMoveStatsRow) map[int64]*MoveStats {
+	stats := map[int64]*MoveStats{}
+	for _, row := range rows {
+		s, ok := stats[row.SnakeID.Int64]
+		if !ok {
+			s = &MoveStats{SnakeID: row.SnakeID.Int64, TeamID: row.TeamID, Latency: game.LatencyHistogram{}}
+			stats[row.SnakeID.Int64] = s
+		}
+		s.add(row.Moves, row.Timeouts, row.StatusErrors, row.DecodeErrors, row.LatencyHistogram)
+	}
+	return stats
+}
@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+var portsReclaimed = metrics.NewCounter(
+	"localsnake_ports_reclaimed_total",
+	"Ports of the snake server pool taken back, by reason: crashed, leaked or orphan.",
+	"reason",
+)
+
+// ErrNoPorts is returned when every port of the pool is reserved or bound by
+// another process.
+var ErrNoPorts = errors.New("no available ports")
+
+// portReclaimInterval is how often the ports of crashed snake servers and
+// the leaked ones are taken back.
+const portReclaimInterval = 30 * time.Second
+
+// portLeakGrace is how long a port may stay reserved without a snake server
+// on it, the time to start one, before it is taken for leaked.
+const portLeakGrace = time.Minute
+
+// portPool hands out the ports of the snake servers, from base to
+// base+size-1. A port is only handed out if it can be bound, another process
+// may hold it.
+type portPool struct {
+	lock     sync.Mutex
+	base     int
+	size     int
+	reserved map[int]portReservation
+	busy     map[int]bool // ports bound by another process on the last try
+	// bindable checks that a port is free, see canBind
+	bindable func(port int) bool
+}
+
+type portReservation struct {
+	snakeID int64
+	since   time.Time
+}
+
+// PortUsage tells how the ports of the snake server pool are used.
+type PortUsage struct {
+	Size int
+	// Reserved ports are handed out to snake servers.
+	Reserved int
+	// Busy ports were bound by another process when last tried.
+	Busy int
+}
+
+func newPortPool(base, size int) *portPool {
+	return &portPool{
+		base:     base,
+		size:     size,
+		reserved: make(map[int]portReservation),
+		busy:     make(map[int]bool),
+		bindable: canBind,
+	}
+}
+
+// canBind reports whether a snake server could listen on port.
+func canBind(port int) bool {
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// reserve hands out a free port to the server of a snake, which must give it
+// back with release once the server stops or fails to start.
+func (p *portPool) reserve(snakeID int64) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for port := p.base; port < p.base+p.size; port++ {
+		if _, ok := p.reserved[port]; ok {
+			continue
+		}
+		if !p.bindable(port) {
+			p.busy[port] = true
+			continue
+		}
+		delete(p.busy, port)
+		p.reserved[port] = portReservation{snakeID: snakeID, since: time.Now()}
+		return port, nil
+	}
+	return 0, ErrNoPorts
+}
+
+// release gives a port back to the pool.
+func (p *portPool) release(port int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.reserved, port)
+}
+
+// leaked releases the ports reserved for longer than portLeakGrace that no
+// server uses, as told by inUse, and returns them.
+func (p *portPool) leaked(inUse func(port int, snakeID int64) bool) []int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var ports []int
+	for port, reservation := range p.reserved {
+		if time.Since(reservation.since) < portLeakGrace || inUse(port, reservation.snakeID) {
+			continue
+		}
+		delete(p.reserved, port)
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+func (p *portPool) usage() PortUsage {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return PortUsage{Size: p.size, Reserved: len(p.reserved), Busy: len(p.busy)}
+}
+
+// reclaimPorts takes back the ports of the snake servers that crashed and
+// the leaked ones, until the manager is destroyed.
+func (c *SnakeServerController) reclaimPorts() {
+	ticker := time.NewTicker(portReclaimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.reclaimCrashedServers()
+			c.reclaimLeakedPorts()
+		}
+	}
+}
+
+// reclaimCrashedServers removes the servers whose process exited, so that
+// their port goes back to the pool and the snake can be managed again.
+func (c *SnakeServerController) reclaimCrashedServers() {
+	c.lock.RLock()
+	var crashed []int64
+	for snakeID, server := range c.servers {
+		if server.processStatus() == "exited" {
+			crashed = append(crashed, snakeID)
+		}
+	}
+	c.lock.RUnlock()
+
+	for _, snakeID := range crashed {
+		slog.Warn("Snake server crashed, releasing its port", "snake_id", snakeID)
+		c.stopAndRemoveServer(snakeID)
+		portsReclaimed.Inc("crashed")
+	}
+}
+
+// reclaimLeakedPorts releases the reservations no server uses.
+func (c *SnakeServerController) reclaimLeakedPorts() {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	leaked := c.ports.leaked(func(port int, snakeID int64) bool {
+		server, ok := c.servers[snakeID]
+		return ok && server.port == port
+	})
+	for _, port := range leaked {
+		slog.Warn("Releasing leaked port", "port", port)
+		portsReclaimed.Inc("leaked")
+	}
+}
+
+// serverRecord is the pidfile of a snake server, named after its PID in the
+// pids directory: it tells which run of localsnake started the server, so
+// that a later run only kills the servers of runs that are gone. Start times
+// tell the processes apart from later ones given the same PID.
+type serverRecord struct {
+	ownerPID   int
+	ownerStart string
+	start      string
+}
+
+// processStart returns the start time of a process, field 22 of proc(5).
+func processStart(pid int) (string, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", err
+	}
+	// the fields come after the command name, which is in parentheses,
+	// from the state, field 3
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 || i+2 >= len(stat) {
+		return "", fmt.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(string(stat[i+2:]))
+	if len(fields) < 20 {
+		return "", fmt.Errorf("malformed stat of process %d", pid)
+	}
+	return fields[19], nil
+}
+
+// recordServerProcess writes the pidfile of a snake server just started.
+func (c *SnakeServerController) recordServerProcess(process *os.Process) {
+	if c.pidsDir == "" {
+		return
+	}
+	ownerStart, err := processStart(os.Getpid())
+	if err != nil {
+		return
+	}
+	start, err := processStart(process.Pid)
+	if err != nil {
+		return
+	}
+	record := fmt.Sprintf("%d %s %s\n", os.Getpid(), ownerStart, start)
+	if err := os.WriteFile(filepath.Join(c.pidsDir, strconv.Itoa(process.Pid)), []byte(record), 0o644); err != nil {
+		slog.Warn("Error recording snake server", "pid", process.Pid, "err", err)
+	}
+}
+
+// forgetServerProcess removes the pidfile of a snake server that stopped.
+func (c *SnakeServerController) forgetServerProcess(process *os.Process) {
+	if c.pidsDir == "" || process == nil {
+		return
+	}
+	os.Remove(filepath.Join(c.pidsDir, strconv.Itoa(process.Pid)))
+}
+
+// killOrphanServers kills the snake servers recorded by a previous run that
+// crashed, so that their ports can be reserved again. The servers of a run
+// still going, from the same directory, are left alone.
+func (c *SnakeServerController) killOrphanServers() {
+	entries, err := os.ReadDir(c.pidsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.pidsDir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var record serverRecord
+		if _, err := fmt.Sscan(string(data), &record.ownerPID, &record.ownerStart, &record.start); err != nil {
+			os.Remove(path)
+			continue
+		}
+		if start, err := processStart(record.ownerPID); err == nil && start == record.ownerStart {
+			// another run is going
+			continue
+		}
+		os.Remove(path)
+		if start, err := processStart(pid); err != nil || start != record.start {
+			// the server is gone, the PID may be another process's now
+			continue
+		}
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		slog.Warn("Killing snake server left by a previous run", "pid", pid)
+		if err := process.Kill(); err == nil {
+			portsReclaimed.Inc("orphan")
+		}
+	}
+}
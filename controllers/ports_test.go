@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPortPool(t *testing.T) {
+	pool := newPortPool(9000, 3)
+	// another process holds 9000
+	pool.bindable = func(port int) bool { return port != 9000 }
+
+	first, err := pool.reserve(1)
+	if err != nil || first != 9001 {
+		t.Fatalf("got port %d and error %v, want 9001 since 9000 is bound", first, err)
+	}
+	second, _ := pool.reserve(2)
+	if _, err := pool.reserve(3); !errors.Is(err, ErrNoPorts) {
+		t.Fatalf("got error %v with every port taken, want ErrNoPorts", err)
+	}
+	if got, want := pool.usage(), (PortUsage{Size: 3, Reserved: 2, Busy: 1}); got != want {
+		t.Errorf("got usage %+v, want %+v", got, want)
+	}
+
+	pool.release(first)
+	if port, err := pool.reserve(3); err != nil || port != first {
+		t.Errorf("got port %d and error %v, want the released port %d", port, err, first)
+	}
+
+	// snake 2 never got its server, snake 3 runs on its port
+	for port, reservation := range pool.reserved {
+		reservation.since = time.Now().Add(-portLeakGrace)
+		pool.reserved[port] = reservation
+	}
+	leaked := pool.leaked(func(port int, snakeID int64) bool { return snakeID == 3 && port == first })
+	if len(leaked) != 1 || leaked[0] != second {
+		t.Errorf("got leaked ports %v, want [%d]", leaked, second)
+	}
+	if got := pool.usage().Reserved; got != 1 {
+		t.Errorf("got %d reserved ports once the leak is reclaimed, want 1", got)
+	}
+}
+
+func TestCanBind(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if canBind(port) {
+		t.Errorf("port %d is bound, canBind says it is free", port)
+	}
+	listener.Close()
+	if !canBind(port) {
+		t.Errorf("port %d was released, canBind says it is taken", port)
+	}
+}
+
+func TestKillOrphanServers(t *testing.T) {
+	if _, err := processStart(os.Getpid()); err != nil {
+		t.Skipf("no /proc: %v", err)
+	}
+	c := &SnakeServerController{pidsDir: t.TempDir()}
+	start := func() *exec.Cmd {
+		t.Helper()
+		cmd := exec.Command("sleep", "30")
+		if err := cmd.Start(); err != nil {
+			t.Skipf("cannot start sleep: %v", err)
+		}
+		t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+		return cmd
+	}
+	record := func(pid int, owner int, ownerStart, start string) {
+		t.Helper()
+		data := fmt.Sprintf("%d %s %s\n", owner, ownerStart, start)
+		if err := os.WriteFile(filepath.Join(c.pidsDir, strconv.Itoa(pid)), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a run that is gone, its PID may be anybody's now
+	gone := exec.Command("true")
+	if err := gone.Run(); err != nil {
+		t.Skipf("cannot run true: %v", err)
+	}
+
+	// the server of this run, as if another run were starting
+	ours := start()
+	c.recordServerProcess(ours.Process)
+	orphan := start()
+	orphanStart, _ := processStart(orphan.Process.Pid)
+	record(orphan.Process.Pid, gone.Process.Pid, "1", orphanStart)
+	// a PID reused by a process that is no snake server
+	reused := start()
+	record(reused.Process.Pid, gone.Process.Pid, "1", "1")
+
+	c.killOrphanServers()
+
+	if err := orphan.Wait(); err == nil {
+		t.Error("the server of the run that is gone was not killed")
+	}
+	for name, cmd := range map[string]*exec.Cmd{"the server of a running run": ours, "the process with a reused PID": reused} {
+		if err := cmd.Process.Signal(syscall.Signal(0)); err != nil {
+			t.Errorf("%s was killed", name)
+		}
+	}
+	entries, _ := os.ReadDir(c.pidsDir)
+	if len(entries) != 1 || entries[0].Name() != strconv.Itoa(ours.Process.Pid) {
+		t.Errorf("got pidfiles %v, want only the one of the running server", entries)
+	}
+}
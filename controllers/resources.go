@@ -0,0 +1,373 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+var (
+	resourceWarnings = metrics.NewCounter(
+		"localsnake_snake_resource_warnings_total",
+		"Snake processes that went over a soft resource limit, by resource: cpu, rss, threads or files.",
+		"resource",
+	)
+	resourceKills = metrics.NewCounter(
+		"localsnake_snake_resource_kills_total",
+		"Snake processes killed for staying over a hard resource limit, by resource: cpu, rss, threads or files.",
+		"resource",
+	)
+)
+
+const (
+	// resourceSampleInterval is how often the snake processes are sampled.
+	resourceSampleInterval = 5 * time.Second
+	// maxResourceSamples bounds the history of each snake, five minutes.
+	maxResourceSamples = 60
+	// softLimitRatio places the soft limits, which only warn, below the
+	// hard ones.
+	softLimitRatio = 0.75
+	// hardLimitStrikes is how many samples in a row a process may stay over
+	// a hard limit before it is killed, so that a spike is not fatal.
+	hardLimitStrikes = 3
+	// clockTicks is the unit of the CPU times of /proc, USER_HZ.
+	clockTicks = 100
+)
+
+// ResourceLimits are the hard limits of each snake process, zero for none. A
+// process over one for hardLimitStrikes samples is killed and, if it is a
+// snake server, restarted. The soft limits are softLimitRatio of them.
+type ResourceLimits struct {
+	// CPUPercent is the CPU usage between two samples, 100 for a core.
+	CPUPercent float64
+	RSSMB      int
+	Threads    int
+	OpenFiles  int
+}
+
+// DefaultResourceLimits apply unless SetResourceLimits says otherwise. A
+// snake searching hard keeps a core busy for whole games, only a process
+// spinning on several cores is stopped.
+var DefaultResourceLimits = ResourceLimits{CPUPercent: 400, RSSMB: 512, Threads: 256, OpenFiles: 256}
+
+var resourceLimits = DefaultResourceLimits
+
+// SetResourceLimits changes the limits of the snake processes, it must be
+// called before InitSnakeServerManager.
+func SetResourceLimits(limits ResourceLimits) {
+	resourceLimits = limits
+}
+
+// over returns the resources of a process over the limits scaled by ratio,
+// a process may use up to its limits.
+func (l ResourceLimits) over(ratio float64, cpuPercent float64, usage processUsage) []string {
+	var resources []string
+	if l.CPUPercent > 0 && cpuPercent > l.CPUPercent*ratio {
+		resources = append(resources, "cpu")
+	}
+	if l.RSSMB > 0 && float64(usage.rss) > float64(l.RSSMB<<20)*ratio {
+		resources = append(resources, "rss")
+	}
+	if l.Threads > 0 && float64(usage.threads) > float64(l.Threads)*ratio {
+		resources = append(resources, "threads")
+	}
+	if l.OpenFiles > 0 && float64(usage.openFiles) > float64(l.OpenFiles)*ratio {
+		resources = append(resources, "files")
+	}
+	return resources
+}
+
+// ResourceSample is the usage of the processes of a snake at a point in time,
+// summed when the snake plays several games over stdio.
+type ResourceSample struct {
+	At         time.Time
+	CPUPercent float64
+	RSS        int64 // bytes
+	Threads    int
+	OpenFiles  int
+	Processes  int
+}
+
+// ResourceEvent is a process of a snake going over its limits.
+type ResourceEvent struct {
+	At        time.Time
+	Resources []string
+	// Killed is set when a hard limit was hit, a warning otherwise.
+	Killed bool
+}
+
+// ResourceHistory holds the last samples of a snake, oldest first, and how
+// often its processes went over their limits.
+type ResourceHistory struct {
+	Samples   []ResourceSample
+	Warnings  int
+	Kills     int
+	LastEvent *ResourceEvent
+}
+
+// monitoredProcess is a process of a snake the monitor samples.
+type monitoredProcess struct {
+	snakeID int64
+	process *os.Process
+	// server is set for the snake servers, which are restarted when
+	// killed; a stdio program is not, its game goes on without it
+	server bool
+}
+
+type resourceMonitor struct {
+	lock      sync.Mutex
+	limits    ResourceLimits
+	histories map[int64]*ResourceHistory
+	// by pid, forgotten once the process is gone
+	lastCPU map[int]cpuReading
+	strikes map[int]int
+	warned  map[int]bool
+}
+
+type cpuReading struct {
+	cpu time.Duration
+	at  time.Time
+}
+
+func newResourceMonitor(limits ResourceLimits) *resourceMonitor {
+	return &resourceMonitor{
+		limits:    limits,
+		histories: make(map[int64]*ResourceHistory),
+		lastCPU:   make(map[int]cpuReading),
+		strikes:   make(map[int]int),
+		warned:    make(map[int]bool),
+	}
+}
+
+// processUsage is what /proc tells of a process.
+type processUsage struct {
+	cpu       time.Duration // user and system
+	rss       int64
+	threads   int
+	openFiles int
+}
+
+// readProcessUsage reads the usage of a running process from /proc.
+func readProcessUsage(pid int) (processUsage, error) {
+	var usage processUsage
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return usage, err
+	}
+	// the fields come after the command name, which is in parentheses,
+	// from the state, field 3 of proc(5)
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 || i+2 >= len(stat) {
+		return usage, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(string(stat[i+2:]))
+	if len(fields) < 22 {
+		return usage, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	if fields[0] == "Z" || fields[0] == "X" {
+		return usage, fmt.Errorf("process %d exited", pid)
+	}
+	field := func(n int) int64 {
+		v, _ := strconv.ParseInt(fields[n-3], 10, 64)
+		return v
+	}
+	usage.cpu = time.Duration(field(14)+field(15)) * time.Second / clockTicks
+	usage.threads = int(field(20))
+	usage.rss = field(24) * int64(os.Getpagesize())
+
+	fds, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return usage, err
+	}
+	usage.openFiles = len(fds)
+	return usage, nil
+}
+
+// ResourceHistory returns the resource usage of the processes of a snake,
+// false if none was sampled yet.
+func (c *SnakeServerController) ResourceHistory(snakeID int64) (ResourceHistory, bool) {
+	m := c.resources
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	history, ok := m.histories[snakeID]
+	if !ok {
+		return ResourceHistory{}, false
+	}
+	copied := *history
+	copied.Samples = slices.Clone(history.Samples)
+	return copied, true
+}
+
+// monitorResources samples the snake processes until the manager is
+// destroyed.
+func (c *SnakeServerController) monitorResources() {
+	ticker := time.NewTicker(resourceSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			c.sampleResources(now)
+		}
+	}
+}
+
+// monitoredProcesses lists the snake servers and the running stdio programs.
+func (c *SnakeServerController) monitoredProcesses() []monitoredProcess {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var processes []monitoredProcess
+	for snakeID, server := range c.servers {
+		if server.command != nil && server.command.Process != nil && server.command.ProcessState == nil {
+			processes = append(processes, monitoredProcess{snakeID: snakeID, process: server.command.Process, server: true})
+		}
+	}
+	for _, process := range c.stdioProcesses {
+		processes = append(processes, process)
+	}
+	return processes
+}
+
+// sampleResources records the usage of every snake process, warns about the
+// ones over a soft limit and kills the ones over a hard limit.
+func (c *SnakeServerController) sampleResources(now time.Time) {
+	processes := c.monitoredProcesses()
+	m := c.resources
+
+	type kill struct {
+		monitoredProcess
+		resources []string
+	}
+	var kills []kill
+
+	m.lock.Lock()
+	samples := map[int64]*ResourceSample{}
+	seen := map[int]bool{}
+	for _, p := range processes {
+		pid := p.process.Pid
+		usage, err := readProcessUsage(pid)
+		if err != nil {
+			// the process exited, the reclaimer or its game cleans up
+			continue
+		}
+		seen[pid] = true
+
+		var cpuPercent float64
+		if last, ok := m.lastCPU[pid]; ok && now.After(last.at) {
+			cpuPercent = float64(usage.cpu-last.cpu) / float64(now.Sub(last.at)) * 100
+		}
+		m.lastCPU[pid] = cpuReading{cpu: usage.cpu, at: now}
+
+		sample, ok := samples[p.snakeID]
+		if !ok {
+			sample = &ResourceSample{At: now}
+			samples[p.snakeID] = sample
+		}
+		sample.CPUPercent += cpuPercent
+		sample.RSS += usage.rss
+		sample.Threads += usage.threads
+		sample.OpenFiles += usage.openFiles
+		sample.Processes++
+
+		history := m.history(p.snakeID)
+		if hard := m.limits.over(1, cpuPercent, usage); len(hard) > 0 {
+			m.strikes[pid]++
+			if m.strikes[pid] >= hardLimitStrikes {
+				delete(m.strikes, pid)
+				kills = append(kills, kill{p, hard})
+				history.Kills++
+				history.LastEvent = &ResourceEvent{At: now, Resources: hard, Killed: true}
+				continue
+			}
+		} else {
+			delete(m.strikes, pid)
+		}
+
+		soft := m.limits.over(softLimitRatio, cpuPercent, usage)
+		// warn once each time a process goes over
+		if len(soft) > 0 && !m.warned[pid] {
+			history.Warnings++
+			history.LastEvent = &ResourceEvent{At: now, Resources: soft}
+			for _, resource := range soft {
+				resourceWarnings.Inc(resource)
+			}
+			slog.Warn("Snake process over its soft resource limits", "snake_id", p.snakeID, "pid", pid, "resources", soft,
+				"cpu_percent", int(cpuPercent), "rss_mb", usage.rss>>20, "threads", usage.threads, "open_files", usage.openFiles)
+		}
+		m.warned[pid] = len(soft) > 0
+	}
+	for pid := range m.lastCPU {
+		if !seen[pid] {
+			delete(m.lastCPU, pid)
+			delete(m.strikes, pid)
+			delete(m.warned, pid)
+		}
+	}
+	for snakeID, sample := range samples {
+		history := m.history(snakeID)
+		history.Samples = append(history.Samples, *sample)
+		if len(history.Samples) > maxResourceSamples {
+			history.Samples = slices.Delete(history.Samples, 0, len(history.Samples)-maxResourceSamples)
+		}
+	}
+	m.lock.Unlock()
+
+	for _, k := range kills {
+		slog.Error("Killing snake process over its hard resource limits", "snake_id", k.snakeID, "pid", k.process.Pid, "resources", k.resources)
+		for _, resource := range k.resources {
+			resourceKills.Inc(resource)
+		}
+		if k.server {
+			c.restartServer(k.snakeID)
+		} else {
+			k.process.Kill()
+		}
+	}
+}
+
+// history returns the history of a snake. The lock must be held.
+func (m *resourceMonitor) history(snakeID int64) *ResourceHistory {
+	history, ok := m.histories[snakeID]
+	if !ok {
+		history = &ResourceHistory{}
+		m.histories[snakeID] = history
+	}
+	return history
+}
+
+// restartServer kills the server of a snake and starts it again.
+func (c *SnakeServerController) restartServer(snakeID int64) {
+	server := c.getServer(snakeID)
+	if server == nil {
+		return
+	}
+	snake := server.snake
+	c.stopAndRemoveServer(snakeID)
+	if err := c.ManageSnake(context.Background(), &snake); err != nil {
+		slog.Error("Error restarting snake server", "snake_id", snakeID, "err", err)
+	}
+}
+
+// watchStdioProcess has the program of a snake sampled while it plays.
+func (c *SnakeServerController) watchStdioProcess(provider *game.StdioProvider, snakeID int64, process *os.Process) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.stdioProcesses[provider] = monitoredProcess{snakeID: snakeID, process: process}
+}
+
+func (c *SnakeServerController) forgetStdioProcess(provider *game.StdioProvider) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.stdioProcesses, provider)
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/secomp2025/localsnake/game"
+)
+
+func TestReadProcessUsage(t *testing.T) {
+	usage, err := readProcessUsage(os.Getpid())
+	if err != nil {
+		t.Skipf("no /proc: %v", err)
+	}
+	if usage.rss <= 0 || usage.threads < 1 || usage.openFiles < 3 {
+		t.Errorf("got %+v, want the memory, threads and files of the test", usage)
+	}
+}
+
+func TestSampleResources(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skipf("no /proc: %v", err)
+	}
+	// the shell waits on its input, which is never written, once it tells
+	// it runs: the samples must not catch the fork before it exec'd
+	cmd := exec.Command("sh", "-c", "echo ready; read line")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sh: %v", err)
+	}
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "ready\n" {
+		cmd.Process.Kill()
+		t.Fatalf("got %q and error %v from the program, want it ready", line, err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer cmd.Process.Kill()
+
+	// the program has its standard files open, more than the hard limit
+	c := &SnakeServerController{
+		servers:        map[int64]SnakeServer{},
+		resources:      newResourceMonitor(ResourceLimits{OpenFiles: 2}),
+		stdioProcesses: map[*game.StdioProvider]monitoredProcess{},
+	}
+	c.watchStdioProcess(game.NewStdioProvider(nil), 7, cmd.Process)
+
+	now := time.Now()
+	for i := range hardLimitStrikes {
+		c.sampleResources(now.Add(time.Duration(i) * resourceSampleInterval))
+	}
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("the program over its hard limit was not killed")
+	}
+
+	history, ok := c.ResourceHistory(7)
+	if !ok {
+		t.Fatal("no history for the snake")
+	}
+	if len(history.Samples) != hardLimitStrikes || history.Samples[0].Processes != 1 || history.Samples[0].RSS <= 0 {
+		t.Errorf("got samples %+v, want one per sampling of the program", history.Samples)
+	}
+	if history.Warnings != 1 || history.Kills != 1 || !history.LastEvent.Killed {
+		t.Errorf("got %d warnings, %d kills and last event %+v, want the program warned then killed", history.Warnings, history.Kills, history.LastEvent)
+	}
+}
+
+func TestResourceLimitsOver(t *testing.T) {
+	limits := ResourceLimits{CPUPercent: 100, RSSMB: 1, Threads: 4, OpenFiles: 8}
+	atLimits := processUsage{rss: 1 << 20, threads: 4, openFiles: 8}
+	if got := limits.over(1, 100, atLimits); len(got) != 0 {
+		t.Errorf("a process at its limits is over %v, want none", got)
+	}
+	overLimits := processUsage{rss: 1<<20 + 1, threads: 5, openFiles: 9}
+	if got := limits.over(1, 101, overLimits); len(got) != 4 {
+		t.Errorf("a process over every limit is over %v, want all", got)
+	}
+
+	// a snake busy on a core all game long is not even warned
+	if got := DefaultResourceLimits.over(softLimitRatio, 100, processUsage{}); len(got) != 0 {
+		t.Errorf("a core busy is over %v of the default limits, want none", got)
+	}
+}
@@ -11,7 +11,7 @@ type SnakeController struct {
 }
 
 func NewSnakeController(db database.DBTX) SnakeController {
-	return SnakeController{queries: database.New(db)}
+	return SnakeController{queries: database.New(database.Timed(db))}
 }
 
 func (c *SnakeController) ListSnakes(ctx context.Context) ([]database.Snake, error) {
@@ -1,23 +1,31 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/secomp2025/localsnake/database"
 	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+var snakeRestarts = metrics.NewCounter(
+	"localsnake_snake_restarts_total",
+	"Snake servers restarted by an admin.",
 )
 
 type SnakeServer struct {
@@ -26,19 +34,41 @@ type SnakeServer struct {
 	port    int
 	command *exec.Cmd
 	logFile *os.File
+	// snake is the snake served, to restart it
+	snake database.Snake
+	// started is closed once the server started or failed to. Until then
+	// the server has no command, it only holds the snake and the port.
+	started chan struct{}
 }
 
 type SnakeServerController struct {
-	lock          sync.RWMutex
-	httpClient    *http.Client
-	servers       map[int64]SnakeServer
-	reservedPorts map[int]bool
-	basePort      int
+	lock       sync.RWMutex
+	httpClient *http.Client
+	servers    map[int64]SnakeServer
+	ports      *portPool
+	// stop stops the goroutines of reclaimPorts and monitorResources
+	stop      chan struct{}
+	resources *resourceMonitor
+	// stdioProcesses are the running programs of the snakes played over
+	// stdio, by provider
+	stdioProcesses map[*game.StdioProvider]monitoredProcess
+
+	runtime SnakeRuntime
+	// stdioCommands are the commands of the snakes ready to play over stdio
+	stdioCommands map[int64][]string
+	// stdioPaths are the paths of the snakes of the games played over stdio,
+	// by provider, until the games end
+	stdioPaths map[*game.StdioProvider]string
 
 	pyServerPath string
 	jsServerPath string
 	cServerPath  string
 	cHeaderPath  string
+	pyStdioPath  string
+	jsStdioPath  string
+	cStdioPath   string
+	// pidsDir holds the pidfiles of the snake servers, see serverRecord
+	pidsDir string
 }
 
 type globalSnakeServerController struct {
@@ -53,7 +83,10 @@ const (
 	MAX_PORTS = 300
 )
 
-func InitSnakeServerManager(staticFS fs.FS) {
+// InitSnakeServerManager installs the snake server templates found in
+// staticFS, which is rooted at the static directory, and creates the manager
+// of the snakes played with the given runtime.
+func InitSnakeServerManager(staticFS fs.FS, runtime SnakeRuntime) {
 	serverManager.lock.Lock()
 	defer serverManager.lock.Unlock()
 	if serverManager.controller != nil {
@@ -63,90 +96,130 @@ func InitSnakeServerManager(staticFS fs.FS) {
 	serversDir := filepath.Join("servers")
 	if _, err := os.Stat(serversDir); err != nil {
 		if err := os.Mkdir(serversDir, 0755); err != nil {
-			log.Println("Error creating servers directory", err)
+			slog.Error("Error creating servers directory", "err", err)
 			return
 		}
 	}
 
-	pyServerPath := filepath.Join("static", "code-templates", "py", "server.py")
-	pyServerFile, err := staticFS.Open(pyServerPath)
+	pyServerFile, err := staticFS.Open("code-templates/py/server.py")
 	if err != nil {
-		log.Println("Error opening server file for snake", err)
+		slog.Error("Error opening server file for snake", "err", err)
 		return
 	}
 	defer pyServerFile.Close()
 
-	jsServerPath := filepath.Join("static", "code-templates", "js", "server.js")
-	jsServerFile, err := staticFS.Open(jsServerPath)
+	jsServerFile, err := staticFS.Open("code-templates/js/server.js")
 	if err != nil {
-		log.Println("Error opening server file for snake", err)
+		slog.Error("Error opening server file for snake", "err", err)
 		return
 	}
 	defer jsServerFile.Close()
 
-	cServerPath := filepath.Join("static", "code-templates", "c", "server.c")
-	cServerFile, err := staticFS.Open(cServerPath)
+	cServerFile, err := staticFS.Open("code-templates/c/server.c")
 	if err != nil {
-		log.Println("Error opening server file for snake", err)
+		slog.Error("Error opening server file for snake", "err", err)
 		return
 	}
 	defer cServerFile.Close()
 
-	cHeaderPath := filepath.Join("static", "code-templates", "c", "battlesnake.h")
-	cHeaderFile, err := staticFS.Open(cHeaderPath)
+	cHeaderFile, err := staticFS.Open("code-templates/c/battlesnake.h")
 	if err != nil {
-		log.Println("Error opening header file for snake", err)
+		slog.Error("Error opening header file for snake", "err", err)
 		return
 	}
 	defer cHeaderFile.Close()
 
+	pidsDir := filepath.Join(serversDir, "pids")
+	if err := os.MkdirAll(pidsDir, 0755); err != nil {
+		slog.Error("Error creating pids directory", "err", err)
+		return
+	}
+
 	pyServerDir := filepath.Join(serversDir, "py")
 	jsServerDir := filepath.Join(serversDir, "js")
 	cServerDir := filepath.Join(serversDir, "c")
 
 	if _, err := os.Stat(pyServerDir); err != nil {
 		if err := os.MkdirAll(pyServerDir, 0755); err != nil {
-			log.Println("Error creating py server directory", err)
+			slog.Error("Error creating py server directory", "err", err)
 			return
 		}
 	}
 	if _, err := os.Stat(jsServerDir); err != nil {
 		if err := os.MkdirAll(jsServerDir, 0755); err != nil {
-			log.Println("Error creating js server directory", err)
+			slog.Error("Error creating js server directory", "err", err)
 			return
 		}
 	}
 
 	if _, err := os.Stat(cServerDir); err != nil {
 		if err := os.MkdirAll(cServerDir, 0755); err != nil {
-			log.Println("Error creating c server directory", err)
+			slog.Error("Error creating c server directory", "err", err)
 			return
 		}
 	}
 
-	copyFile(pyServerFile, filepath.Join(pyServerDir, "server.py"))
-	copyFile(jsServerFile, filepath.Join(jsServerDir, "server.js"))
+	// snakes run from the copies, the static directory may only exist
+	// embedded in the binary
+	pyServerPath := filepath.Join(pyServerDir, "server.py")
+	jsServerPath := filepath.Join(jsServerDir, "server.js")
+	cHeaderPath := filepath.Join(cServerDir, "battlesnake.h")
+	copyFile(pyServerFile, pyServerPath)
+	copyFile(jsServerFile, jsServerPath)
 	copyFile(cServerFile, filepath.Join(cServerDir, "server.c"))
-	copyFile(cHeaderFile, filepath.Join(cServerDir, "battlesnake.h"))
+	copyFile(cHeaderFile, cHeaderPath)
+
+	// the stdio harnesses, C snakes are compiled with theirs
+	pyStdioPath := filepath.Join(pyServerDir, "stdio.py")
+	jsStdioPath := filepath.Join(jsServerDir, "stdio.js")
+	cStdioPath := filepath.Join(cServerDir, "stdio.c")
+	for dst, name := range map[string]string{
+		pyStdioPath: "code-templates/py/stdio.py",
+		jsStdioPath: "code-templates/js/stdio.js",
+		cStdioPath:  "code-templates/c/stdio.c",
+	} {
+		if err := installTemplate(staticFS, name, dst); err != nil {
+			slog.Error("Error installing stdio harness for snake", "file", name, "err", err)
+			return
+		}
+	}
 
-	// compile
+	// compile, the stdio runtime needs neither jansson nor microhttpd
 	cCompServerPath := filepath.Join(cServerDir, "server")
-	cmd := exec.Command("gcc", filepath.Join(cServerDir, "server.c"), "-o", cCompServerPath, "-ljansson", "-lmicrohttpd")
-	if err := cmd.Run(); err != nil {
-		log.Println("Error compiling server file for snake", err)
-		return
+	if runtime == RuntimeHTTP {
+		cmd := exec.Command("gcc", filepath.Join(cServerDir, "server.c"), "-o", cCompServerPath, "-ljansson", "-lmicrohttpd")
+		if err := cmd.Run(); err != nil {
+			slog.Error("Error compiling server file for snake", "err", err)
+			return
+		}
 	}
 
-	serverManager.controller = &SnakeServerController{
-		httpClient:    &http.Client{},
-		basePort:      BASE_PORT,
-		servers:       make(map[int64]SnakeServer),
-		reservedPorts: make(map[int]bool),
-		pyServerPath:  pyServerPath,
-		jsServerPath:  jsServerPath,
-		cServerPath:   cCompServerPath,
-		cHeaderPath:   cHeaderPath,
+	slog.Info("Snake runtime", "runtime", runtime)
+	controller := &SnakeServerController{
+		httpClient:     &http.Client{},
+		servers:        make(map[int64]SnakeServer),
+		ports:          newPortPool(BASE_PORT, MAX_PORTS),
+		stop:           make(chan struct{}),
+		resources:      newResourceMonitor(resourceLimits),
+		stdioProcesses: make(map[*game.StdioProvider]monitoredProcess),
+		runtime:        runtime,
+		stdioCommands:  make(map[int64][]string),
+		stdioPaths:     make(map[*game.StdioProvider]string),
+		pyServerPath:   pyServerPath,
+		jsServerPath:   jsServerPath,
+		cServerPath:    cCompServerPath,
+		cHeaderPath:    cHeaderPath,
+		pyStdioPath:    pyStdioPath,
+		jsStdioPath:    jsStdioPath,
+		cStdioPath:     cStdioPath,
+		pidsDir:        pidsDir,
+	}
+	if runtime == RuntimeHTTP {
+		controller.killOrphanServers()
+		go controller.reclaimPorts()
 	}
+	go controller.monitorResources()
+	serverManager.controller = controller
 }
 
 func GetServerManager() *SnakeServerController {
@@ -160,10 +233,12 @@ func DestroySnakeServerManager() {
 		return
 	}
 
+	close(serverManager.controller.stop)
 	for _, server := range serverManager.controller.servers {
 		if server.command != nil {
 			server.command.Process.Kill()
 			server.command.Wait()
+			serverManager.controller.forgetServerProcess(server.command.Process)
 		}
 	}
 
@@ -173,22 +248,36 @@ func DestroySnakeServerManager() {
 func (c *SnakeServerController) GetSnakeStatus(ctx context.Context, snake *database.Snake) (game.Status, error) {
 
 	snakeID := snake.ID
+	if c.runtime == RuntimeStdio {
+		// the snake only runs during its games, it is online once it
+		// can be started
+		c.lock.RLock()
+		_, ok := c.stdioCommands[snakeID]
+		c.lock.RUnlock()
+		if !ok {
+			if err := c.prepareStdioSnake(ctx, snake); err != nil {
+				slog.ErrorContext(ctx, "Error preparing snake", "snake_id", snake.ID, "err", err)
+				return game.StatusOffline, err
+			}
+		}
+		return game.StatusOnline, nil
+	}
+
 	server := c.getServer(snakeID)
 	if server == nil {
 		return game.StatusOffline, nil
 	}
 
-	// log.Println("Checking status for snake", snake)
 	resp, err := c.httpClient.Get(server.Addr)
 	if err != nil {
-		log.Println("Error checking status for snake", snake, err)
+		slog.ErrorContext(ctx, "Error checking status for snake", "snake_id", snake.ID, "err", err)
 		c.stopAndRemoveServer(snakeID)
 		return game.StatusOffline, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Println("Error checking status for snake", snake, resp.StatusCode)
+		slog.ErrorContext(ctx, "Error checking status for snake", "snake_id", snake.ID, "status", resp.StatusCode)
 		c.stopAndRemoveServer(snakeID)
 		return game.StatusOffline, nil
 	}
@@ -198,78 +287,243 @@ func (c *SnakeServerController) GetSnakeStatus(ctx context.Context, snake *datab
 
 func (c *SnakeServerController) ManageSnake(ctx context.Context, snake *database.Snake) error {
 	snakeID := snake.ID
-	if c.serverExists(snakeID) {
-		log.Println("Snake already managed", snake)
-		return nil
+	if c.runtime == RuntimeStdio {
+		return c.prepareStdioSnake(ctx, snake)
 	}
 
-	log.Println("Finding empty port for snake", snake)
-	port, err := c.getEmptyPort()
+	// the snake is claimed with its port under a single lock, so that
+	// concurrent calls start a single server
+	c.lock.Lock()
+	if server, ok := c.servers[snakeID]; ok {
+		c.lock.Unlock()
+		if server.command == nil {
+			// another call is starting it
+			select {
+			case <-server.started:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if c.getServer(snakeID) == nil {
+				return fmt.Errorf("snake server of snake %d failed to start", snakeID)
+			}
+		}
+		slog.WarnContext(ctx, "Snake already managed", "snake_id", snake.ID)
+		return nil
+	}
+	slog.DebugContext(ctx, "Finding empty port for snake", "snake_id", snake.ID)
+	port, err := c.ports.reserve(snakeID)
 	if err != nil {
-		log.Println("Error getting empty port for snake", snake, err)
+		c.lock.Unlock()
+		slog.ErrorContext(ctx, "Error getting empty port for snake", "snake_id", snake.ID, "err", err)
 		return err
 	}
+	started := make(chan struct{})
+	defer close(started)
+	c.servers[snakeID] = SnakeServer{
+		Addr:    "http://localhost:" + strconv.Itoa(port),
+		path:    snake.Path,
+		port:    port,
+		snake:   *snake,
+		started: started,
+	}
+	c.lock.Unlock()
 
-	log.Println("Starting snake server for snake", snake, "on port", port)
+	server, err := c.startServer(ctx, snake, port)
 
-	logFile, err := os.OpenFile("snake-"+strconv.FormatInt(snakeID, 10)+".log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	c.lock.Lock()
+	current, ok := c.servers[snakeID]
+	claimed := ok && current.started == started
+	switch {
+	case claimed && err != nil:
+		delete(c.servers, snakeID)
+		c.ports.release(port)
+	case claimed:
+		server.started = started
+		c.servers[snakeID] = server
+	}
+	c.lock.Unlock()
+	if !claimed {
+		// the snake was stopped while its server started, which gave the
+		// port back to the pool
+		if err == nil {
+			server.command.Process.Kill()
+			server.command.Wait()
+			c.forgetServerProcess(server.command.Process)
+			server.logFile.Close()
+			err = fmt.Errorf("snake server of snake %d was stopped while starting", snakeID)
+		}
+		return err
+	}
 	if err != nil {
-		log.Println("Error creating log file for snake", snake, err)
 		return err
 	}
+	slog.InfoContext(ctx, "Snake server started", "snake_id", snake.ID, "port", port)
+	return nil
+}
+
+// startServer starts the server of a snake on port, the caller owns the
+// port whatever happens.
+func (c *SnakeServerController) startServer(ctx context.Context, snake *database.Snake, port int) (server SnakeServer, err error) {
+	snakeID := snake.ID
+	logFile, err := os.OpenFile("snake-"+strconv.FormatInt(snakeID, 10)+".log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating log file for snake", "snake_id", snake.ID, "err", err)
+		return server, err
+	}
+	defer func() {
+		if err != nil {
+			logFile.Close()
+		}
+	}()
 
 	var serverCommand *exec.Cmd
 
 	if strings.HasSuffix(snake.Path, ".py") {
-		fmt.Println("Python snake")
 		serverCommand = exec.Command("python3", c.pyServerPath, snake.Path, strconv.Itoa(port))
-		serverCommand.Env = append(os.Environ(), "PYTHONUNBUFFERED=1")
+		// let multi-file projects import their sibling modules
+		serverCommand.Env = append(os.Environ(), "PYTHONUNBUFFERED=1", "PYTHONPATH="+filepath.Dir(snake.Path))
 	} else if strings.HasSuffix(snake.Path, ".js") {
-		fmt.Println("JS snake", c.jsServerPath, snake.Path, strconv.Itoa(port))
 		serverCommand = exec.Command("node", c.jsServerPath, snake.Path, strconv.Itoa(port))
-	} else if strings.HasSuffix(snake.Path, ".c") {
+	} else if strings.HasSuffix(snake.Path, ".c") || snake.Lang == ".c" {
 		// compile and run shared object
-		cHeaderDir := filepath.Dir(c.cHeaderPath)
-
-		sharedObjectPath := snake.Path[:len(snake.Path)-2] + ".so"
-		log.Println("gcc", "-shared", "-I"+cHeaderDir, "-o", sharedObjectPath, snake.Path)
-		compileCmd := exec.Command("gcc", "-shared", "-I"+cHeaderDir, "-o", sharedObjectPath, snake.Path)
-		compileCmd.Stdout = os.Stdout
-		compileCmd.Stderr = os.Stderr
-		if err := compileCmd.Run(); err != nil {
-			return fmt.Errorf("error compiling snake %d: %w", snake.ID, err)
+		sharedObjectPath, err := c.compileCSnake(snake)
+		if err != nil {
+			return server, err
 		}
 
 		serverCommand = exec.Command(c.cServerPath, strconv.Itoa(port))
 		serverCommand.Env = append(os.Environ(), "LD_PRELOAD="+sharedObjectPath)
-
-		log.Println("SERVER_COMMAND: LD_PRELOAD="+sharedObjectPath, c.cServerPath, strconv.Itoa(port))
 	} else {
-		return fmt.Errorf("invalid snake file extension: %s", snake.Path)
+		return server, fmt.Errorf("invalid snake file extension: %s", snake.Path)
 	}
 
 	serverCommand.Stdout = logFile
 	serverCommand.Stderr = logFile
 
-	fmt.Println("Starting snake server for snake", snake, "on port", port)
+	slog.InfoContext(ctx, "Starting snake server", "snake_id", snake.ID, "port", port, "command", serverCommand.String())
 	if err := serverCommand.Start(); err != nil {
-		log.Println("Error starting snake server for snake", snake, err)
-		return err
+		slog.ErrorContext(ctx, "Error starting snake server for snake", "snake_id", snake.ID, "err", err)
+		return server, err
 	}
+	c.recordServerProcess(serverCommand.Process)
 
 	// give some time for the server to start
 	time.Sleep(300 * time.Millisecond)
 
-	log.Println("Snake server for snake", snake, "started on port", port)
-
-	c.addServer(snakeID, SnakeServer{
+	server = SnakeServer{
 		Addr:    "http://localhost:" + strconv.Itoa(port),
 		path:    snake.Path,
 		port:    port,
 		command: serverCommand,
 		logFile: logFile,
-	})
-	return nil
+		snake:   *snake,
+	}
+	if server.processStatus() == "exited" {
+		err := serverCommand.Wait()
+		c.forgetServerProcess(serverCommand.Process)
+		slog.ErrorContext(ctx, "Snake server exited on start", "snake_id", snake.ID, "port", port, "err", err)
+		return SnakeServer{}, fmt.Errorf("snake server of snake %d exited on start: %v", snakeID, err)
+	}
+	return server, nil
+}
+
+// compileCSnake builds the shared object of a C snake.
+func (c *SnakeServerController) compileCSnake(snake *database.Snake) (string, error) {
+	return c.buildCSnake(snake, "so", "-shared")
+}
+
+// buildCSnake compiles a C snake with the extra gcc arguments into a file
+// named after the snake with the given extension. A snake path ending in .c
+// is a single source file; otherwise it is a project directory and every .c
+// file in it is compiled together.
+func (c *SnakeServerController) buildCSnake(snake *database.Snake, ext string, extra ...string) (string, error) {
+	cHeaderDir := filepath.Dir(c.cHeaderPath)
+
+	var sources []string
+	var outputPath string
+	args := append(slices.Clone(extra), "-I"+cHeaderDir)
+	if strings.HasSuffix(snake.Path, ".c") {
+		sources = []string{snake.Path}
+		outputPath = snake.Path[:len(snake.Path)-2] + "." + ext
+	} else {
+		err := filepath.WalkDir(snake.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ".c") {
+				sources = append(sources, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("error listing sources of snake %d: %w", snake.ID, err)
+		}
+		if len(sources) == 0 {
+			return "", fmt.Errorf("snake %d has no .c sources", snake.ID)
+		}
+		outputPath = filepath.Join(snake.Path, "snake."+ext)
+		args = append(args, "-I"+snake.Path)
+	}
+	args = append(args, "-o", outputPath)
+	args = append(args, sources...)
+
+	slog.Debug("Compiling C snake", "snake_id", snake.ID, "command", "gcc "+strings.Join(args, " "))
+	compileCmd := exec.Command("gcc", args...)
+	compileCmd.Stdout = os.Stdout
+	compileCmd.Stderr = os.Stderr
+	if err := compileCmd.Run(); err != nil {
+		return "", fmt.Errorf("error compiling snake %d: %w", snake.ID, err)
+	}
+	return outputPath, nil
+}
+
+// RestartSnake stops the server of a snake, if it runs, and starts it again.
+func (c *SnakeServerController) RestartSnake(ctx context.Context, snake *database.Snake) error {
+	snakeRestarts.Inc()
+	c.stopAndRemoveServer(snake.ID)
+	return c.ManageSnake(ctx, snake)
+}
+
+// ProcessStatuses counts the managed snake servers by the state of their
+// process: running, exited or stopped.
+func (c *SnakeServerController) ProcessStatuses() map[string]int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	statuses := map[string]int{}
+	for _, server := range c.servers {
+		if server.command == nil {
+			// still starting
+			continue
+		}
+		statuses[server.processStatus()]++
+	}
+	return statuses
+}
+
+// PortUsage tells how many of the MAX_PORTS ports are reserved and how many
+// are held by other processes.
+func (c *SnakeServerController) PortUsage() PortUsage {
+	return c.ports.usage()
+}
+
+// processStatus tells whether the process of a server still runs. A process
+// that exited stays a zombie until it is waited for, /proc tells them apart.
+func (s SnakeServer) processStatus() string {
+	if s.command == nil || s.command.Process == nil || s.command.ProcessState != nil {
+		return "stopped"
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", s.command.Process.Pid))
+	if err != nil {
+		if s.command.Process.Signal(syscall.Signal(0)) != nil {
+			return "exited"
+		}
+		return "running"
+	}
+	// the state comes after the command name, which is in parentheses
+	if i := bytes.LastIndexByte(stat, ')'); i >= 0 && i+2 < len(stat) && (stat[i+2] == 'Z' || stat[i+2] == 'X') {
+		return "exited"
+	}
+	return "running"
 }
 
 func (c *SnakeServerController) StopSnake(snakeID int64) {
@@ -284,40 +538,43 @@ func (c *SnakeServerController) GetServer(snakeID int64) *SnakeServer {
 	return c.getServer(snakeID)
 }
 
-func (c *SnakeServerController) getServer(snakeID int64) *SnakeServer {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
-	server, ok := c.servers[snakeID]
-	if !ok {
-		return nil
+// UsesPath reports whether a snake server starting or running, a snake ready
+// to play over stdio or the program of a running game uses the files at
+// path, so that they are kept.
+func (c *SnakeServerController) UsesPath(path string) bool {
+	within := func(p string) bool {
+		rel, err := filepath.Rel(path, p)
+		return err == nil && filepath.IsLocal(rel)
 	}
-	return &server
-}
-
-func (c *SnakeServerController) serverExists(snakeID int64) bool {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	_, ok := c.servers[snakeID]
-	return ok
+	for _, server := range c.servers {
+		if (server.command == nil || server.command.ProcessState == nil) && within(server.path) {
+			return true
+		}
+	}
+	for _, args := range c.stdioCommands {
+		if slices.ContainsFunc(args, within) {
+			return true
+		}
+	}
+	for _, p := range c.stdioPaths {
+		if within(p) {
+			return true
+		}
+	}
+	return false
 }
 
-func (c *SnakeServerController) getEmptyPort() (int, error) {
+// getServer returns the server of a snake, nil until it is started.
+func (c *SnakeServerController) getServer(snakeID int64) *SnakeServer {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	for port := range MAX_PORTS {
-		port += c.basePort
-		if v, ok := c.reservedPorts[port]; !ok || !v {
-			c.reservedPorts[port] = true
-			return port, nil
-		}
+	server, ok := c.servers[snakeID]
+	if !ok || server.command == nil {
+		return nil
 	}
-	return 0, errors.New("no available ports")
-}
-
-func (c *SnakeServerController) addServer(snakeID int64, server SnakeServer) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.servers[snakeID] = server
+	return &server
 }
 
 func (c *SnakeServerController) stopServer(snakeID int64) {
@@ -325,7 +582,7 @@ func (c *SnakeServerController) stopServer(snakeID int64) {
 	defer c.lock.Unlock()
 
 	if _, ok := c.servers[snakeID]; !ok {
-		log.Println("Snake server for snake", snakeID, "not found")
+		slog.Warn("Snake server not found", "snake_id", snakeID)
 		return
 	}
 
@@ -333,12 +590,20 @@ func (c *SnakeServerController) stopServer(snakeID int64) {
 	if server.command != nil {
 		server.command.Process.Kill()
 		server.command.Wait()
+		c.forgetServerProcess(server.command.Process)
 	}
 
-	log.Println("Snake server for snake", snakeID, "stopped")
+	slog.Info("Snake server stopped", "snake_id", snakeID)
 }
 
 func (c *SnakeServerController) stopAndRemoveServer(snakeID int64) {
+	if c.runtime == RuntimeStdio {
+		// the programs of the running games stop with them
+		c.lock.Lock()
+		delete(c.stdioCommands, snakeID)
+		c.lock.Unlock()
+		return
+	}
 	c.stopServer(snakeID)
 	c.removeServer(snakeID)
 }
@@ -348,15 +613,16 @@ func (c *SnakeServerController) removeServer(snakeID int64) {
 	defer c.lock.Unlock()
 
 	if _, ok := c.servers[snakeID]; !ok {
-		log.Println("Snake server for snake", snakeID, "not found")
+		slog.Warn("Snake server not found", "snake_id", snakeID)
 		return
 	}
 
-	log.Println("Removing snake server for snake", c.servers[snakeID])
+	server := c.servers[snakeID]
+	slog.Info("Removing snake server", "snake_id", snakeID, "port", server.port)
 
-	c.reservedPorts[c.servers[snakeID].port] = false
 	delete(c.servers, snakeID)
-	c.servers[snakeID].logFile.Close()
+	c.ports.release(server.port)
+	server.logFile.Close()
 }
 
 func copyFile(src fs.File, dst string) error {
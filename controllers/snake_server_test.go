@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+)
+
+func TestManageSnakeConcurrently(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// a harness that notes each start and serves nothing
+	if err := os.WriteFile("server.js", []byte("require('fs').appendFileSync('starts', 'start\\n')\nsetInterval(() => {}, 1000)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := &SnakeServerController{
+		servers:      map[int64]SnakeServer{},
+		ports:        newPortPool(9000, 10),
+		jsServerPath: "server.js",
+	}
+	c.ports.bindable = func(port int) bool { return true }
+	snake := &database.Snake{ID: 7, Path: "snake.js"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.ManageSnake(context.Background(), snake)
+		}()
+	}
+	wg.Wait()
+	defer c.stopAndRemoveServer(snake.ID)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("call %d: %v", i, err)
+		}
+	}
+	if server := c.getServer(snake.ID); server == nil || server.processStatus() != "running" {
+		t.Fatalf("got server %+v, want it running", server)
+	}
+	// node may take a while to note its start
+	var starts []byte
+	for deadline := time.Now().Add(5 * time.Second); len(starts) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		starts, _ = os.ReadFile("starts")
+	}
+	if n := strings.Count(string(starts), "start"); n != 1 {
+		t.Errorf("got %d servers started, want 1", n)
+	}
+	if got := c.PortUsage().Reserved; got != 1 {
+		t.Errorf("got %d ports reserved, want 1", got)
+	}
+}
+
+func TestManageSnakeStoppedWhileStarting(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("server.js", []byte("setInterval(() => {}, 1000)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := &SnakeServerController{
+		servers:      map[int64]SnakeServer{},
+		ports:        newPortPool(9000, 10),
+		jsServerPath: "server.js",
+	}
+	c.ports.bindable = func(port int) bool { return true }
+	snake := &database.Snake{ID: 7, Path: "snake.js"}
+
+	managed := make(chan error, 1)
+	go func() { managed <- c.ManageSnake(context.Background(), snake) }()
+	// the snake is claimed at once, its server takes a while to start
+	deadline := time.Now().Add(5 * time.Second)
+	for c.PortUsage().Reserved == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the snake was never claimed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if c.getServer(snake.ID) != nil {
+		t.Error("the server is handed out before it started")
+	}
+	c.stopAndRemoveServer(snake.ID)
+
+	if err := <-managed; err == nil {
+		t.Error("the snake stopped while starting was reported managed")
+	}
+	if len(c.servers) != 0 || c.PortUsage().Reserved != 0 {
+		t.Errorf("got servers %+v and %d ports reserved, want none", c.servers, c.PortUsage().Reserved)
+	}
+}
+
+func TestUsesPath(t *testing.T) {
+	stopped := exec.Command("true")
+	if err := stopped.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := &SnakeServerController{
+		servers: map[int64]SnakeServer{
+			// starting
+			1: {path: "uploads/T/v1/snake.py"},
+			2: {path: "uploads/T/v2/snake.py", command: stopped},
+		},
+		stdioCommands: map[int64][]string{3: {"python3", "servers/stdio.py", "uploads/T/v3/snake.py"}},
+		stdioPaths:    map[*game.StdioProvider]string{game.NewStdioProvider(nil): "uploads/T/v4"},
+	}
+	for path, want := range map[string]bool{
+		"uploads/T/v1":  true,
+		"uploads/T/v2":  false,
+		"uploads/T/v3":  true,
+		"uploads/T/v4":  true,
+		"uploads/T/v10": false,
+		"uploads/U/v1":  false,
+	} {
+		if got := c.UsesPath(path); got != want {
+			t.Errorf("UsesPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+)
+
+// SnakeRuntime is how the uploaded snakes are played.
+type SnakeRuntime string
+
+const (
+	// RuntimeHTTP runs a snake server per uploaded snake, on a port of the
+	// pool from BASE_PORT, as long as the snake is managed.
+	RuntimeHTTP SnakeRuntime = "http"
+	// RuntimeStdio starts a program per snake and per game, which answers
+	// the requests of the engine on its standard output, see
+	// game.StdioProvider. It needs no port and stops with the game.
+	RuntimeStdio SnakeRuntime = "stdio"
+)
+
+// ParseSnakeRuntime checks a runtime name, an empty name is RuntimeHTTP.
+func ParseSnakeRuntime(name string) (SnakeRuntime, error) {
+	switch runtime := SnakeRuntime(name); runtime {
+	case "":
+		return RuntimeHTTP, nil
+	case RuntimeHTTP, RuntimeStdio:
+		return runtime, nil
+	default:
+		return "", fmt.Errorf("unknown snake runtime %q, valid runtimes are %q and %q", name, RuntimeHTTP, RuntimeStdio)
+	}
+}
+
+// String, Set and Type let a SnakeRuntime be used as a command flag.
+func (r *SnakeRuntime) String() string { return string(*r) }
+
+func (r *SnakeRuntime) Set(name string) error {
+	runtime, err := ParseSnakeRuntime(name)
+	if err != nil {
+		return err
+	}
+	*r = runtime
+	return nil
+}
+
+func (r *SnakeRuntime) Type() string { return "runtime" }
+
+// Runtime returns how the uploaded snakes are played.
+func (c *SnakeServerController) Runtime() SnakeRuntime {
+	return c.runtime
+}
+
+// installTemplate copies a file of the static directory to dst.
+func installTemplate(staticFS fs.FS, name string, dst string) error {
+	src, err := staticFS.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return copyFile(src, dst)
+}
+
+// prepareStdioSnake checks that a snake can be played over stdio, compiling
+// C snakes with the stdio harness, and keeps its command for the games.
+func (c *SnakeServerController) prepareStdioSnake(ctx context.Context, snake *database.Snake) error {
+	var args []string
+	if strings.HasSuffix(snake.Path, ".py") {
+		args = []string{"python3", c.pyStdioPath, snake.Path}
+	} else if strings.HasSuffix(snake.Path, ".js") {
+		args = []string{"node", c.jsStdioPath, snake.Path}
+	} else if strings.HasSuffix(snake.Path, ".c") || snake.Lang == ".c" {
+		programPath, err := c.buildCSnake(snake, "stdio", c.cStdioPath)
+		if err != nil {
+			return err
+		}
+		args = []string{programPath}
+	} else {
+		return fmt.Errorf("invalid snake file extension: %s", snake.Path)
+	}
+	if _, err := os.Stat(snake.Path); err != nil {
+		return fmt.Errorf("snake %d: %w", snake.ID, err)
+	}
+
+	slog.InfoContext(ctx, "Snake ready to play over stdio", "snake_id", snake.ID, "command", strings.Join(args, " "))
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.stdioCommands[snake.ID] = args
+	return nil
+}
+
+// StdioSnake returns the game snake of an uploaded snake played over stdio.
+// Its program starts with the game and stops with it, each game has its
+// own. The snake writes its standard error to its log file.
+func (c *SnakeServerController) StdioSnake(ctx context.Context, snake *database.Snake) (game.Snake, error) {
+	c.lock.RLock()
+	args, ok := c.stdioCommands[snake.ID]
+	c.lock.RUnlock()
+	if !ok {
+		if err := c.prepareStdioSnake(ctx, snake); err != nil {
+			return game.Snake{}, err
+		}
+		c.lock.RLock()
+		args = c.stdioCommands[snake.ID]
+		c.lock.RUnlock()
+	}
+
+	log := &snakeLog{path: "snake-" + strconv.FormatInt(snake.ID, 10) + ".log"}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = log
+	provider := game.NewStdioProvider(cmd)
+	c.lock.Lock()
+	c.stdioPaths[provider] = snake.Path
+	c.lock.Unlock()
+	snakeID := snake.ID
+	provider.OnStart = func(process *os.Process) {
+		c.watchStdioProcess(provider, snakeID, process)
+	}
+	return game.Snake{
+		// the URL records the command, batches start their own programs
+		URL:      game.StdioURLScheme + ":" + strings.Join(args, " "),
+		SnakeID:  snake.ID,
+		Provider: stdioSnakeProvider{StdioProvider: provider, log: log, controller: c},
+	}, nil
+}
+
+// stdioSnakeProvider closes the log of the snake with its program, stops
+// sampling it and lets its files go, see UsesPath.
+type stdioSnakeProvider struct {
+	*game.StdioProvider
+	log        *snakeLog
+	controller *SnakeServerController
+}
+
+func (p stdioSnakeProvider) Close() error {
+	err := p.StdioProvider.Close()
+	p.controller.forgetStdioProcess(p.StdioProvider)
+	p.controller.lock.Lock()
+	delete(p.controller.stdioPaths, p.StdioProvider)
+	p.controller.lock.Unlock()
+	p.log.Close()
+	return err
+}
+
+// snakeLog appends to the log file of a snake, which is only opened if the
+// snake writes to it: the games that never start leave no file open.
+type snakeLog struct {
+	path string
+	lock sync.Mutex
+	file *os.File
+	err  error
+}
+
+func (l *snakeLog) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil && l.err == nil {
+		l.file, l.err = os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	}
+	if l.err != nil {
+		// the output of the snake is lost, not its game
+		return len(p), nil
+	}
+	return l.file.Write(p)
+}
+
+func (l *snakeLog) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	l.err = io.ErrClosedPipe
+	return err
+}
@@ -12,7 +12,7 @@ type TeamController struct {
 }
 
 func NewTeamController(db database.DBTX) *TeamController {
-	return &TeamController{queries: database.New(db)}
+	return &TeamController{queries: database.New(database.Timed(db))}
 }
 
 func (c *TeamController) GetTeam(ctx context.Context, id int64) (*database.Team, error) {
@@ -56,6 +56,14 @@ func (c *TeamController) CreateTeam(ctx context.Context, name string, code_id in
 	return &team_model, nil
 }
 
+func (c *TeamController) CreateAdminTeam(ctx context.Context, name string, code_id int64) (*database.Team, error) {
+	team_model, err := c.queries.CreateAdminTeam(ctx, database.CreateAdminTeamParams{Name: name, CodeID: code_id})
+	if err != nil {
+		return nil, err
+	}
+	return &team_model, nil
+}
+
 func (c *TeamController) UpdateTeam(ctx context.Context, team *database.Team) (*database.Team, error) {
 	err := c.queries.UpdateTeam(ctx, database.UpdateTeamParams{ID: team.ID, Name: team.Name, CodeID: team.CodeID})
 	if err != nil {
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/secomp2025/localsnake/database"
+)
+
+// Token scopes. A token may only call the endpoints its scopes allow.
+const (
+	ScopeUpload = "upload"
+	ScopePlay   = "play"
+	ScopeRead   = "read"
+)
+
+// AllScopes lists every scope a token can be granted.
+var AllScopes = []string{ScopeUpload, ScopePlay, ScopeRead}
+
+// tokenPrefix marks localsnake tokens so they are easy to spot in scripts.
+const tokenPrefix = "lsk_"
+
+var ErrInvalidScope = errors.New("invalid token scope")
+
+type TokenController struct {
+	queries *database.Queries
+}
+
+func NewTokenController(db database.DBTX) *TokenController {
+	return &TokenController{queries: database.New(database.Timed(db))}
+}
+
+// CreateToken generates a new token for the team. The plaintext is returned
+// once and only its hash is stored.
+func (c *TokenController) CreateToken(ctx context.Context, team_id int64, name string, scopes []string) (string, *database.ApiToken, error) {
+	if len(scopes) == 0 {
+		return "", nil, ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(AllScopes, scope) {
+			return "", nil, ErrInvalidScope
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	plaintext := tokenPrefix + hex.EncodeToString(secret)
+
+	token_model, err := c.queries.CreateApiToken(ctx, database.CreateApiTokenParams{
+		TeamID:    team_id,
+		Name:      name,
+		Prefix:    plaintext[:len(tokenPrefix)+8],
+		TokenHash: hashToken(plaintext),
+		Scopes:    strings.Join(scopes, ","),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return plaintext, &token_model, nil
+}
+
+// Authenticate returns the active token matching the plaintext, or nil if
+// there is none, and records its use.
+func (c *TokenController) Authenticate(ctx context.Context, plaintext string) (*database.ApiToken, error) {
+	if !strings.HasPrefix(plaintext, tokenPrefix) {
+		return nil, nil
+	}
+	token_model, err := c.queries.FindApiToken(ctx, hashToken(plaintext))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := c.queries.TouchApiToken(ctx, token_model.ID); err != nil {
+		return nil, err
+	}
+	return &token_model, nil
+}
+
+func (c *TokenController) ListTeamTokens(ctx context.Context, team_id int64) ([]database.ApiToken, error) {
+	return c.queries.ListTeamApiTokens(ctx, team_id)
+}
+
+// RevokeToken revokes a token owned by the team. Revoking an unknown or
+// already revoked token is a no-op.
+func (c *TokenController) RevokeToken(ctx context.Context, team_id int64, id int64) error {
+	return c.queries.RevokeApiToken(ctx, database.RevokeApiTokenParams{ID: id, TeamID: team_id})
+}
+
+// TokenScopes splits the stored scope list of a token.
+func TokenScopes(token *database.ApiToken) []string {
+	return strings.Split(token.Scopes, ",")
+}
+
+func hashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
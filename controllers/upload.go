@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+// Upload statuses recorded in the upload audit log.
+const (
+	UploadAccepted = "accepted"
+	UploadFlagged  = "flagged"
+	UploadRejected = "rejected"
+)
+
+var uploadsRecorded = metrics.NewCounter(
+	"localsnake_uploads_total",
+	"Snake uploads recorded in the upload audit log, by status.",
+	"status",
+)
+
+type UploadController struct {
+	queries *database.Queries
+}
+
+func NewUploadController(db database.DBTX) *UploadController {
+	return &UploadController{queries: database.New(database.Timed(db))}
+}
+
+func (c *UploadController) RecordUpload(ctx context.Context, upload database.CreateUploadParams) (*database.Upload, error) {
+	upload_model, err := c.queries.CreateUpload(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	uploadsRecorded.Inc(upload_model.Status)
+	return &upload_model, nil
+}
+
+func (c *UploadController) ListUploads(ctx context.Context, limit int64, offset int64) ([]database.ListUploadsRow, error) {
+	return c.queries.ListUploads(ctx, database.ListUploadsParams{Limit: limit, Offset: offset})
+}
+
+func (c *UploadController) CountUploads(ctx context.Context) (int64, error) {
+	return c.queries.CountUploads(ctx)
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+var queryDuration = metrics.NewHistogram(
+	"localsnake_db_query_duration_seconds",
+	"Time taken by database queries, by sqlc query name. Row iteration is not included.",
+	[]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1},
+	"query",
+)
+
+// timedDB records the duration of every query run through it.
+type timedDB struct {
+	db DBTX
+}
+
+// Timed wraps db so that the queries run through it show up in the
+// localsnake_db_query_duration_seconds metric.
+func Timed(db DBTX) DBTX {
+	if _, ok := db.(timedDB); ok {
+		return db
+	}
+	return timedDB{db: db}
+}
+
+func (t timedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer observeQuery(query, time.Now())
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t timedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	defer observeQuery(query, time.Now())
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t timedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer observeQuery(query, time.Now())
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t timedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer observeQuery(query, time.Now())
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func observeQuery(query string, start time.Time) {
+	queryDuration.Observe(time.Since(start).Seconds(), queryName(query))
+}
+
+// queryName returns the name sqlc gives a query in its "-- name: X :kind"
+// comment, or "other" for queries written by hand.
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "other"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
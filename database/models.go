@@ -8,12 +8,70 @@ import (
 	"database/sql"
 )
 
+type ApiToken struct {
+	ID         int64
+	TeamID     int64
+	Name       string
+	Prefix     string
+	TokenHash  string
+	Scopes     string
+	CreatedAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
+type AuditEvent struct {
+	ID         int64
+	TeamID     sql.NullInt64
+	Actor      string
+	Action     string
+	Target     sql.NullString
+	RemoteAddr sql.NullString
+	Payload    sql.NullString
+	CreatedAt  sql.NullTime
+}
+
 type Code struct {
 	ID        int64
 	Code      string
 	CreatedAt sql.NullTime
 }
 
+type Game struct {
+	ID            string
+	Status        string
+	Seed          int64
+	Turns         int64
+	Winner        sql.NullString
+	IsDraw        sql.NullBool
+	ReplayPath    sql.NullString
+	CreatedAt     sql.NullTime
+	FinishedAt    sql.NullTime
+	AbortReason   sql.NullString
+	TimeoutPolicy sql.NullString
+	Map           sql.NullString
+	Fog           sql.NullString
+	VisionRadius  sql.NullInt64
+	VisionOverlay sql.NullBool
+	SquadRules    sql.NullString
+	WinnerSquad   sql.NullString
+}
+
+type GameSnake struct {
+	ID               int64
+	GameID           string
+	SnakeID          sql.NullInt64
+	Name             string
+	Url              string
+	IsWinner         sql.NullBool
+	Timeouts         int64
+	Moves            int64
+	StatusErrors     int64
+	DecodeErrors     int64
+	LatencyHistogram sql.NullString
+	Squad            sql.NullString
+}
+
 type Snake struct {
 	ID        int64
 	Path      string
@@ -30,3 +88,16 @@ type Team struct {
 	CodeID    int64
 	CreatedAt sql.NullTime
 }
+
+type Upload struct {
+	ID         int64
+	TeamID     int64
+	SnakeID    sql.NullInt64
+	Filename   string
+	Size       int64
+	Lang       sql.NullString
+	Status     string
+	Findings   sql.NullString
+	RemoteAddr sql.NullString
+	CreatedAt  sql.NullTime
+}
@@ -7,8 +7,141 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
+const countGames = `-- name: CountGames :one
+SELECT COUNT(*)
+FROM games
+`
+
+func (q *Queries) CountGames(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countGames)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUploads = `-- name: CountUploads :one
+SELECT COUNT(*)
+FROM uploads
+`
+
+func (q *Queries) CountUploads(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUploads)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAdminTeam = `-- name: CreateAdminTeam :one
+INSERT INTO teams (name, code_id, is_admin)
+VALUES (?, ?, TRUE)
+RETURNING id, name, is_admin, code_id, created_at
+`
+
+type CreateAdminTeamParams struct {
+	Name   string
+	CodeID int64
+}
+
+func (q *Queries) CreateAdminTeam(ctx context.Context, arg CreateAdminTeamParams) (Team, error) {
+	row := q.db.QueryRowContext(ctx, createAdminTeam, arg.Name, arg.CodeID)
+	var i Team
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.IsAdmin,
+		&i.CodeID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createApiToken = `-- name: CreateApiToken :one
+INSERT INTO api_tokens (team_id, name, prefix, token_hash, scopes)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, team_id, name, prefix, token_hash, scopes, created_at, last_used_at, revoked_at
+`
+
+type CreateApiTokenParams struct {
+	TeamID    int64
+	Name      string
+	Prefix    string
+	TokenHash string
+	Scopes    string
+}
+
+// ------ API TOKEN --------
+func (q *Queries) CreateApiToken(ctx context.Context, arg CreateApiTokenParams) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createApiToken,
+		arg.TeamID,
+		arg.Name,
+		arg.Prefix,
+		arg.TokenHash,
+		arg.Scopes,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Name,
+		&i.Prefix,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+        team_id,
+        actor,
+        action,
+        target,
+        remote_addr,
+        payload
+    )
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, team_id, actor, action, target, remote_addr, payload, created_at
+`
+
+type CreateAuditEventParams struct {
+	TeamID     sql.NullInt64
+	Actor      string
+	Action     string
+	Target     sql.NullString
+	RemoteAddr sql.NullString
+	Payload    sql.NullString
+}
+
+// ------ AUDIT EVENT --------
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEvent,
+		arg.TeamID,
+		arg.Actor,
+		arg.Action,
+		arg.Target,
+		arg.RemoteAddr,
+		arg.Payload,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Actor,
+		&i.Action,
+		&i.Target,
+		&i.RemoteAddr,
+		&i.Payload,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createCode = `-- name: CreateCode :one
 INSERT INTO codes (code)
 VALUES (?)
@@ -22,6 +155,106 @@ func (q *Queries) CreateCode(ctx context.Context, code string) (Code, error) {
 	return i, err
 }
 
+const createGame = `-- name: CreateGame :one
+INSERT INTO games (
+        id,
+        seed,
+        replay_path,
+        map,
+        fog,
+        vision_radius,
+        vision_overlay,
+        squad_rules
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, status, seed, turns, winner, is_draw, replay_path, created_at, finished_at, abort_reason, timeout_policy, map, fog, vision_radius, vision_overlay, squad_rules, winner_squad
+`
+
+type CreateGameParams struct {
+	ID            string
+	Seed          int64
+	ReplayPath    sql.NullString
+	Map           sql.NullString
+	Fog           sql.NullString
+	VisionRadius  sql.NullInt64
+	VisionOverlay sql.NullBool
+	SquadRules    sql.NullString
+}
+
+func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (Game, error) {
+	row := q.db.QueryRowContext(ctx, createGame,
+		arg.ID,
+		arg.Seed,
+		arg.ReplayPath,
+		arg.Map,
+		arg.Fog,
+		arg.VisionRadius,
+		arg.VisionOverlay,
+		arg.SquadRules,
+	)
+	var i Game
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Seed,
+		&i.Turns,
+		&i.Winner,
+		&i.IsDraw,
+		&i.ReplayPath,
+		&i.CreatedAt,
+		&i.FinishedAt,
+		&i.AbortReason,
+		&i.TimeoutPolicy,
+		&i.Map,
+		&i.Fog,
+		&i.VisionRadius,
+		&i.VisionOverlay,
+		&i.SquadRules,
+		&i.WinnerSquad,
+	)
+	return i, err
+}
+
+const createGameSnake = `-- name: CreateGameSnake :one
+INSERT INTO game_snakes (game_id, snake_id, name, url, squad)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, game_id, snake_id, name, url, is_winner, timeouts, moves, status_errors, decode_errors, latency_histogram, squad
+`
+
+type CreateGameSnakeParams struct {
+	GameID  string
+	SnakeID sql.NullInt64
+	Name    string
+	Url     string
+	Squad   sql.NullString
+}
+
+func (q *Queries) CreateGameSnake(ctx context.Context, arg CreateGameSnakeParams) (GameSnake, error) {
+	row := q.db.QueryRowContext(ctx, createGameSnake,
+		arg.GameID,
+		arg.SnakeID,
+		arg.Name,
+		arg.Url,
+		arg.Squad,
+	)
+	var i GameSnake
+	err := row.Scan(
+		&i.ID,
+		&i.GameID,
+		&i.SnakeID,
+		&i.Name,
+		&i.Url,
+		&i.IsWinner,
+		&i.Timeouts,
+		&i.Moves,
+		&i.StatusErrors,
+		&i.DecodeErrors,
+		&i.LatencyHistogram,
+		&i.Squad,
+	)
+	return i, err
+}
+
 const createSnake = `-- name: CreateSnake :one
 INSERT INTO snakes (path, lang, team_id)
 VALUES (?, ?, ?)
@@ -72,6 +305,60 @@ func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, e
 	return i, err
 }
 
+const createUpload = `-- name: CreateUpload :one
+INSERT INTO uploads (
+        team_id,
+        snake_id,
+        filename,
+        size,
+        lang,
+        status,
+        findings,
+        remote_addr
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, team_id, snake_id, filename, size, lang, status, findings, remote_addr, created_at
+`
+
+type CreateUploadParams struct {
+	TeamID     int64
+	SnakeID    sql.NullInt64
+	Filename   string
+	Size       int64
+	Lang       sql.NullString
+	Status     string
+	Findings   sql.NullString
+	RemoteAddr sql.NullString
+}
+
+// ------ UPLOAD --------
+func (q *Queries) CreateUpload(ctx context.Context, arg CreateUploadParams) (Upload, error) {
+	row := q.db.QueryRowContext(ctx, createUpload,
+		arg.TeamID,
+		arg.SnakeID,
+		arg.Filename,
+		arg.Size,
+		arg.Lang,
+		arg.Status,
+		arg.Findings,
+		arg.RemoteAddr,
+	)
+	var i Upload
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.SnakeID,
+		&i.Filename,
+		&i.Size,
+		&i.Lang,
+		&i.Status,
+		&i.Findings,
+		&i.RemoteAddr,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const deleteCode = `-- name: DeleteCode :exec
 DELETE FROM codes
 WHERE code = ?
@@ -102,6 +389,31 @@ func (q *Queries) DeleteTeam(ctx context.Context, id int64) error {
 	return err
 }
 
+const findApiToken = `-- name: FindApiToken :one
+SELECT id, team_id, name, prefix, token_hash, scopes, created_at, last_used_at, revoked_at
+FROM api_tokens
+WHERE token_hash = ?
+    AND revoked_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) FindApiToken(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, findApiToken, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.Name,
+		&i.Prefix,
+		&i.TokenHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
 const findCode = `-- name: FindCode :one
 SELECT id, code, created_at
 FROM codes
@@ -116,6 +428,44 @@ func (q *Queries) FindCode(ctx context.Context, code string) (Code, error) {
 	return i, err
 }
 
+const finishGame = `-- name: FinishGame :exec
+UPDATE games
+SET status = ?,
+    turns = ?,
+    winner = ?,
+    is_draw = ?,
+    abort_reason = ?,
+    timeout_policy = ?,
+    winner_squad = ?,
+    finished_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type FinishGameParams struct {
+	Status        string
+	Turns         int64
+	Winner        sql.NullString
+	IsDraw        sql.NullBool
+	AbortReason   sql.NullString
+	TimeoutPolicy sql.NullString
+	WinnerSquad   sql.NullString
+	ID            string
+}
+
+func (q *Queries) FinishGame(ctx context.Context, arg FinishGameParams) error {
+	_, err := q.db.ExecContext(ctx, finishGame,
+		arg.Status,
+		arg.Turns,
+		arg.Winner,
+		arg.IsDraw,
+		arg.AbortReason,
+		arg.TimeoutPolicy,
+		arg.WinnerSquad,
+		arg.ID,
+	)
+	return err
+}
+
 const getCode = `-- name: GetCode :one
 SELECT id, code, created_at
 FROM codes
@@ -145,6 +495,39 @@ func (q *Queries) GetCodeByTeam(ctx context.Context, id int64) (Code, error) {
 	return i, err
 }
 
+const getGame = `-- name: GetGame :one
+SELECT id, status, seed, turns, winner, is_draw, replay_path, created_at, finished_at, abort_reason, timeout_policy, map, fog, vision_radius, vision_overlay, squad_rules, winner_squad
+FROM games
+WHERE id = ?
+LIMIT 1
+`
+
+// ------ GAME --------
+func (q *Queries) GetGame(ctx context.Context, id string) (Game, error) {
+	row := q.db.QueryRowContext(ctx, getGame, id)
+	var i Game
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.Seed,
+		&i.Turns,
+		&i.Winner,
+		&i.IsDraw,
+		&i.ReplayPath,
+		&i.CreatedAt,
+		&i.FinishedAt,
+		&i.AbortReason,
+		&i.TimeoutPolicy,
+		&i.Map,
+		&i.Fog,
+		&i.VisionRadius,
+		&i.VisionOverlay,
+		&i.SquadRules,
+		&i.WinnerSquad,
+	)
+	return i, err
+}
+
 const getSnake = `-- name: GetSnake :one
 SELECT id, path, lang, created_at, updated_at, team_id
 FROM snakes
@@ -208,6 +591,81 @@ func (q *Queries) GetTeamByCode(ctx context.Context, codeID int64) (Team, error)
 	return i, err
 }
 
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT id, team_id, actor, action, target, remote_addr, payload, created_at
+FROM audit_events
+WHERE (
+        ? IS NULL
+        OR action = ?
+    )
+    AND (
+        ? IS NULL
+        OR team_id = ?
+    )
+    AND (
+        ? IS NULL
+        OR created_at >= ?
+    )
+    AND (
+        ? IS NULL
+        OR created_at < ?
+    )
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+type ListAuditEventsParams struct {
+	Action sql.NullString
+	TeamID sql.NullInt64
+	Since  sql.NullTime
+	Until  sql.NullTime
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEvents,
+		arg.Action,
+		arg.Action,
+		arg.TeamID,
+		arg.TeamID,
+		arg.Since,
+		arg.Since,
+		arg.Until,
+		arg.Until,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Actor,
+			&i.Action,
+			&i.Target,
+			&i.RemoteAddr,
+			&i.Payload,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCodes = `-- name: ListCodes :many
 SELECT id, code, created_at
 FROM codes
@@ -237,6 +695,156 @@ func (q *Queries) ListCodes(ctx context.Context) ([]Code, error) {
 	return items, nil
 }
 
+const listGames = `-- name: ListGames :many
+SELECT id, status, seed, turns, winner, is_draw, replay_path, created_at, finished_at, abort_reason, timeout_policy, map, fog, vision_radius, vision_overlay, squad_rules, winner_squad
+FROM games
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type ListGamesParams struct {
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) ListGames(ctx context.Context, arg ListGamesParams) ([]Game, error) {
+	rows, err := q.db.QueryContext(ctx, listGames, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Game
+	for rows.Next() {
+		var i Game
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.Seed,
+			&i.Turns,
+			&i.Winner,
+			&i.IsDraw,
+			&i.ReplayPath,
+			&i.CreatedAt,
+			&i.FinishedAt,
+			&i.AbortReason,
+			&i.TimeoutPolicy,
+			&i.Map,
+			&i.Fog,
+			&i.VisionRadius,
+			&i.VisionOverlay,
+			&i.SquadRules,
+			&i.WinnerSquad,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGameSnakes = `-- name: ListGameSnakes :many
+SELECT id, game_id, snake_id, name, url, is_winner, timeouts, moves, status_errors, decode_errors, latency_histogram, squad
+FROM game_snakes
+WHERE game_id = ?
+ORDER BY id ASC
+`
+
+func (q *Queries) ListGameSnakes(ctx context.Context, gameID string) ([]GameSnake, error) {
+	rows, err := q.db.QueryContext(ctx, listGameSnakes, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GameSnake
+	for rows.Next() {
+		var i GameSnake
+		if err := rows.Scan(
+			&i.ID,
+			&i.GameID,
+			&i.SnakeID,
+			&i.Name,
+			&i.Url,
+			&i.IsWinner,
+			&i.Timeouts,
+			&i.Moves,
+			&i.StatusErrors,
+			&i.DecodeErrors,
+			&i.LatencyHistogram,
+			&i.Squad,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSnakeMoveStats = `-- name: ListSnakeMoveStats :many
+SELECT gs.snake_id,
+    s.team_id,
+    gs.moves,
+    gs.timeouts,
+    gs.status_errors,
+    gs.decode_errors,
+    gs.latency_histogram
+FROM game_snakes gs
+    INNER JOIN snakes s ON s.id = gs.snake_id
+WHERE gs.moves > 0
+`
+
+type ListSnakeMoveStatsRow struct {
+	SnakeID          sql.NullInt64
+	TeamID           int64
+	Moves            int64
+	Timeouts         int64
+	StatusErrors     int64
+	DecodeErrors     int64
+	LatencyHistogram sql.NullString
+}
+
+func (q *Queries) ListSnakeMoveStats(ctx context.Context) ([]ListSnakeMoveStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSnakeMoveStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSnakeMoveStatsRow
+	for rows.Next() {
+		var i ListSnakeMoveStatsRow
+		if err := rows.Scan(
+			&i.SnakeID,
+			&i.TeamID,
+			&i.Moves,
+			&i.Timeouts,
+			&i.StatusErrors,
+			&i.DecodeErrors,
+			&i.LatencyHistogram,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSnakes = `-- name: ListSnakes :many
 SELECT id, path, lang, created_at, updated_at, team_id
 FROM snakes
@@ -273,6 +881,136 @@ func (q *Queries) ListSnakes(ctx context.Context) ([]Snake, error) {
 	return items, nil
 }
 
+const listTeamApiTokens = `-- name: ListTeamApiTokens :many
+SELECT id, team_id, name, prefix, token_hash, scopes, created_at, last_used_at, revoked_at
+FROM api_tokens
+WHERE team_id = ?
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListTeamApiTokens(ctx context.Context, teamID int64) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamApiTokens, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.Name,
+			&i.Prefix,
+			&i.TokenHash,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeams = `-- name: ListTeams :many
+SELECT id, name, is_admin, code_id, created_at
+FROM teams
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListTeams(ctx context.Context) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, listTeams)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.IsAdmin,
+			&i.CodeID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamSnakeMoveStats = `-- name: ListTeamSnakeMoveStats :many
+SELECT gs.snake_id,
+    s.team_id,
+    gs.moves,
+    gs.timeouts,
+    gs.status_errors,
+    gs.decode_errors,
+    gs.latency_histogram
+FROM game_snakes gs
+    INNER JOIN snakes s ON s.id = gs.snake_id
+WHERE s.team_id = ?
+    AND gs.moves > 0
+`
+
+type ListTeamSnakeMoveStatsRow struct {
+	SnakeID          sql.NullInt64
+	TeamID           int64
+	Moves            int64
+	Timeouts         int64
+	StatusErrors     int64
+	DecodeErrors     int64
+	LatencyHistogram sql.NullString
+}
+
+func (q *Queries) ListTeamSnakeMoveStats(ctx context.Context, teamID int64) ([]ListTeamSnakeMoveStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamSnakeMoveStats, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTeamSnakeMoveStatsRow
+	for rows.Next() {
+		var i ListTeamSnakeMoveStatsRow
+		if err := rows.Scan(
+			&i.SnakeID,
+			&i.TeamID,
+			&i.Moves,
+			&i.Timeouts,
+			&i.StatusErrors,
+			&i.DecodeErrors,
+			&i.LatencyHistogram,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTeamSnakes = `-- name: ListTeamSnakes :many
 SELECT id, path, lang, created_at, updated_at, team_id
 FROM snakes
@@ -310,27 +1048,55 @@ func (q *Queries) ListTeamSnakes(ctx context.Context, teamID int64) ([]Snake, er
 	return items, nil
 }
 
-const listTeams = `-- name: ListTeams :many
-SELECT id, name, is_admin, code_id, created_at
-FROM teams
-ORDER BY created_at ASC
+const listUploads = `-- name: ListUploads :many
+SELECT u.id, u.team_id, u.snake_id, u.filename, u.size, u.lang, u.status, u.findings, u.remote_addr, u.created_at,
+    t.name AS team_name
+FROM uploads u
+    INNER JOIN teams t ON t.id = u.team_id
+ORDER BY u.id DESC
+LIMIT ? OFFSET ?
 `
 
-func (q *Queries) ListTeams(ctx context.Context) ([]Team, error) {
-	rows, err := q.db.QueryContext(ctx, listTeams)
+type ListUploadsParams struct {
+	Limit  int64
+	Offset int64
+}
+
+type ListUploadsRow struct {
+	ID         int64
+	TeamID     int64
+	SnakeID    sql.NullInt64
+	Filename   string
+	Size       int64
+	Lang       sql.NullString
+	Status     string
+	Findings   sql.NullString
+	RemoteAddr sql.NullString
+	CreatedAt  sql.NullTime
+	TeamName   string
+}
+
+func (q *Queries) ListUploads(ctx context.Context, arg ListUploadsParams) ([]ListUploadsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUploads, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Team
+	var items []ListUploadsRow
 	for rows.Next() {
-		var i Team
+		var i ListUploadsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.IsAdmin,
-			&i.CodeID,
+			&i.TeamID,
+			&i.SnakeID,
+			&i.Filename,
+			&i.Size,
+			&i.Lang,
+			&i.Status,
+			&i.Findings,
+			&i.RemoteAddr,
 			&i.CreatedAt,
+			&i.TeamName,
 		); err != nil {
 			return nil, err
 		}
@@ -345,6 +1111,103 @@ func (q *Queries) ListTeams(ctx context.Context) ([]Team, error) {
 	return items, nil
 }
 
+const revokeApiToken = `-- name: RevokeApiToken :exec
+UPDATE api_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ?
+    AND team_id = ?
+    AND revoked_at IS NULL
+`
+
+type RevokeApiTokenParams struct {
+	ID     int64
+	TeamID int64
+}
+
+func (q *Queries) RevokeApiToken(ctx context.Context, arg RevokeApiTokenParams) error {
+	_, err := q.db.ExecContext(ctx, revokeApiToken, arg.ID, arg.TeamID)
+	return err
+}
+
+const setGameWinner = `-- name: SetGameWinner :exec
+UPDATE game_snakes
+SET is_winner = TRUE
+WHERE game_id = ?
+    AND id = ?
+`
+
+type SetGameWinnerParams struct {
+	GameID string
+	ID     int64
+}
+
+func (q *Queries) SetGameWinner(ctx context.Context, arg SetGameWinnerParams) error {
+	_, err := q.db.ExecContext(ctx, setGameWinner, arg.GameID, arg.ID)
+	return err
+}
+
+const setSquadWinner = `-- name: SetSquadWinner :exec
+UPDATE game_snakes
+SET is_winner = TRUE
+WHERE game_id = ?
+    AND squad = ?
+`
+
+type SetSquadWinnerParams struct {
+	GameID string
+	Squad  sql.NullString
+}
+
+func (q *Queries) SetSquadWinner(ctx context.Context, arg SetSquadWinnerParams) error {
+	_, err := q.db.ExecContext(ctx, setSquadWinner, arg.GameID, arg.Squad)
+	return err
+}
+
+const setGameSnakeStats = `-- name: SetGameSnakeStats :exec
+UPDATE game_snakes
+SET moves = ?,
+    timeouts = ?,
+    status_errors = ?,
+    decode_errors = ?,
+    latency_histogram = ?
+WHERE game_id = ?
+    AND id = ?
+`
+
+type SetGameSnakeStatsParams struct {
+	Moves            int64
+	Timeouts         int64
+	StatusErrors     int64
+	DecodeErrors     int64
+	LatencyHistogram sql.NullString
+	GameID           string
+	ID               int64
+}
+
+func (q *Queries) SetGameSnakeStats(ctx context.Context, arg SetGameSnakeStatsParams) error {
+	_, err := q.db.ExecContext(ctx, setGameSnakeStats,
+		arg.Moves,
+		arg.Timeouts,
+		arg.StatusErrors,
+		arg.DecodeErrors,
+		arg.LatencyHistogram,
+		arg.GameID,
+		arg.ID,
+	)
+	return err
+}
+
+const touchApiToken = `-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) TouchApiToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchApiToken, id)
+	return err
+}
+
 const updateCode = `-- name: UpdateCode :exec
 UPDATE codes
 SET code = ?
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -15,20 +16,19 @@ func Init(ctx context.Context, path string) error {
 	if DB != nil {
 		return nil
 	}
+	// Pragmas to improve reliability and enforce FKs. They are given in the
+	// DSN so that the driver applies them to every pooled connection, not
+	// only to the one that happens to run a PRAGMA statement.
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	dsn := path + sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
 	// modernc.org/sqlite driver name is "sqlite"
-	db, err := sql.Open("sqlite", path)
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return fmt.Errorf("open sqlite: %w", err)
 	}
-	// Pragmas to improve reliability and enforce FKs
-	if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys = ON;"); err != nil {
-		_ = db.Close()
-		return fmt.Errorf("enable foreign_keys: %w", err)
-	}
-	if _, err := db.ExecContext(ctx, "PRAGMA busy_timeout = 5000;"); err != nil {
-		_ = db.Close()
-		return fmt.Errorf("set busy_timeout: %w", err)
-	}
 	if err := db.PingContext(ctx); err != nil {
 		_ = db.Close()
 		return fmt.Errorf("ping: %w", err)
@@ -46,3 +46,49 @@ func Close() error {
 	}
 	return nil
 }
+
+// addedColumns lists the columns added to tables after their creation. The
+// schema script only creates missing tables, so Migrate adds these to
+// databases created before them.
+var addedColumns = []struct {
+	table, column, definition string
+}{
+	{"games", "abort_reason", "TEXT"},
+	{"games", "timeout_policy", "TEXT"},
+	{"games", "map", "TEXT"},
+	{"games", "fog", "TEXT"},
+	{"games", "vision_radius", "INTEGER"},
+	{"games", "vision_overlay", "BOOLEAN"},
+	{"games", "squad_rules", "TEXT"},
+	{"games", "winner_squad", "TEXT"},
+	{"game_snakes", "timeouts", "INTEGER NOT NULL DEFAULT 0"},
+	{"game_snakes", "moves", "INTEGER NOT NULL DEFAULT 0"},
+	{"game_snakes", "status_errors", "INTEGER NOT NULL DEFAULT 0"},
+	{"game_snakes", "decode_errors", "INTEGER NOT NULL DEFAULT 0"},
+	{"game_snakes", "latency_histogram", "TEXT"},
+	{"game_snakes", "squad", "TEXT"},
+}
+
+// Migrate applies an idempotent schema script to the open database.
+func Migrate(ctx context.Context, schema string) error {
+	if DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if _, err := DB.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("apply schema: %w", err)
+	}
+	for _, c := range addedColumns {
+		var n int
+		err := DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", c.table, c.column).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("inspect %s: %w", c.table, err)
+		}
+		if n > 0 {
+			continue
+		}
+		if _, err := DB.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)); err != nil {
+			return fmt.Errorf("add %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
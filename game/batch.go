@@ -0,0 +1,334 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/logging"
+)
+
+// BatchOptions configures a batch of headless games between the same snakes.
+type BatchOptions struct {
+	// Template holds the game options shared by every game: board size,
+	// map, rules, timeout and the snakes' Names and URLs. Seed is the seed of
+	// the first game, game i is played with Seed+i.
+	Template GameState
+	// Games is the number of games to play.
+	Games int
+	// Parallelism is how many games run at the same time.
+	Parallelism int
+	// OnGameEnd, when set, is called after each game with the number of
+	// games finished so far. It may be called from several goroutines.
+	OnGameEnd func(finished int, result Result)
+}
+
+// BatchStats aggregates the results of a batch.
+type BatchStats struct {
+	Games        int           `json:"games"`
+	Draws        int           `json:"draws"`
+	Errors       int           `json:"errors"`
+	AverageTurns float64       `json:"average_turns"`
+	Snakes       []SnakeStats  `json:"snakes"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// SnakeStats aggregates the results of one snake of a batch.
+type SnakeStats struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Wins int    `json:"wins"`
+	// WinRate is the share of games won, with its 95% Wilson score
+	// confidence interval.
+	WinRate       float64 `json:"win_rate"`
+	WinRateLow    float64 `json:"win_rate_low"`
+	WinRateHigh   float64 `json:"win_rate_high"`
+	AverageLength float64 `json:"average_length"`
+	// DeathCauses counts eliminations by cause, survivals are not counted.
+	DeathCauses map[string]int `json:"death_causes"`
+	LatencyP50  time.Duration  `json:"latency_p50"`
+	LatencyP90  time.Duration  `json:"latency_p90"`
+	LatencyP99  time.Duration  `json:"latency_p99"`
+	// Timeouts counts the move requests that took too long.
+	Timeouts int `json:"timeouts"`
+}
+
+// RunBatch plays opts.Games headless games and aggregates their results.
+// Cancelling ctx aborts the running games and starts no new one, the stats
+// only cover the games played to their end.
+func RunBatch(ctx context.Context, opts BatchOptions) (*BatchStats, error) {
+	if opts.Games < 1 {
+		return nil, fmt.Errorf("the number of games must be positive")
+	}
+	if len(opts.Template.URLs) == 0 {
+		return nil, fmt.Errorf("at least one snake URL is required")
+	}
+	parallelism := min(max(opts.Parallelism, 1), opts.Games)
+
+	// name unnamed snakes once, so that they keep their name in every game
+	opts.Template.Names = slices.Clone(opts.Template.Names)
+	for len(opts.Template.Names) < len(opts.Template.URLs) {
+		opts.Template.Names = append(opts.Template.Names, GenerateSnakeName())
+	}
+
+	start := time.Now()
+	var results []Result
+	seeds := make(chan int)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	for range parallelism {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range seeds {
+				result := runBatchGame(ctx, opts.Template, opts.Template.Seed+int64(i))
+				if ctx.Err() != nil {
+					continue
+				}
+
+				lock.Lock()
+				results = append(results, result)
+				done := len(results)
+				lock.Unlock()
+
+				if opts.OnGameEnd != nil {
+					opts.OnGameEnd(done, result)
+				}
+			}
+		}()
+	}
+
+schedule:
+	for i := range opts.Games {
+		select {
+		case seeds <- i:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(seeds)
+	wg.Wait()
+
+	stats := aggregateBatch(results)
+	stats.Duration = time.Since(start)
+	return stats, ctx.Err()
+}
+
+func runBatchGame(ctx context.Context, template GameState, seed int64) Result {
+	gameState := template
+	gameState.Seed = seed
+	gameState.Headless = true
+	gameState.OutputPath = ""
+	gameState.OutputDir = ""
+	// the slices are only read, but do not share them with the template
+	gameState.Names = slices.Clone(template.Names)
+	gameState.URLs = slices.Clone(template.URLs)
+
+	if err := gameState.Initialize(); err != nil {
+		return Result{Err: err}
+	}
+	boardGame := board.Game{ID: gameState.gameID, Status: "running", Width: gameState.Width, Height: gameState.Height}
+	err := gameState.Run(ctx, boardGame, nil)
+
+	result := gameState.Result()
+	result.GameID = gameState.gameID
+	result.Err = err
+	return result
+}
+
+func aggregateBatch(results []Result) *BatchStats {
+	stats := &BatchStats{Games: len(results)}
+
+	var latencies [][]time.Duration
+	var lengths []int
+	var turns int
+	played := 0
+	for _, result := range results {
+		if result.Err != nil {
+			stats.Errors++
+			continue
+		}
+		played++
+		turns += result.Turns
+		if result.IsDraw {
+			stats.Draws++
+		}
+
+		for i, snake := range result.Snakes {
+			if i >= len(stats.Snakes) {
+				stats.Snakes = append(stats.Snakes, SnakeStats{Name: snake.Name, URL: snake.URL, DeathCauses: map[string]int{}})
+				latencies = append(latencies, nil)
+				lengths = append(lengths, 0)
+			}
+			won := snake.EliminatedCause == rules.NotEliminated && !result.IsDraw && len(result.Snakes) > 1
+			if result.WinnerSquad != "" {
+				// the whole squad wins, eliminated snakes included
+				won = snake.Squad == result.WinnerSquad
+			}
+			if won {
+				stats.Snakes[i].Wins++
+			}
+			if snake.EliminatedCause != rules.NotEliminated {
+				stats.Snakes[i].DeathCauses[snake.EliminatedCause]++
+			}
+			lengths[i] += snake.Length
+			stats.Snakes[i].Timeouts += snake.Timeouts
+			latencies[i] = append(latencies[i], snake.Latencies...)
+		}
+	}
+
+	if played > 0 {
+		stats.AverageTurns = float64(turns) / float64(played)
+	}
+	for i := range stats.Snakes {
+		s := &stats.Snakes[i]
+		if played > 0 {
+			s.WinRate = float64(s.Wins) / float64(played)
+			s.AverageLength = float64(lengths[i]) / float64(played)
+		}
+		s.WinRateLow, s.WinRateHigh = wilsonInterval(s.Wins, played)
+
+		slices.Sort(latencies[i])
+		s.LatencyP50 = percentile(latencies[i], 50)
+		s.LatencyP90 = percentile(latencies[i], 90)
+		s.LatencyP99 = percentile(latencies[i], 99)
+	}
+	return stats
+}
+
+// wilsonInterval returns the 95% Wilson score interval of a proportion.
+func wilsonInterval(successes int, trials int) (float64, float64) {
+	if trials == 0 {
+		return 0, 1
+	}
+	const z = 1.96
+	n := float64(trials)
+	p := float64(successes) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / (1 + z*z/n)
+	return max(center-margin, 0), min(center+margin, 1)
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func NewBatchCommand() *cobra.Command {
+	opts := BatchOptions{}
+	var asJSON bool
+
+	var batchCmd = &cobra.Command{
+		Use:   "batch",
+		Short: "Play many headless games and report statistics.",
+		Long:  "Play many headless games between the same snakes, with seeds Seed, Seed+1, ..., and report win rates, game length, death causes and latencies.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the logs of parallel games are unreadable, unless asked for
+			if f := cmd.Flag("log-level"); f == nil || !f.Changed {
+				logging.SetLevel(slog.LevelWarn)
+			}
+
+			opts.OnGameEnd = func(finished int, result Result) {
+				if result.Err != nil {
+					fmt.Fprintf(os.Stderr, "game %d/%d failed: %v\n", finished, opts.Games, result.Err)
+					return
+				}
+				fmt.Fprintf(os.Stderr, "game %d/%d: %d turns\n", finished, opts.Games, result.Turns)
+			}
+
+			stats, err := RunBatch(cmd.Context(), opts)
+			if stats == nil {
+				return err
+			}
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(stats); encErr != nil {
+					return encErr
+				}
+			} else {
+				stats.Print(cmd.OutOrStdout())
+			}
+			return err
+		},
+	}
+
+	batchCmd.Flags().IntVarP(&opts.Games, "games", "N", 100, "Number of games to play")
+	batchCmd.Flags().IntVarP(&opts.Parallelism, "parallel", "p", 4, "Number of games played at the same time")
+	batchCmd.Flags().BoolVar(&asJSON, "json", false, "Print the statistics as JSON")
+
+	gameState := &opts.Template
+	batchCmd.Flags().IntVarP(&gameState.Width, "width", "W", 11, "Width of Board")
+	batchCmd.Flags().IntVarP(&gameState.Height, "height", "H", 11, "Height of Board")
+	batchCmd.Flags().StringArrayVarP(&gameState.Names, "name", "n", nil, "Name of Snake")
+	batchCmd.Flags().StringArrayVarP(&gameState.URLs, "url", "u", nil, "URL of Snake, bot://greedy or stdio:<command>")
+	batchCmd.Flags().IntVarP(&gameState.Timeout, "timeout", "t", 500, "Request Timeout")
+	gameState.TimeoutPolicy = TimeoutRepeatLast
+	batchCmd.Flags().Var(&gameState.TimeoutPolicy, "timeout-policy", "Move of a snake that times out: repeat, random-safe or eliminate")
+	batchCmd.Flags().IntVar(&gameState.MaxTimeouts, "max-timeouts", DefaultMaxTimeouts, "Consecutive timeouts that eliminate a snake with --timeout-policy eliminate")
+	batchCmd.Flags().IntVar(&gameState.TimeBank, "time-bank", 0, "Extra milliseconds each snake may spend over a game on slow moves")
+	gameState.Fog = FogOff
+	batchCmd.Flags().Var(&gameState.Fog, "fog", "Fog of war: off, radius (snakes see only near their bodies) or sight (nor behind bodies and walls)")
+	batchCmd.Flags().IntVar(&gameState.VisionRadius, "vision-radius", DefaultVisionRadius, "How many moves away the snakes see with --fog")
+	batchCmd.Flags().StringArrayVar(&gameState.Squads, "squad", nil, "Squad of Snake, given for every snake to play in squads")
+	batchCmd.Flags().Var(&gameState.SquadRules, "squad-rules", "Rules of the squads: pass-through, shared-health, shared-length and shared-elimination, comma separated")
+	batchCmd.Flags().StringVarP(&gameState.GameType, "gametype", "g", "standard", "Type of Game Rules")
+	batchCmd.Flags().StringVarP(&gameState.MapName, "map", "m", "standard", "Game map to use to populate the board")
+	batchCmd.Flags().Int64VarP(&gameState.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Seed of the first game")
+	batchCmd.Flags().IntVar(&gameState.FoodSpawnChance, "foodSpawnChance", 15, "Percentage chance of spawning a new food every round")
+	batchCmd.Flags().IntVar(&gameState.MinimumFood, "minimumFood", 1, "Minimum food to keep on the board every turn")
+	batchCmd.Flags().IntVar(&gameState.HazardDamagePerTurn, "hazardDamagePerTurn", 14, "Health damage a snake will take when ending its turn in a hazard")
+	batchCmd.Flags().IntVar(&gameState.ShrinkEveryNTurns, "shrinkEveryNTurns", 25, "In Royale mode, the number of turns between generating new hazards")
+
+	batchCmd.Flags().SortFlags = false
+
+	return batchCmd
+}
+
+// Print writes the statistics as a table.
+func (stats *BatchStats) Print(w io.Writer) {
+	fmt.Fprintf(w, "Games: %d, draws: %d, errors: %d, average length: %.1f turns, took %v\n\n",
+		stats.Games, stats.Draws, stats.Errors, stats.AverageTurns, stats.Duration.Round(time.Millisecond))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SNAKE\tWINS\tWIN RATE (95% CI)\tAVG LENGTH\tP50\tP90\tP99\tTIMEOUTS\tDEATHS")
+	for _, s := range stats.Snakes {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%% (%.1f-%.1f%%)\t%.1f\t%v\t%v\t%v\t%d\t%s\n",
+			s.Name, s.Wins, s.WinRate*100, s.WinRateLow*100, s.WinRateHigh*100, s.AverageLength,
+			s.LatencyP50.Round(time.Millisecond), s.LatencyP90.Round(time.Millisecond), s.LatencyP99.Round(time.Millisecond),
+			s.Timeouts, s.DeathSummary())
+	}
+	tw.Flush()
+}
+
+// DeathSummary lists the death causes of the snake, e.g.
+// "wall-collision 3, snake-collision 1".
+func (s SnakeStats) DeathSummary() string {
+	if len(s.DeathCauses) == 0 {
+		return "-"
+	}
+	var causes []string
+	for _, cause := range slices.Sorted(maps.Keys(s.DeathCauses)) {
+		causes = append(causes, fmt.Sprintf("%s %d", cause, s.DeathCauses[cause]))
+	}
+	return strings.Join(causes, ", ")
+}
@@ -0,0 +1,139 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	// z² of the 95% interval
+	const z2 = 1.96 * 1.96
+	tests := []struct {
+		successes, trials int
+		low, high         float64
+	}{
+		// nothing played, nothing known
+		{0, 0, 0, 1},
+		{0, 10, 0, z2 / (10 + z2)},
+		{10, 10, 10 / (10 + z2), 1},
+		{5, 10, 0.2366, 0.7634},
+		{1, 1, 1 / (1 + z2), 1},
+		{0, 1, 0, z2 / (1 + z2)},
+		{1, 2, 0.0945, 0.9055},
+	}
+	for _, test := range tests {
+		low, high := wilsonInterval(test.successes, test.trials)
+		if math.Abs(low-test.low) > 1e-4 || math.Abs(high-test.high) > 1e-4 {
+			t.Errorf("wilsonInterval(%d, %d) = (%.4f, %.4f), want (%.4f, %.4f)", test.successes, test.trials, low, high, test.low, test.high)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var values []time.Duration
+	for i := 1; i <= 10; i++ {
+		values = append(values, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		sorted []time.Duration
+		p      int
+		want   time.Duration
+	}{
+		{nil, 50, 0},
+		{values[:1], 50, time.Millisecond},
+		{values[:1], 99, time.Millisecond},
+		{values, 0, time.Millisecond},
+		{values, 50, 5 * time.Millisecond},
+		{values, 51, 6 * time.Millisecond},
+		{values, 90, 9 * time.Millisecond},
+		{values, 99, 10 * time.Millisecond},
+		{values, 100, 10 * time.Millisecond},
+		// the rank is rounded up
+		{values[:3], 50, 2 * time.Millisecond},
+		{values[:4], 50, 2 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if got := percentile(test.sorted, test.p); got != test.want {
+			t.Errorf("percentile(%v, %d) = %v, want %v", test.sorted, test.p, got, test.want)
+		}
+	}
+}
+
+func TestAggregateBatch(t *testing.T) {
+	ms := time.Millisecond
+	results := []Result{
+		{Turns: 10, Snakes: []SnakeResult{
+			{Name: "a", Length: 5, Latencies: []time.Duration{1 * ms, 2 * ms}},
+			{Name: "b", Length: 3, EliminatedCause: rules.EliminatedByOutOfBounds, Latencies: []time.Duration{3 * ms}, Timeouts: 1},
+		}},
+		{Turns: 20, IsDraw: true, Snakes: []SnakeResult{
+			{Name: "a", Length: 7, EliminatedCause: rules.EliminatedByHeadToHeadCollision},
+			{Name: "b", Length: 7, EliminatedCause: rules.EliminatedByHeadToHeadCollision},
+		}},
+		// an errored game counts as played by no snake
+		{Turns: 99, Err: errors.New("snake unreachable"), Snakes: []SnakeResult{
+			{Name: "a", Length: 99},
+			{Name: "b", Length: 99},
+		}},
+	}
+	stats := aggregateBatch(results)
+	if stats.Games != 3 || stats.Errors != 1 || stats.Draws != 1 || stats.AverageTurns != 15 {
+		t.Errorf("got %d games, %d errors, %d draws and %v turns on average, want 3, 1, 1 and 15", stats.Games, stats.Errors, stats.Draws, stats.AverageTurns)
+	}
+	if len(stats.Snakes) != 2 {
+		t.Fatalf("got stats of %d snakes, want 2", len(stats.Snakes))
+	}
+	a, b := stats.Snakes[0], stats.Snakes[1]
+	low, high := wilsonInterval(1, 2)
+	if a.Name != "a" || a.Wins != 1 || a.WinRate != 0.5 || a.WinRateLow != low || a.WinRateHigh != high || a.AverageLength != 6 {
+		t.Errorf("got %+v, want a won 1 of the 2 games played", a)
+	}
+	if a.LatencyP50 != 1*ms || a.LatencyP99 != 2*ms {
+		t.Errorf("got latencies %v and %v, want 1ms and 2ms", a.LatencyP50, a.LatencyP99)
+	}
+	if b.Wins != 0 || b.WinRate != 0 || b.AverageLength != 5 || b.Timeouts != 1 {
+		t.Errorf("got %+v, want b never won", b)
+	}
+	if b.DeathCauses[rules.EliminatedByOutOfBounds] != 1 || b.DeathCauses[rules.EliminatedByHeadToHeadCollision] != 1 || len(a.DeathCauses) != 1 {
+		t.Errorf("got death causes %v and %v, want those of the games played", a.DeathCauses, b.DeathCauses)
+	}
+}
+
+func TestAggregateBatchSquads(t *testing.T) {
+	results := []Result{
+		// the whole squad wins, the snake eliminated on the way too
+		{Turns: 10, WinnerSquad: "red", Snakes: []SnakeResult{
+			{Name: "a", Squad: "red"},
+			{Name: "b", Squad: "red", EliminatedCause: rules.EliminatedBySelfCollision},
+			{Name: "c", Squad: "blue", EliminatedCause: rules.EliminatedByCollision},
+		}},
+		{Turns: 10, IsDraw: true, Snakes: []SnakeResult{
+			{Name: "a", Squad: "red", EliminatedCause: rules.EliminatedByOutOfHealth},
+			{Name: "b", Squad: "red", EliminatedCause: rules.EliminatedByOutOfHealth},
+			{Name: "c", Squad: "blue", EliminatedCause: rules.EliminatedByOutOfHealth},
+		}},
+	}
+	stats := aggregateBatch(results)
+	for i, want := range []int{1, 1, 0} {
+		if got := stats.Snakes[i].Wins; got != want {
+			t.Errorf("snake %s: got %d wins, want %d", stats.Snakes[i].Name, got, want)
+		}
+	}
+}
+
+func TestAggregateBatchSolo(t *testing.T) {
+	// a snake alone wins nothing by surviving
+	stats := aggregateBatch([]Result{{Turns: 50, Snakes: []SnakeResult{{Name: "a"}}}})
+	if stats.Snakes[0].Wins != 0 {
+		t.Errorf("got %d wins in a solo game, want none", stats.Snakes[0].Wins)
+	}
+	// with no game played the rate is unknown
+	stats = aggregateBatch([]Result{{Err: errors.New("failed"), Snakes: []SnakeResult{{Name: "a"}}}})
+	if s := stats.Snakes; len(s) != 0 {
+		t.Errorf("got stats %+v for the snakes of failed games only, want none", s)
+	}
+}
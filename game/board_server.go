@@ -1,20 +1,30 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/BattlesnakeOfficial/rules/board"
 	"github.com/gorilla/websocket"
-	log "github.com/spf13/jwalterweatherman"
+
+	"github.com/secomp2025/localsnake/logging"
 )
 
-// A minimal server capable of handling the requests from a single browser client running the board viewer.
+// A minimal server capable of handling the requests from the browser clients running the board viewer.
+// Every event is kept, so that viewers joining late still receive the game from its first frame.
 type BoardServer struct {
-	game   board.Game
-	events chan board.GameEvent // channel for sending events from the game runner to the browser client
-	done   chan bool            // channel for signalling (via closing) that all events have been sent to the browser client
+	game board.Game
+
+	lock    sync.Mutex
+	events  []board.GameEvent
+	closed  bool          // set by Shutdown, no more events will be sent
+	updated chan struct{} // closed and replaced whenever events or closed change
+	viewers int
 }
 
 var upgrader = websocket.Upgrader{
@@ -26,9 +36,8 @@ var upgrader = websocket.Upgrader{
 func NewBoardServer(game board.Game) *BoardServer {
 
 	server := &BoardServer{
-		game:   game,
-		events: make(chan board.GameEvent, 1000), // buffered channel to allow game to run ahead of browser client
-		done:   make(chan bool),
+		game:    game,
+		updated: make(chan struct{}),
 	}
 
 	return server
@@ -36,67 +45,183 @@ func NewBoardServer(game board.Game) *BoardServer {
 
 // Handle the /games/:id request made by the board to fetch the game metadata.
 func (server *BoardServer) HandleGame(w http.ResponseWriter, r *http.Request) {
+	ctx := server.logContext(r.Context())
 	w.Header().Add("Content-Type", "application/json")
 	err := json.NewEncoder(w).Encode(struct {
 		Game board.Game
 	}{server.game})
 	if err != nil {
-		log.ERROR.Printf("Unable to serialize game: %v", err)
+		slog.ErrorContext(ctx, "Unable to serialize game", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
 
+// EventTypeControlError is the type of the board events answering a viewer
+// command that failed, its Data is a ControlError. Only the viewer that
+// sent the command receives it.
+const EventTypeControlError board.GameEventType = "control_error"
+
+// ControlError explains why a viewer command failed.
+type ControlError struct {
+	Command string
+	Error   string
+}
+
+// ErrControlForbidden answers the commands of the viewers without control.
+var ErrControlForbidden = errors.New("this viewer may not control the game")
+
+// maxCommandSize bounds the messages read from a viewer.
+const maxCommandSize = 1024
+
 // Handle the /games/:id/events websocket request made by the board to receive game events.
-func (server *BoardServer) HandleWebsocket(w http.ResponseWriter, r *http.Request) {
+// With control set, the viewer may drive the game: each message it sends is a
+// ControlCommand passed to control, the commands failing are answered with a
+// control error event.
+func (server *BoardServer) HandleWebsocket(w http.ResponseWriter, r *http.Request, control func(ControlCommand) error) {
+	ctx := server.logContext(r.Context())
 	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.ERROR.Printf("Unable to upgrade connection: %v", err)
+		slog.ErrorContext(ctx, "Unable to upgrade connection", "err", err)
 		return
 	}
 
 	defer func() {
 		err = ws.Close()
 		if err != nil {
-			log.ERROR.Printf("Unable to close websocket stream")
+			slog.ErrorContext(ctx, "Unable to close websocket stream", "err", err)
 		}
 	}()
 
-	for event := range server.events {
-		jsonStr, err := json.Marshal(event)
-		if err != nil {
-			log.ERROR.Printf("Unable to serialize event for websocket: %v", err)
+	server.lock.Lock()
+	server.viewers++
+	server.lock.Unlock()
+	defer func() {
+		server.lock.Lock()
+		server.viewers--
+		server.lock.Unlock()
+	}()
+
+	// reading notices when the viewer goes away and gets the commands
+	gone := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	replies := make(chan board.GameEvent)
+	ws.SetReadLimit(maxCommandSize)
+	go func() {
+		defer close(gone)
+		for {
+			_, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd ControlCommand
+			if err = json.Unmarshal(data, &cmd); err == nil {
+				if control == nil {
+					err = ErrControlForbidden
+				} else {
+					err = control(cmd)
+				}
+			}
+			if err == nil {
+				continue
+			}
+			slog.InfoContext(ctx, "Viewer command failed", "command", cmd.Command, "err", err)
+			select {
+			case replies <- board.GameEvent{EventType: EventTypeControlError, Data: ControlError{Command: cmd.Command, Error: err.Error()}}:
+			case <-done:
+				return
+			}
 		}
+	}()
 
-		err = ws.WriteMessage(websocket.TextMessage, jsonStr)
-		if err != nil {
-			log.ERROR.Printf("Unable to write to websocket: %v", err)
+	sent := 0
+	for {
+		server.lock.Lock()
+		events := server.events[sent:]
+		closed := server.closed
+		updated := server.updated
+		server.lock.Unlock()
+
+		for _, event := range events {
+			jsonStr, err := json.Marshal(event)
+			if err != nil {
+				slog.ErrorContext(ctx, "Unable to serialize event for websocket", "err", err)
+			}
+
+			err = ws.WriteMessage(websocket.TextMessage, jsonStr)
+			if err != nil {
+				slog.ErrorContext(ctx, "Unable to write to websocket", "err", err)
+				return
+			}
+		}
+		sent += len(events)
+
+		if closed && len(events) == 0 {
 			break
 		}
+		if len(events) > 0 {
+			continue
+		}
+		select {
+		case <-updated:
+		case reply := <-replies:
+			if err := ws.WriteJSON(reply); err != nil {
+				slog.ErrorContext(ctx, "Unable to write to websocket", "err", err)
+				return
+			}
+		case <-gone:
+			return
+		}
 	}
 
-	log.DEBUG.Printf("Finished writing all game events, signalling game server to stop")
-	close(server.done)
-
-	log.DEBUG.Printf("Sending websocket close message")
+	slog.DebugContext(ctx, "Finished writing all game events, sending websocket close message")
 	err = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 	if err != nil {
-		log.ERROR.Printf("Problem closing websocket: %v", err)
+		slog.ErrorContext(ctx, "Problem closing websocket", "err", err)
 	}
 }
 
+// Shutdown marks the end of the events and waits for the connected viewers to receive them.
 func (server *BoardServer) Shutdown() {
-	close(server.events)
+	server.lock.Lock()
+	server.closed = true
+	server.notify()
+	server.lock.Unlock()
 
 	// wait for at max 10 seconds to allow clients to finish
-	select {
-	case <-server.done:
-		log.DEBUG.Printf("Server is done, exiting")
-	case <-time.After(10 * time.Second):
-		log.DEBUG.Printf("Server timed out, exiting")
+	deadline := time.Now().Add(10 * time.Second)
+	for server.Viewers() > 0 {
+		if time.Now().After(deadline) {
+			slog.Debug("Board server timed out, exiting", "game_id", server.game.ID)
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
 	}
+	slog.Debug("Board server is done, exiting", "game_id", server.game.ID)
+}
+
+// logContext adds the game ID to the log records of a viewer request.
+func (server *BoardServer) logContext(ctx context.Context) context.Context {
+	return logging.With(ctx, "game_id", server.game.ID)
 }
 
 func (server *BoardServer) SendEvent(event board.GameEvent) {
-	server.events <- event
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	server.events = append(server.events, event)
+	server.notify()
+}
+
+// Viewers returns the number of connected websocket viewers.
+func (server *BoardServer) Viewers() int {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	return server.viewers
+}
+
+// notify wakes up the viewers waiting for events. The lock must be held.
+func (server *BoardServer) notify() {
+	close(server.updated)
+	server.updated = make(chan struct{})
 }
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"context"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// BotURLScheme is the scheme of the URLs of the snakes played by the engine
+// itself, see BotURL.
+const BotURLScheme = "bot"
+
+// BotURL is the URL of the built-in snake: it heads for the closest food
+// without running into walls or bodies.
+const BotURL = BotURLScheme + "://greedy"
+
+// bots are the built-in snakes, by the host of their URL.
+var bots = map[string]MoveProvider{
+	"greedy": FuncProvider{
+		Metadata: client.SnakeMetadataResponse{
+			APIVersion: "1",
+			Author:     "localsnake",
+			Color:      "#6b7280",
+			Head:       "default",
+			Tail:       "default",
+		},
+		MoveFunc: func(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, error) {
+			return client.MoveResponse{Move: greedyMove(request)}, nil
+		},
+	},
+}
+
+// greedyMove picks the safe move closest to a food, the forward move when
+// none is safe.
+func greedyMove(request client.SnakeRequest) string {
+	occupied := map[client.Coord]bool{}
+	for _, snake := range request.Board.Snakes {
+		for _, p := range snake.Body {
+			occupied[p] = true
+		}
+	}
+
+	head := request.You.Head
+	best, bestDistance := forwardMove(request.You.Body), -1
+	for _, m := range []struct {
+		name string
+		to   client.Coord
+	}{
+		{rules.MoveUp, client.Coord{X: head.X, Y: head.Y + 1}},
+		{rules.MoveDown, client.Coord{X: head.X, Y: head.Y - 1}},
+		{rules.MoveLeft, client.Coord{X: head.X - 1, Y: head.Y}},
+		{rules.MoveRight, client.Coord{X: head.X + 1, Y: head.Y}},
+	} {
+		if m.to.X < 0 || m.to.Y < 0 || m.to.X >= request.Board.Width || m.to.Y >= request.Board.Height || occupied[m.to] {
+			continue
+		}
+		// without food in sight, any safe move will do
+		distance := request.Board.Width + request.Board.Height
+		for _, food := range request.Board.Food {
+			distance = min(distance, abs(food.X-m.to.X)+abs(food.Y-m.to.Y))
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = m.name, distance
+		}
+	}
+	return best
+}
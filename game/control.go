@@ -0,0 +1,294 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/board"
+)
+
+// AbortError is the cancellation cause of a game stopped before its end.
+type AbortError struct {
+	Reason string
+}
+
+func (e *AbortError) Error() string {
+	return "game aborted: " + e.Reason
+}
+
+// Abort returns the cause to cancel the context of a running game with, see
+// context.WithCancelCause.
+func Abort(reason string) error {
+	return &AbortError{Reason: reason}
+}
+
+// AbortReason returns the reason of an aborted game, or false when err does
+// not come from Abort.
+func AbortReason(err error) (string, bool) {
+	var abortErr *AbortError
+	if errors.As(err, &abortErr) {
+		return abortErr.Reason, true
+	}
+	return "", false
+}
+
+// Commands of ControlCommand.
+const (
+	ControlPause  = "pause"
+	ControlResume = "resume"
+	ControlStep   = "step"
+	ControlDelay  = "delay"
+)
+
+// MaxTurnDelay bounds the turn delay, in milliseconds, set on a running game.
+const MaxTurnDelay = 10000
+
+// EventTypeControl is the type of the board events telling the viewers the
+// controls of the game changed, its Data is a ControlStatus.
+const EventTypeControl board.GameEventType = "control"
+
+var (
+	// ErrNotPaused is returned when stepping a game that is not paused.
+	ErrNotPaused = errors.New("the game is not paused")
+	// ErrBadTurnDelay is returned for turn delays out of [0, MaxTurnDelay].
+	ErrBadTurnDelay = fmt.Errorf("the turn delay must be between 0 and %d ms", MaxTurnDelay)
+)
+
+// ControlCommand drives a running game, see GameState.Control.
+type ControlCommand struct {
+	// Command is one of ControlPause, ControlResume, ControlStep and
+	// ControlDelay.
+	Command string `json:"command"`
+	// DelayMS is the new turn delay of ControlDelay, in milliseconds.
+	DelayMS int `json:"delay_ms,omitempty"`
+	// Move is the direction of ControlMove.
+	Move string `json:"move,omitempty"`
+}
+
+// ControlStatus is the state of the controls of a running game.
+type ControlStatus struct {
+	Paused bool
+	// TurnDelay is the time waited after each turn, in milliseconds.
+	TurnDelay int
+}
+
+// pauseGate holds the game between two turns while it is paused and paces
+// the turns with the turn delay.
+type pauseGate struct {
+	lock    sync.Mutex
+	resumed chan struct{} // closed on Resume and Step, nil while the game is not paused
+	steps   int           // turns to play before holding the paused game again
+	delay   time.Duration
+	changed chan struct{} // closed and replaced when delay changes
+}
+
+// Pause stops the game before its next turn. The turn being played, if
+// any, finishes first.
+func (gameState *GameState) Pause() {
+	gameState.pause.lock.Lock()
+	defer gameState.pause.lock.Unlock()
+	if gameState.pause.resumed == nil {
+		gameState.pause.resumed = make(chan struct{})
+	}
+}
+
+// Resume continues a paused game.
+func (gameState *GameState) Resume() {
+	gameState.pause.lock.Lock()
+	defer gameState.pause.lock.Unlock()
+	if gameState.pause.resumed != nil {
+		close(gameState.pause.resumed)
+		gameState.pause.resumed = nil
+		gameState.pause.steps = 0
+	}
+}
+
+// Step plays one turn of a paused game, which is held again before the
+// turn after. Steps made while the turn is played add up.
+func (gameState *GameState) Step() error {
+	gameState.pause.lock.Lock()
+	defer gameState.pause.lock.Unlock()
+	if gameState.pause.resumed == nil {
+		return ErrNotPaused
+	}
+	gameState.pause.steps++
+	close(gameState.pause.resumed)
+	gameState.pause.resumed = make(chan struct{})
+	return nil
+}
+
+// Paused reports whether the game is paused.
+func (gameState *GameState) Paused() bool {
+	gameState.pause.lock.Lock()
+	defer gameState.pause.lock.Unlock()
+	return gameState.pause.resumed != nil
+}
+
+// SetTurnDelay changes the time waited after each turn, in milliseconds.
+// The turn waiting for its delay, if any, follows the new delay.
+func (gameState *GameState) SetTurnDelay(delay int) error {
+	if delay < 0 || delay > MaxTurnDelay {
+		return ErrBadTurnDelay
+	}
+	gameState.pause.lock.Lock()
+	defer gameState.pause.lock.Unlock()
+	gameState.pause.delay = time.Duration(delay) * time.Millisecond
+	close(gameState.pause.changed)
+	gameState.pause.changed = make(chan struct{})
+	return nil
+}
+
+// CurrentTurnDelay returns the time waited after each turn, in
+// milliseconds. It starts at TurnDelay and follows SetTurnDelay.
+func (gameState *GameState) CurrentTurnDelay() int {
+	gameState.pause.lock.Lock()
+	defer gameState.pause.lock.Unlock()
+	return int(gameState.pause.delay / time.Millisecond)
+}
+
+// Control applies a command sent by a viewer or through the API.
+func (gameState *GameState) Control(cmd ControlCommand) error {
+	switch cmd.Command {
+	case ControlPause:
+		gameState.Pause()
+	case ControlResume:
+		gameState.Resume()
+	case ControlStep:
+		return gameState.Step()
+	case ControlDelay:
+		return gameState.SetTurnDelay(cmd.DelayMS)
+	default:
+		return fmt.Errorf("unknown command %q", cmd.Command)
+	}
+	return nil
+}
+
+// ControlStatus returns the state of the controls of the game.
+func (gameState *GameState) ControlStatus() ControlStatus {
+	return ControlStatus{Paused: gameState.Paused(), TurnDelay: gameState.CurrentTurnDelay()}
+}
+
+// ControlEvent tells the viewers the state of the controls of the game.
+func (gameState *GameState) ControlEvent() board.GameEvent {
+	return board.GameEvent{EventType: EventTypeControl, Data: gameState.ControlStatus()}
+}
+
+// waitWhilePaused blocks while the game is paused, unless a step lets the
+// next turn through, and returns the cause of ctx once it is done.
+func (gameState *GameState) waitWhilePaused(ctx context.Context) error {
+	waited := false
+	for {
+		gameState.pause.lock.Lock()
+		resumed := gameState.pause.resumed
+		stepped := resumed != nil && gameState.pause.steps > 0
+		if stepped {
+			gameState.pause.steps--
+		}
+		gameState.pause.lock.Unlock()
+
+		if stepped {
+			slog.InfoContext(ctx, "Game stepped")
+			break
+		}
+		if resumed == nil {
+			if waited {
+				slog.InfoContext(ctx, "Game resumed")
+			}
+			break
+		}
+		if !waited {
+			slog.InfoContext(ctx, "Game paused")
+			waited = true
+		}
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+// waitTurnDelay waits for the turn delay after a turn, or until ctx is
+// done. A delay changed meanwhile counts from the same start.
+func (gameState *GameState) waitTurnDelay(ctx context.Context) {
+	start := time.Now()
+	for {
+		gameState.pause.lock.Lock()
+		delay := gameState.pause.delay
+		changed := gameState.pause.changed
+		gameState.pause.lock.Unlock()
+
+		d := time.Until(start.Add(delay))
+		if d <= 0 {
+			return
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+			return
+		case <-changed:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// progress is the state of a running game that other goroutines may read.
+type progress struct {
+	started atomic.Bool
+	turn    atomic.Int64
+	alive   atomic.Int64
+}
+
+func (p *progress) update(boardState *rules.BoardState) {
+	alive := 0
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			alive++
+		}
+	}
+	p.turn.Store(int64(boardState.Turn))
+	p.alive.Store(int64(alive))
+	p.started.Store(true)
+}
+
+// Started reports whether the snakes answered /start and the board is set
+// up.
+func (gameState *GameState) Started() bool {
+	return gameState.progress.started.Load()
+}
+
+// Turn returns the last turn played.
+func (gameState *GameState) Turn() int {
+	return int(gameState.progress.turn.Load())
+}
+
+// Alive returns the number of snakes not eliminated yet.
+func (gameState *GameState) Alive() int {
+	return int(gameState.progress.alive.Load())
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/board"
+)
+
+// waitForTurn waits for the game to play the given turn.
+func waitForTurn(t *testing.T, gameState *GameState, turn int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for gameState.Turn() < turn {
+		if time.Now().After(deadline) {
+			t.Fatalf("the game is still on turn %d, want turn %d", gameState.Turn(), turn)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestControlGame(t *testing.T) {
+	gameState := &GameState{
+		Width:           11,
+		Height:          11,
+		Names:           []string{"one", "two"},
+		URLs:            []string{"http://one", "http://two"},
+		Timeout:         500,
+		GameType:        "standard",
+		MapName:         "standard",
+		Seed:            42,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+		Headless:        true,
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	gameState.httpClient = greedySnakeClient{}
+
+	if err := gameState.Step(); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("stepping a running game: got %v, want ErrNotPaused", err)
+	}
+	if err := gameState.Control(ControlCommand{Command: ControlDelay, DelayMS: MaxTurnDelay + 1}); !errors.Is(err, ErrBadTurnDelay) {
+		t.Errorf("setting a turn delay too long: got %v, want ErrBadTurnDelay", err)
+	}
+	if err := gameState.Control(ControlCommand{Command: "rewind"}); err == nil {
+		t.Error("an unknown command was accepted")
+	}
+
+	gameState.Pause()
+	done := make(chan error)
+	go func() {
+		done <- gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil)
+	}()
+
+	// each step plays one turn, the steps made together add up
+	if err := gameState.Step(); err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	waitForTurn(t, gameState, 1)
+	gameState.Step()
+	gameState.Step()
+	waitForTurn(t, gameState, 3)
+	time.Sleep(50 * time.Millisecond)
+	if turn := gameState.Turn(); turn != 3 || !gameState.Paused() {
+		t.Fatalf("got turn %d and paused %v after three steps, want the game held on turn 3", turn, gameState.Paused())
+	}
+
+	// a shorter delay ends the wait of the turn under way
+	gameState.SetTurnDelay(MaxTurnDelay)
+	gameState.Resume()
+	waitForTurn(t, gameState, 4)
+	if got := gameState.ControlStatus(); got != (ControlStatus{TurnDelay: MaxTurnDelay}) {
+		t.Errorf("got status %+v, want the game running with the longest delay", got)
+	}
+	gameState.SetTurnDelay(0)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the game still waits for the longest turn delay")
+	}
+}
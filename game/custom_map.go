@@ -0,0 +1,600 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"gopkg.in/yaml.v3"
+)
+
+// EliminatedByWall is the elimination cause of snakes that run into a wall
+// of a custom map. The rules call leaving the board "wall-collision".
+const EliminatedByWall = "map-wall"
+
+// Limits of the boards of custom maps.
+const (
+	MinCustomMapSize = 3
+	MaxCustomMapSize = 25
+)
+
+// MapPoint is a cell of a custom map, (0, 0) is the bottom left corner like
+// in the snake requests.
+type MapPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// MapRegion is a rectangle of cells of a custom map, starting at (X, Y) and
+// growing up and to the right. A missing Width or Height counts as 1, so
+// {x: 3, y: 4} is a single cell.
+type MapRegion struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+}
+
+func (r MapRegion) points() []rules.Point {
+	var points []rules.Point
+	for x := r.X; x < r.X+max(r.Width, 1); x++ {
+		for y := r.Y; y < r.Y+max(r.Height, 1); y++ {
+			points = append(points, rules.Point{X: x, Y: y})
+		}
+	}
+	return points
+}
+
+// HazardWindow puts hazards on Cells from turn From until, not including,
+// turn Until. An Until of zero keeps them until the end of the game.
+type HazardWindow struct {
+	From  int         `json:"from"`
+	Until int         `json:"until,omitempty"`
+	Cells []MapRegion `json:"cells"`
+}
+
+func (w HazardWindow) activeOn(turn int) bool {
+	return turn >= w.From && (w.Until == 0 || turn < w.Until)
+}
+
+// CustomMap is a map defined in a JSON or YAML file instead of in Go, see
+// ParseCustomMap. Walls are sent to the snakes as hazards, a snake that moves
+// into one is eliminated with EliminatedByWall.
+type CustomMap struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Description string `json:"description,omitempty"`
+	Version     int    `json:"version,omitempty"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// MinPlayers defaults to 1 and MaxPlayers to the number of start
+	// positions.
+	MinPlayers int `json:"min_players,omitempty"`
+	MaxPlayers int `json:"max_players,omitempty"`
+	// StartPositions are the cells the snakes start on, shuffled with the
+	// seed of the game.
+	StartPositions []MapPoint  `json:"start_positions"`
+	Walls          []MapRegion `json:"walls,omitempty"`
+	Hazards        []MapRegion `json:"hazards,omitempty"`
+	// Food is placed when the game starts. Without it the game starts with
+	// one food per snake, placed at random in FoodZones.
+	Food []MapPoint `json:"food,omitempty"`
+	// FoodZones limit where food spawns during the game, the whole board
+	// when empty. Spawning follows the minimum food and food spawn chance
+	// settings of the game, like on the standard map.
+	FoodZones      []MapRegion    `json:"food_zones,omitempty"`
+	HazardSchedule []HazardWindow `json:"hazard_schedule,omitempty"`
+}
+
+var customMapIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// IsCustomMapFile reports whether name has the extension of a map file:
+// .json, .yaml or .yml.
+func IsCustomMapFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ParseCustomMap decodes and validates the map in data. name is the name of
+// the file the map comes from: its extension chooses between JSON and YAML
+// and it gives the map its ID when the map has none.
+func ParseCustomMap(name string, data []byte) (CustomMap, error) {
+	var def CustomMap
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".yaml" || ext == ".yml" {
+		// YAML goes through JSON so that both use the same field names
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return def, fmt.Errorf("invalid YAML: %w", err)
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return def, fmt.Errorf("invalid YAML: %w", err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return def, fmt.Errorf("invalid map: %w", err)
+	}
+
+	if def.ID == "" {
+		def.ID = strings.ToLower(strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)))
+	}
+	if def.Name == "" {
+		def.Name = def.ID
+	}
+	if def.MinPlayers == 0 {
+		def.MinPlayers = 1
+	}
+	if def.MaxPlayers == 0 {
+		def.MaxPlayers = len(def.StartPositions)
+	}
+	if err := def.validate(); err != nil {
+		return def, err
+	}
+	return def, nil
+}
+
+// validate returns every problem of the map at once, so that authors can fix
+// them in one go.
+func (def CustomMap) validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !customMapIDPattern.MatchString(def.ID) {
+		fail("id %q must have 1 to 40 lowercase letters, digits, - or _", def.ID)
+	} else if isBuiltinMap(def.ID) {
+		fail("id %q is taken by a built-in map", def.ID)
+	}
+	if def.Width < MinCustomMapSize || def.Width > MaxCustomMapSize || def.Height < MinCustomMapSize || def.Height > MaxCustomMapSize {
+		fail("board size %dx%d is not between %dx%d and %dx%d", def.Width, def.Height, MinCustomMapSize, MinCustomMapSize, MaxCustomMapSize, MaxCustomMapSize)
+		return errors.Join(errs...)
+	}
+	onBoard := func(p rules.Point) bool {
+		return p.X >= 0 && p.X < def.Width && p.Y >= 0 && p.Y < def.Height
+	}
+	checkRegions := func(field string, regions []MapRegion) {
+		for i, r := range regions {
+			if r.Width < 0 || r.Height < 0 {
+				fail("%s[%d] has a negative size", field, i)
+				continue
+			}
+			corner := rules.Point{X: r.X + max(r.Width, 1) - 1, Y: r.Y + max(r.Height, 1) - 1}
+			if !onBoard(rules.Point{X: r.X, Y: r.Y}) || !onBoard(corner) {
+				fail("%s[%d] is not inside the board", field, i)
+			}
+		}
+	}
+	checkRegions("walls", def.Walls)
+	checkRegions("hazards", def.Hazards)
+	checkRegions("food_zones", def.FoodZones)
+
+	walls := def.wallSet()
+	if len(def.StartPositions) == 0 {
+		fail("the map needs start positions")
+	}
+	seen := map[rules.Point]bool{}
+	for i, s := range def.StartPositions {
+		p := rules.Point{X: s.X, Y: s.Y}
+		switch {
+		case !onBoard(p):
+			fail("start_positions[%d] is not inside the board", i)
+		case walls[p]:
+			fail("start_positions[%d] is on a wall", i)
+		case seen[p]:
+			fail("start_positions[%d] is repeated", i)
+		}
+		seen[p] = true
+	}
+	for i, f := range def.Food {
+		p := rules.Point{X: f.X, Y: f.Y}
+		if !onBoard(p) || walls[p] {
+			fail("food[%d] is not on a free cell of the board", i)
+		}
+	}
+	if def.MinPlayers < 1 || def.MinPlayers > def.MaxPlayers || def.MaxPlayers > len(def.StartPositions) {
+		fail("players must be between 1 and the %d start positions, got %d to %d", len(def.StartPositions), def.MinPlayers, def.MaxPlayers)
+	}
+	for i, w := range def.HazardSchedule {
+		if w.From < 0 || (w.Until != 0 && w.Until <= w.From) {
+			fail("hazard_schedule[%d] must have 0 <= from < until", i)
+		}
+		checkRegions(fmt.Sprintf("hazard_schedule[%d].cells", i), w.Cells)
+	}
+	return errors.Join(errs...)
+}
+
+func (def CustomMap) wallSet() map[rules.Point]bool {
+	walls := map[rules.Point]bool{}
+	for _, r := range def.Walls {
+		for _, p := range r.points() {
+			walls[p] = true
+		}
+	}
+	return walls
+}
+
+// The rules keep the maps in a plain map that cannot forget or replace a map.
+// A custom map is registered there once, as a customMapRef that looks up the
+// current definition, and customMapsMu guards the registry from uploads
+// while games look maps up with getMap.
+var (
+	customMapsMu sync.RWMutex
+	customMaps   = map[string]CustomMap{}
+)
+
+// RegisterCustomMap makes def available to maps.GetMap and to the games,
+// replacing the earlier version of a custom map with the same ID. Running
+// games keep the version they started with.
+func RegisterCustomMap(def CustomMap) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+	customMapsMu.Lock()
+	defer customMapsMu.Unlock()
+	if _, ok := customMaps[def.ID]; !ok {
+		maps.RegisterMap(def.ID, customMapRef{def.ID})
+	}
+	customMaps[def.ID] = def
+	return nil
+}
+
+// CustomMaps returns the registered custom maps sorted by ID.
+func CustomMaps() []CustomMap {
+	customMapsMu.RLock()
+	defer customMapsMu.RUnlock()
+	defs := make([]CustomMap, 0, len(customMaps))
+	for _, def := range customMaps {
+		defs = append(defs, def)
+	}
+	slices.SortFunc(defs, func(a, b CustomMap) int { return strings.Compare(a.ID, b.ID) })
+	return defs
+}
+
+func isCustomMap(id string) bool {
+	customMapsMu.RLock()
+	defer customMapsMu.RUnlock()
+	_, ok := customMaps[id]
+	return ok
+}
+
+func isBuiltinMap(id string) bool {
+	customMapsMu.RLock()
+	defer customMapsMu.RUnlock()
+	if _, ok := customMaps[id]; ok {
+		return false
+	}
+	_, err := maps.GetMap(id)
+	return err == nil
+}
+
+// getMap looks a map up in the registry of the rules. A custom map comes
+// with the definition it has now, later uploads do not change it.
+func getMap(id string) (maps.GameMap, error) {
+	customMapsMu.RLock()
+	defer customMapsMu.RUnlock()
+	if def, ok := customMaps[id]; ok {
+		return newCustomGameMap(def), nil
+	}
+	return maps.GetMap(id)
+}
+
+// CheckMap returns an error when there is no map called name or when it
+// cannot host the given number of players.
+func CheckMap(name string, players int) error {
+	gameMap, err := getMap(name)
+	if err != nil {
+		return fmt.Errorf("unknown map %q", name)
+	}
+	meta := gameMap.Meta()
+	if (meta.MinPlayers != 0 && players < meta.MinPlayers) || (meta.MaxPlayers != 0 && players > meta.MaxPlayers) {
+		return fmt.Errorf("map %q is played by %d to %d snakes", name, meta.MinPlayers, meta.MaxPlayers)
+	}
+	return nil
+}
+
+// MapInfo describes a map that games can be played on.
+type MapInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Author      string `json:"author,omitempty"`
+	Description string `json:"description,omitempty"`
+	MinPlayers  int    `json:"min_players,omitempty"`
+	MaxPlayers  int    `json:"max_players,omitempty"`
+	// Sizes lists the allowed board sizes, like 11x11, empty when any size
+	// goes.
+	Sizes  []string `json:"sizes,omitempty"`
+	Custom bool     `json:"custom"`
+}
+
+// ListMaps describes the built-in and the custom maps, sorted by ID.
+func ListMaps() []MapInfo {
+	customMapsMu.RLock()
+	ids := maps.List()
+	customMapsMu.RUnlock()
+
+	infos := make([]MapInfo, 0, len(ids))
+	for _, id := range ids {
+		gameMap, err := getMap(id)
+		if err != nil {
+			continue
+		}
+		meta := gameMap.Meta()
+		info := MapInfo{
+			ID:          id,
+			Name:        meta.Name,
+			Author:      meta.Author,
+			Description: meta.Description,
+			MinPlayers:  meta.MinPlayers,
+			MaxPlayers:  meta.MaxPlayers,
+			Custom:      isCustomMap(id),
+		}
+		if !meta.BoardSizes.IsUnlimited() {
+			for _, size := range meta.BoardSizes {
+				info.Sizes = append(info.Sizes, fmt.Sprintf("%dx%d", size.Width, size.Height))
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// LoadCustomMaps registers the map files of dir. A missing dir has no maps.
+// Invalid files are reported in the returned error, the other maps are
+// registered all the same.
+func LoadCustomMaps(dir string) ([]CustomMap, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []CustomMap
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !IsCustomMapFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			var def CustomMap
+			if def, err = ParseCustomMap(entry.Name(), data); err == nil {
+				err = RegisterCustomMap(def)
+				loaded = append(loaded, def)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+		}
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// SaveCustomMap validates the map file called name, stores it in dir under
+// the ID of the map and registers it.
+func SaveCustomMap(dir string, name string, data []byte) (CustomMap, error) {
+	if !IsCustomMapFile(name) {
+		return CustomMap{}, fmt.Errorf("map files end in .json, .yaml or .yml, got %q", name)
+	}
+	def, err := ParseCustomMap(name, data)
+	if err != nil {
+		return def, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return def, err
+	}
+	// the file is named after the ID, so an upload replaces the earlier
+	// version of the map and cannot write outside dir
+	path := filepath.Join(dir, def.ID+strings.ToLower(filepath.Ext(name)))
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return def, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return def, err
+	}
+	if err := tmp.Close(); err != nil {
+		return def, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return def, err
+	}
+	// a file of another format may hold the earlier version
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		if other := filepath.Join(dir, def.ID+ext); other != path {
+			if err := os.Remove(other); err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.Warn("Error removing old map file", "path", other, "err", err)
+			}
+		}
+	}
+	return def, RegisterCustomMap(def)
+}
+
+// customMapRef is what the registry of the rules knows of a custom map, it
+// plays the current definition.
+type customMapRef struct {
+	id string
+}
+
+func (m customMapRef) current() customGameMap {
+	customMapsMu.RLock()
+	defer customMapsMu.RUnlock()
+	return newCustomGameMap(customMaps[m.id])
+}
+
+func (m customMapRef) ID() string {
+	return m.id
+}
+
+func (m customMapRef) Meta() maps.Metadata {
+	return m.current().Meta()
+}
+
+func (m customMapRef) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return m.current().SetupBoard(initialBoardState, settings, editor)
+}
+
+func (m customMapRef) PreUpdateBoard(previousBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return m.current().PreUpdateBoard(previousBoardState, settings, editor)
+}
+
+func (m customMapRef) PostUpdateBoard(previousBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return m.current().PostUpdateBoard(previousBoardState, settings, editor)
+}
+
+// customGameMap plays a CustomMap.
+type customGameMap struct {
+	def   CustomMap
+	walls map[rules.Point]bool
+	// foodCells are the cells food spawns on
+	foodCells []rules.Point
+}
+
+func newCustomGameMap(def CustomMap) customGameMap {
+	m := customGameMap{def: def, walls: def.wallSet()}
+	zones := def.FoodZones
+	if len(zones) == 0 {
+		zones = []MapRegion{{Width: def.Width, Height: def.Height}}
+	}
+	seen := map[rules.Point]bool{}
+	for _, r := range zones {
+		for _, p := range r.points() {
+			if !m.walls[p] && !seen[p] {
+				seen[p] = true
+				m.foodCells = append(m.foodCells, p)
+			}
+		}
+	}
+	return m
+}
+
+func (m customGameMap) ID() string {
+	return m.def.ID
+}
+
+func (m customGameMap) Meta() maps.Metadata {
+	return maps.Metadata{
+		Name:        m.def.Name,
+		Author:      m.def.Author,
+		Description: m.def.Description,
+		Version:     m.def.Version,
+		MinPlayers:  m.def.MinPlayers,
+		MaxPlayers:  m.def.MaxPlayers,
+		BoardSizes:  maps.FixedSizes(maps.Dimensions{Width: m.def.Width, Height: m.def.Height}),
+		Tags:        []string{"custom"},
+	}
+}
+
+func (m customGameMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	rand := settings.GetRand(0)
+
+	heads := make([]rules.Point, len(m.def.StartPositions))
+	for i, s := range m.def.StartPositions {
+		heads[i] = rules.Point{X: s.X, Y: s.Y}
+	}
+	if err := editor.PlaceSnakesRandomlyAtPositions(rand, initialBoardState.Snakes, heads, rules.SnakeStartSize); err != nil {
+		return err
+	}
+
+	m.placeHazards(editor, 0)
+
+	for _, f := range m.def.Food {
+		editor.AddFood(rules.Point{X: f.X, Y: f.Y})
+	}
+	if len(m.def.Food) == 0 {
+		m.spawnFood(rand, editor, len(initialBoardState.Snakes))
+	}
+	return nil
+}
+
+func (m customGameMap) PreUpdateBoard(previousBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return nil
+}
+
+func (m customGameMap) PostUpdateBoard(previousBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	// the board is sent to the snakes as the next turn
+	m.placeHazards(editor, previousBoardState.Turn+1)
+
+	rand := settings.GetRand(previousBoardState.Turn)
+	minFood := settings.Int(rules.ParamMinimumFood, 0)
+	foodSpawnChance := settings.Int(rules.ParamFoodSpawnChance, 0)
+	switch {
+	case len(previousBoardState.Food) < minFood:
+		m.spawnFood(rand, editor, minFood-len(previousBoardState.Food))
+	case foodSpawnChance > 0 && (100-rand.Intn(100)) < foodSpawnChance:
+		m.spawnFood(rand, editor, 1)
+	}
+	return nil
+}
+
+// placeHazards puts the walls and the hazards of the given turn on the
+// board. A cell takes hazard damage once, even when regions overlap.
+func (m customGameMap) placeHazards(editor maps.Editor, turn int) {
+	editor.ClearHazards()
+	seen := map[rules.Point]bool{}
+	add := func(regions []MapRegion) {
+		for _, r := range regions {
+			for _, p := range r.points() {
+				if !seen[p] {
+					seen[p] = true
+					editor.AddHazard(p)
+				}
+			}
+		}
+	}
+	add(m.def.Walls)
+	add(m.def.Hazards)
+	for _, w := range m.def.HazardSchedule {
+		if w.activeOn(turn) {
+			add(w.Cells)
+		}
+	}
+}
+
+// spawnFood places n food on free food cells, fewer when the cells run out.
+func (m customGameMap) spawnFood(rand rules.Rand, editor maps.Editor, n int) {
+	free := editor.FilterUnoccupiedPoints(m.foodCells, true, false, true)
+	editor.ShufflePoints(rand, free)
+	for _, p := range free[:min(n, len(free))] {
+		editor.AddFood(p)
+	}
+}
+
+// eliminateWallCollisions eliminates the snakes whose heads are on a wall.
+func (m customGameMap) eliminateWallCollisions(boardState *rules.BoardState) []string {
+	var eliminated []string
+	for i := range boardState.Snakes {
+		snake := &boardState.Snakes[i]
+		if snake.EliminatedCause != rules.NotEliminated || len(snake.Body) == 0 || !m.walls[snake.Body[0]] {
+			continue
+		}
+		rules.EliminateSnake(snake, EliminatedByWall, "", boardState.Turn+1)
+		eliminated = append(eliminated, snake.ID)
+	}
+	return eliminated
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/maps"
+)
+
+const arenaYAML = `
+name: Arena
+width: 5
+height: 5
+start_positions:
+  - {x: 1, y: 1}
+  - {x: 3, y: 0}
+walls:
+  - {x: 1, y: 3}
+hazard_schedule:
+  - from: 2
+    until: 4
+    cells:
+      - {x: 0, y: 0, width: 5}
+`
+
+func TestParseCustomMap(t *testing.T) {
+	def, err := ParseCustomMap("test-arena.yaml", []byte(arenaYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.ID != "test-arena" || def.MinPlayers != 1 || def.MaxPlayers != 2 {
+		t.Errorf("got id %q with %d to %d players, want test-arena with 1 to 2", def.ID, def.MinPlayers, def.MaxPlayers)
+	}
+
+	_, err = ParseCustomMap("standard.json", []byte(`{"width": 30, "height": 5, "start_positions": [{"x": 0, "y": 0}]}`))
+	if err == nil || !strings.Contains(err.Error(), "built-in") || !strings.Contains(err.Error(), "board size") {
+		t.Errorf("got %v, want the built-in ID and the board size reported", err)
+	}
+	_, err = ParseCustomMap("walled.json", []byte(`{"width": 5, "height": 5, "start_positions": [{"x": 0, "y": 0}], "walls": [{"x": 0, "y": 0, "width": 6}]}`))
+	if err == nil || !strings.Contains(err.Error(), "walls[0] is not inside") || !strings.Contains(err.Error(), "start_positions[0] is on a wall") {
+		t.Errorf("got %v, want the wall outside the board and the start on a wall reported", err)
+	}
+	if _, err = ParseCustomMap("typo.json", []byte(`{"widht": 5}`)); err == nil {
+		t.Error("unknown fields were accepted")
+	}
+}
+
+func TestCustomMapHazardSchedule(t *testing.T) {
+	def, err := ParseCustomMap("test-schedule.yaml", []byte(arenaYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newCustomGameMap(def)
+	for turn, want := range map[int]int{1: 1, 2: 6, 3: 6, 4: 1} {
+		boardState := rules.NewBoardState(5, 5)
+		m.placeHazards(maps.NewBoardStateEditor(boardState), turn)
+		if len(boardState.Hazards) != want {
+			t.Errorf("turn %d has %d hazards, want %d", turn, len(boardState.Hazards), want)
+		}
+	}
+}
+
+func TestCustomMapWalls(t *testing.T) {
+	def, err := ParseCustomMap("test-walls.yaml", []byte(arenaYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterCustomMap(def); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := maps.GetMap("test-walls"); err != nil {
+		t.Fatalf("the rules do not know the map: %v", err)
+	}
+
+	gameState := &GameState{
+		Width:           11,
+		Height:          11,
+		Names:           []string{"one", "two"},
+		URLs:            []string{"http://one", "http://two"},
+		Timeout:         500,
+		GameType:        "standard",
+		MapName:         "test-walls",
+		Seed:            42,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+		Headless:        true,
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if gameState.Width != 5 || gameState.Height != 5 {
+		t.Fatalf("board is %dx%d, want the 5x5 of the map", gameState.Width, gameState.Height)
+	}
+	gameState.httpClient = greedySnakeClient{}
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// the greedy snakes go up, the one starting at (1, 1) hits the wall at
+	// (1, 3) on its second move
+	walled := 0
+	for _, snake := range gameState.Result().Snakes {
+		if snake.EliminatedCause == EliminatedByWall {
+			walled++
+			if snake.EliminatedOnTurn != 2 {
+				t.Errorf("%s hit the wall on turn %d, want 2", snake.Name, snake.EliminatedOnTurn)
+			}
+		}
+	}
+	if walled != 1 {
+		t.Errorf("%d snakes hit the wall, want 1", walled)
+	}
+}
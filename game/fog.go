@@ -0,0 +1,237 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// FogMode decides how much of the board the snakes see.
+type FogMode string
+
+const (
+	// FogOff sends the whole board to every snake.
+	FogOff FogMode = "off"
+	// FogRadius shows a snake the cells at most VisionRadius moves away from
+	// a segment of its body.
+	FogRadius FogMode = "radius"
+	// FogSight is FogRadius where snake bodies and map walls block the view:
+	// the cells behind them are hidden.
+	FogSight FogMode = "sight"
+)
+
+// DefaultVisionRadius is used when VisionRadius is not set.
+const DefaultVisionRadius = 3
+
+// ParseFogMode checks a fog mode name, an empty name is FogOff.
+func ParseFogMode(name string) (FogMode, error) {
+	switch mode := FogMode(name); mode {
+	case "":
+		return FogOff, nil
+	case FogOff, FogRadius, FogSight:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown fog mode %q, valid modes are %q, %q and %q", name, FogOff, FogRadius, FogSight)
+	}
+}
+
+// String, Set and Type let a FogMode be used as a command flag.
+func (m *FogMode) String() string { return string(*m) }
+
+func (m *FogMode) Set(name string) error {
+	mode, err := ParseFogMode(name)
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+func (m *FogMode) Type() string { return "mode" }
+
+// FogOptions configures fog of war. The snakes get only what they see of the
+// board: the food, hazards and enemy segments elsewhere are left out of
+// their requests. The enemies they see in part have a zero health and, when
+// their head is hidden, the head HiddenHead, off the board; see fogBoard.
+// Spectators still get the whole board.
+type FogOptions struct {
+	Fog FogMode
+	// VisionRadius, in moves, is how far the snakes see.
+	VisionRadius int
+	// VisionOverlay adds the cells each snake sees to the board frames, so
+	// that spectators can show them.
+	VisionOverlay bool
+}
+
+// MaxVisionRadius bounds VisionRadius, beyond it the snakes see any board.
+const MaxVisionRadius = 2 * MaxCustomMapSize
+
+// ParseFogOptions checks the fog settings asked for a game.
+func ParseFogOptions(mode string, radius int, overlay bool) (FogOptions, error) {
+	fog, err := ParseFogMode(mode)
+	if err != nil {
+		return FogOptions{}, err
+	}
+	if radius < 0 || radius > MaxVisionRadius {
+		return FogOptions{}, fmt.Errorf("vision radius must be between 1 and %d, or 0 for the default of %d", MaxVisionRadius, DefaultVisionRadius)
+	}
+	return FogOptions{Fog: fog, VisionRadius: radius, VisionOverlay: overlay}, nil
+}
+
+func (opts FogOptions) enabled() bool {
+	return opts.Fog == FogRadius || opts.Fog == FogSight
+}
+
+func (opts FogOptions) radius() int {
+	if opts.VisionRadius <= 0 {
+		return DefaultVisionRadius
+	}
+	return opts.VisionRadius
+}
+
+// visibleCells returns the cells the snake with the given ID sees, nil when
+// it is not on the board.
+func (gameState *GameState) visibleCells(boardState *rules.BoardState, id string) map[rules.Point]bool {
+	var body []rules.Point
+	blockers := map[rules.Point]bool{}
+	for _, snake := range boardState.Snakes {
+		if snake.ID == id {
+			body = snake.Body
+		}
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		for _, p := range snake.Body {
+			blockers[p] = true
+		}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if m, ok := gameState.gameMap.(customGameMap); ok {
+		for p := range m.walls {
+			blockers[p] = true
+		}
+	}
+
+	radius := gameState.radius()
+	visible := map[rules.Point]bool{}
+	for _, from := range body {
+		for x := max(from.X-radius, 0); x <= min(from.X+radius, boardState.Width-1); x++ {
+			for y := max(from.Y-radius, 0); y <= min(from.Y+radius, boardState.Height-1); y++ {
+				to := rules.Point{X: x, Y: y}
+				if visible[to] || abs(to.X-from.X)+abs(to.Y-from.Y) > radius {
+					continue
+				}
+				if gameState.Fog == FogSight && lineBlocked(from, to, blockers) {
+					continue
+				}
+				visible[to] = true
+			}
+		}
+	}
+	return visible
+}
+
+// lineBlocked reports whether a blocker stands on the straight line between
+// from and to, not counting the two ends: a wall or a body is seen, what is
+// behind it is not.
+func lineBlocked(from, to rules.Point, blockers map[rules.Point]bool) bool {
+	// Bresenham's line algorithm
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+	if from.Y > to.Y {
+		sy = -1
+	}
+	err := dx + dy
+	p := from
+	for {
+		if p != from && p != to && blockers[p] {
+			return true
+		}
+		if p == to {
+			return false
+		}
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			p.X += sx
+		} else {
+			err += dx
+			p.Y += sy
+		}
+	}
+}
+
+// HiddenHead is the head of the enemies whose head is hidden by the fog, a
+// cell off the board so that no snake mistakes it for a real one.
+var HiddenHead = client.Coord{X: -1, Y: -1}
+
+// fogBoard leaves out of board what the snake with the given ID does not
+// see. Enemy snakes keep only their visible segments, in order; the snake
+// itself is left whole. An enemy partly hidden gets its visible segments for
+// length, a zero health and, when its head is hidden, HiddenHead.
+func fogBoard(board client.Board, id string, visible map[rules.Point]bool) client.Board {
+	seen := func(c client.Coord) bool {
+		return visible[rules.Point{X: c.X, Y: c.Y}]
+	}
+	filter := func(coords []client.Coord) []client.Coord {
+		kept := []client.Coord{}
+		for _, c := range coords {
+			if seen(c) {
+				kept = append(kept, c)
+			}
+		}
+		return kept
+	}
+
+	fogged := board
+	fogged.Food = filter(board.Food)
+	fogged.Hazards = filter(board.Hazards)
+	fogged.Snakes = []client.Snake{}
+	for _, snake := range board.Snakes {
+		if snake.ID != id {
+			visibleBody := filter(snake.Body)
+			if len(visibleBody) == 0 {
+				continue
+			}
+			if len(visibleBody) < len(snake.Body) {
+				if !seen(snake.Head) {
+					snake.Head = HiddenHead
+				}
+				snake.Health = 0
+			}
+			snake.Body = visibleBody
+			snake.Length = len(visibleBody)
+		}
+		fogged.Snakes = append(fogged.Snakes, snake)
+	}
+	return fogged
+}
+
+// sortedPoints lists the cells of a set by row, then column, so that frames
+// do not depend on map order.
+func sortedPoints(cells map[rules.Point]bool) []rules.Point {
+	points := make([]rules.Point, 0, len(cells))
+	for p := range cells {
+		points = append(points, p)
+	}
+	slices.SortFunc(points, func(a, b rules.Point) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	return points
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
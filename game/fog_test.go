@@ -0,0 +1,97 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestVisibleCells(t *testing.T) {
+	boardState := rules.NewBoardState(7, 7)
+	boardState.Snakes = []rules.Snake{
+		{ID: "me", Body: []rules.Point{{X: 1, Y: 3}, {X: 0, Y: 3}}, Health: 100},
+		{ID: "wall", Body: []rules.Point{{X: 3, Y: 2}, {X: 3, Y: 3}, {X: 3, Y: 4}}, Health: 100},
+	}
+
+	gameState := &GameState{FogOptions: FogOptions{Fog: FogRadius, VisionRadius: 3}}
+	visible := gameState.visibleCells(boardState, "me")
+	for p, want := range map[rules.Point]bool{
+		{X: 4, Y: 3}: true,  // 3 moves from the head
+		{X: 5, Y: 3}: false, // 4 moves away
+		{X: 0, Y: 6}: true,  // 3 moves from the tail
+		{X: 3, Y: 3}: true,
+	} {
+		if visible[p] != want {
+			t.Errorf("radius: %v visible is %v, want %v", p, visible[p], want)
+		}
+	}
+
+	gameState.Fog = FogSight
+	visible = gameState.visibleCells(boardState, "me")
+	for p, want := range map[rules.Point]bool{
+		{X: 3, Y: 3}: true,  // the body in the way is seen
+		{X: 4, Y: 3}: false, // what is behind it is not
+		{X: 1, Y: 6}: true,
+	} {
+		if visible[p] != want {
+			t.Errorf("sight: %v visible is %v, want %v", p, visible[p], want)
+		}
+	}
+
+	if visible := gameState.visibleCells(boardState, "gone"); visible != nil {
+		t.Errorf("a snake off the board sees %v", visible)
+	}
+}
+
+func TestLineBlockedEnds(t *testing.T) {
+	// every line reaches its end without stepping off the straight path
+	for x := -4; x <= 4; x++ {
+		for y := -4; y <= 4; y++ {
+			to := rules.Point{X: x, Y: y}
+			blockers := map[rules.Point]bool{to: true, {}: true}
+			if lineBlocked(rules.Point{}, to, blockers) {
+				t.Errorf("the line to %v is blocked by its own ends", to)
+			}
+		}
+	}
+}
+
+func TestFogBoard(t *testing.T) {
+	board := client.Board{
+		Height: 5,
+		Width:  5,
+		Food:   []client.Coord{{X: 0, Y: 1}, {X: 4, Y: 4}},
+		Snakes: []client.Snake{
+			{ID: "me", Head: client.Coord{X: 0, Y: 0}, Body: []client.Coord{{X: 0, Y: 0}, {X: 4, Y: 0}}, Length: 2},
+			{ID: "near", Head: client.Coord{X: 2, Y: 1}, Body: []client.Coord{{X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}}, Length: 3, Health: 80},
+			{ID: "far", Head: client.Coord{X: 4, Y: 3}, Body: []client.Coord{{X: 4, Y: 3}}, Length: 1},
+			{ID: "seen", Head: client.Coord{X: 0, Y: 1}, Body: []client.Coord{{X: 0, Y: 1}}, Length: 1, Health: 90},
+		},
+		Hazards: []client.Coord{{X: 3, Y: 3}},
+	}
+	visible := map[rules.Point]bool{{X: 0, Y: 0}: true, {X: 0, Y: 1}: true, {X: 1, Y: 0}: true, {X: 1, Y: 1}: true}
+
+	fogged := fogBoard(board, "me", visible)
+	if len(fogged.Food) != 1 || len(fogged.Hazards) != 0 {
+		t.Errorf("got food %v and hazards %v, want only the food at (0, 1)", fogged.Food, fogged.Hazards)
+	}
+	if len(fogged.Snakes) != 3 {
+		t.Fatalf("got %d snakes, want me, the near one and the seen one", len(fogged.Snakes))
+	}
+	if me := fogged.Snakes[0]; len(me.Body) != 2 {
+		t.Errorf("the snake lost its own hidden segments: %v", me.Body)
+	}
+	// the head of the near snake is hidden, neither its first visible
+	// segment nor (0, 0) is taken for it
+	near := fogged.Snakes[1]
+	if near.Length != 2 || near.Head != HiddenHead || near.Health != 0 || near.Body[0] != (client.Coord{X: 1, Y: 1}) {
+		t.Errorf("got the near snake as %+v, want its two visible segments and nothing else", near)
+	}
+	if seen := fogged.Snakes[2]; seen.Head != (client.Coord{X: 0, Y: 1}) || seen.Health != 90 {
+		t.Errorf("got the snake in sight as %+v, want it whole", seen)
+	}
+	if len(board.Snakes[1].Body) != 3 {
+		t.Error("fogBoard changed the full board")
+	}
+}
@@ -1,28 +1,38 @@
 package game
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"time"
 )
 
 type TimedHttpClient interface {
-	Get(url string) (*http.Response, time.Duration, error)
-	Post(url string, contentType string, body io.Reader) (*http.Response, time.Duration, error)
+	Get(ctx context.Context, url string) (*http.Response, time.Duration, error)
+	Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, time.Duration, error)
 }
 
 type timedHTTPClient struct {
 	*http.Client
 }
 
-func (client timedHTTPClient) Get(url string) (*http.Response, time.Duration, error) {
+func (client timedHTTPClient) Get(ctx context.Context, url string) (*http.Response, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
 	startTime := time.Now()
-	res, err := client.Client.Get(url)
+	res, err := client.Client.Do(req)
 	return res, time.Since(startTime), err
 }
 
-func (client timedHTTPClient) Post(url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+func (client timedHTTPClient) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
 	startTime := time.Now()
-	res, err := client.Client.Post(url, contentType, body)
+	res, err := client.Client.Do(req)
 	return res, time.Since(startTime), err
 }
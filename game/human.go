@@ -0,0 +1,155 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// HumanURLScheme is the scheme of the URLs of the snakes played from a
+// keyboard, see HumanURL. Their moves come over the game websocket.
+const HumanURLScheme = "human"
+
+// HumanMoveTimeout is the /move timeout, in milliseconds, of the games with
+// a human player: each turn lasts that long, a person needs the time.
+const HumanMoveTimeout = 700
+
+// ControlMove is the command of the human players, with Move the direction
+// they pressed. It is not a command of GameState.Control, see Steer.
+const ControlMove = "move"
+
+// humanMoveMargin is how long before the deadline the move of a human
+// player is played, so that it is never late.
+const humanMoveMargin = 20 * time.Millisecond
+
+// ErrNotPlayer is returned by Steer for the players not in the game.
+var ErrNotPlayer = errors.New("not a player of the game")
+
+// HumanURL returns the URL of the snake played by the given player, who
+// steers it with Steer.
+func HumanURL(player string) string {
+	return (&url.URL{Scheme: HumanURLScheme, Host: player}).String()
+}
+
+// humanPlayers holds the last direction pressed by each human player.
+type humanPlayers struct {
+	lock  sync.Mutex
+	moves map[string]string // by player, the empty move until one is pressed
+}
+
+// newHumanPlayers sets up the players of the human:// URLs among urls.
+func newHumanPlayers(urls []string) *humanPlayers {
+	players := &humanPlayers{moves: map[string]string{}}
+	for _, snakeURL := range urls {
+		if u, err := url.Parse(snakeURL); err == nil && u.Scheme == HumanURLScheme {
+			players.moves[u.Host] = ""
+		}
+	}
+	return players
+}
+
+// HasHumans reports whether a person plays one of the snakes at urls.
+func HasHumans(urls []string) bool {
+	return len(newHumanPlayers(urls).moves) > 0
+}
+
+// Steer records the move pressed by a human player. It is played when the
+// turn timer of the snake runs out, unless another move is pressed before.
+func (gameState *GameState) Steer(player string, move string) error {
+	if !slices.Contains([]string{rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight}, move) {
+		return fmt.Errorf("invalid move %q, valid moves are up, down, left or right", move)
+	}
+	gameState.humans.lock.Lock()
+	defer gameState.humans.lock.Unlock()
+	if _, ok := gameState.humans.moves[player]; !ok {
+		return ErrNotPlayer
+	}
+	gameState.humans.moves[player] = move
+	return nil
+}
+
+// humanProvider plays the snake of a human player: Move waits for the turn
+// timer and plays the last move pressed.
+type humanProvider struct {
+	players *humanPlayers
+	player  string
+	timeout time.Duration
+}
+
+func (p humanProvider) Info(ctx context.Context) (client.SnakeMetadataResponse, error) {
+	return client.SnakeMetadataResponse{
+		APIVersion: "1",
+		Author:     "humano",
+		Color:      "#db2777",
+		Head:       "default",
+		Tail:       "default",
+	}, nil
+}
+
+func (p humanProvider) Start(ctx context.Context, request client.SnakeRequest) error {
+	return nil
+}
+
+func (p humanProvider) Move(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, time.Duration, error) {
+	start := time.Now()
+	deadline := start.Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	sleep(ctx, time.Until(deadline)-humanMoveMargin)
+	if ctx.Err() != nil {
+		return client.MoveResponse{}, time.Since(start), ctx.Err()
+	}
+
+	p.players.lock.Lock()
+	move := p.players.moves[p.player]
+	p.players.lock.Unlock()
+	// a move back into the neck is a mistake, the snake goes on
+	if forward := forwardMove(request.You.Body); move == "" || move == oppositeMove(forward) {
+		move = forward
+	}
+	return client.MoveResponse{Move: move}, time.Since(start), nil
+}
+
+func (p humanProvider) End(ctx context.Context, request client.SnakeRequest) error {
+	return nil
+}
+
+// forwardMove returns the direction the snake with the given body is going,
+// up before its first move.
+func forwardMove(body []client.Coord) string {
+	if len(body) < 2 {
+		return rules.MoveUp
+	}
+	head, neck := body[0], body[1]
+	switch {
+	case head.X > neck.X:
+		return rules.MoveRight
+	case head.X < neck.X:
+		return rules.MoveLeft
+	case head.Y < neck.Y:
+		return rules.MoveDown
+	default:
+		return rules.MoveUp
+	}
+}
+
+func oppositeMove(move string) string {
+	switch move {
+	case rules.MoveUp:
+		return rules.MoveDown
+	case rules.MoveDown:
+		return rules.MoveUp
+	case rules.MoveLeft:
+		return rules.MoveRight
+	default:
+		return rules.MoveLeft
+	}
+}
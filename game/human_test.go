@@ -0,0 +1,96 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestHumanMove(t *testing.T) {
+	players := newHumanPlayers([]string{HumanURL("ana"), "http://snake"})
+	humans := humanProvider{players: players, player: "ana", timeout: 50 * time.Millisecond}
+	gameState := &GameState{humans: players}
+
+	// the snake goes up, from {5, 4} to {5, 5}
+	move := func() string {
+		t.Helper()
+		request := client.SnakeRequest{You: client.Snake{Body: []client.Coord{{X: 5, Y: 5}, {X: 5, Y: 4}}}}
+		start := time.Now()
+		response, _, err := humans.Move(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Move: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 25*time.Millisecond || elapsed > 50*time.Millisecond {
+			t.Errorf("the move came after %v, want it just before the turn timer runs out", elapsed)
+		}
+		return response.Move
+	}
+
+	if got := move(); got != "up" {
+		t.Errorf("got %q before any key was pressed, want the snake to go on up", got)
+	}
+	if err := gameState.Steer("ana", "left"); err != nil {
+		t.Fatalf("Steer: %v", err)
+	}
+	if got := move(); got != "left" {
+		t.Errorf("got %q, want the move pressed", got)
+	}
+	gameState.Steer("ana", "down")
+	if got := move(); got != "up" {
+		t.Errorf("got %q after the snake was turned back into its neck, want it to go on up", got)
+	}
+
+	if err := gameState.Steer("bruno", "up"); !errors.Is(err, ErrNotPlayer) {
+		t.Errorf("steering the snake of another player: got %v, want ErrNotPlayer", err)
+	}
+	if err := gameState.Steer("ana", "jump"); err == nil {
+		t.Error("an invalid move was accepted")
+	}
+}
+
+func TestGreedyMove(t *testing.T) {
+	request := client.SnakeRequest{
+		Board: client.Board{Width: 5, Height: 5, Food: []client.Coord{{X: 0, Y: 4}}},
+		You:   client.Snake{Head: client.Coord{X: 0, Y: 2}, Body: []client.Coord{{X: 0, Y: 2}, {X: 1, Y: 2}}},
+	}
+	request.Board.Snakes = []client.Snake{request.You, {Body: []client.Coord{{X: 0, Y: 3}, {X: 1, Y: 3}}}}
+	// up is closer to the food but taken, left is the wall
+	if got := greedyMove(request); got != "down" {
+		t.Errorf("got %q, want the only safe move", got)
+	}
+}
+
+func TestHumanGameAgainstBot(t *testing.T) {
+	gameState := &GameState{
+		Width:           11,
+		Height:          11,
+		Names:           []string{"ana", "bot"},
+		URLs:            []string{HumanURL("ana"), BotURL},
+		Timeout:         40,
+		GameType:        "standard",
+		MapName:         "standard",
+		Seed:            42,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+		Headless:        true,
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// left alone, the human snake runs into a wall and the bot wins
+	result := gameState.Result()
+	if result.WinnerName != "bot" || result.Turns > 11 {
+		t.Errorf("got winner %q after %d turns, want the bot to win once the human snake hits a wall", result.WinnerName, result.Turns)
+	}
+	if result.Snakes[0].Timeouts > 0 {
+		t.Errorf("the human snake timed out %d times, its moves must come before the deadline", result.Snakes[0].Timeouts)
+	}
+}
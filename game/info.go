@@ -2,9 +2,9 @@ package game
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/spf13/cobra"
-	log "github.com/spf13/jwalterweatherman"
 
 	"github.com/BattlesnakeOfficial/rules/maps"
 )
@@ -36,7 +36,7 @@ func NewMapInfoCommand() *cobra.Command {
 			if len(args) < 1 {
 				err := cmd.Help()
 				if err != nil {
-					log.ERROR.Println(err)
+					slog.Error("Error showing help", "err", err)
 				}
 				return
 			}
@@ -60,7 +60,7 @@ func NewMapInfoCommand() *cobra.Command {
 func (m *mapInfo) display(id string) {
 	gameMap, err := maps.GetMap(id)
 	if err != nil {
-		log.ERROR.Printf("Failed to load game map %v: %v", id, err)
+		slog.Error("Failed to load game map", "map", id, "err", err)
 		return
 	}
 	meta := gameMap.Meta()
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+var moveLatency = metrics.NewHistogram(
+	"localsnake_move_request_duration_seconds",
+	"Time taken by snakes to answer /move, by result: ok, timeout or error.",
+	[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	"result",
+)
+
+// latencyBucket is the width of the buckets of a LatencyHistogram.
+const latencyBucket = 10 * time.Millisecond
+
+// LatencyHistogram counts move latencies in 10ms buckets, keyed by the lower
+// bound of the bucket in milliseconds. Histograms of several games add up
+// with Merge, unlike percentiles.
+type LatencyHistogram map[int64]int
+
+// NewLatencyHistogram counts the given latencies.
+func NewLatencyHistogram(latencies []time.Duration) LatencyHistogram {
+	h := LatencyHistogram{}
+	for _, latency := range latencies {
+		h.Add(latency)
+	}
+	return h
+}
+
+// Add counts one latency.
+func (h LatencyHistogram) Add(latency time.Duration) {
+	h[int64(max(latency, 0)/latencyBucket)*latencyBucket.Milliseconds()]++
+}
+
+// Merge adds the counts of other to h.
+func (h LatencyHistogram) Merge(other LatencyHistogram) {
+	for bucket, n := range other {
+		h[bucket] += n
+	}
+}
+
+// Count returns the number of latencies counted.
+func (h LatencyHistogram) Count() int {
+	total := 0
+	for _, n := range h {
+		total += n
+	}
+	return total
+}
+
+// Percentile returns the upper bound of the bucket holding the nearest-rank
+// percentile p, 0 for an empty histogram.
+func (h LatencyHistogram) Percentile(p int) time.Duration {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+	rank := max((p*total+99)/100, 1)
+	seen := 0
+	for _, bucket := range slices.Sorted(maps.Keys(h)) {
+		seen += h[bucket]
+		if seen >= rank {
+			return time.Duration(bucket)*time.Millisecond + latencyBucket
+		}
+	}
+	return 0
+}
+
+// observeMove adds a /move request to the move latency metric, before and
+// after are the states of the snake around the request.
+func observeMove(before, after SnakeState) {
+	result := "ok"
+	switch {
+	case after.TimedOut:
+		result = "timeout"
+	case after.Error != nil, after.StatusErrors > before.StatusErrors, after.DecodeErrors > before.DecodeErrors:
+		result = "error"
+	}
+	moveLatency.Observe(after.Latency.Seconds(), result)
+}
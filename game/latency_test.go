@@ -0,0 +1,33 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogramPercentile(t *testing.T) {
+	h := NewLatencyHistogram([]time.Duration{
+		3 * time.Millisecond, 12 * time.Millisecond, 15 * time.Millisecond, 48 * time.Millisecond,
+	})
+	h.Merge(NewLatencyHistogram([]time.Duration{480 * time.Millisecond}))
+
+	if n := h.Count(); n != 5 {
+		t.Fatalf("Count() = %d, want 5", n)
+	}
+	for _, c := range []struct {
+		p    int
+		want time.Duration
+	}{
+		{50, 20 * time.Millisecond},
+		{80, 50 * time.Millisecond},
+		{95, 490 * time.Millisecond},
+		{0, 10 * time.Millisecond},
+	} {
+		if got := h.Percentile(c.p); got != c.want {
+			t.Errorf("Percentile(%d) = %v, want %v", c.p, got, c.want)
+		}
+	}
+	if got := (LatencyHistogram{}).Percentile(95); got != 0 {
+		t.Errorf("empty Percentile(95) = %v, want 0", got)
+	}
+}
@@ -1,8 +1,9 @@
 package game
 
 import (
+	"log/slog"
+
 	"github.com/spf13/cobra"
-	log "github.com/spf13/jwalterweatherman"
 )
 
 func NewMapCommand() *cobra.Command {
@@ -14,7 +15,7 @@ func NewMapCommand() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			err := cmd.Help()
 			if err != nil {
-				log.ERROR.Println(err)
+				slog.Error("Error showing help", "err", err)
 				return
 			}
 		},
@@ -1,7 +1,9 @@
 package game
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -12,13 +14,17 @@ type GameExporter struct {
 	game          client.Game
 	snakeRequests []client.SnakeRequest
 	winner        SnakeState
+	winnerSquad   string
 	isDraw        bool
 }
 
-type result struct {
+// ReplayResult is the last line of an exported game.
+type ReplayResult struct {
 	WinnerID   string `json:"winnerId"`
 	WinnerName string `json:"winnerName"`
-	IsDraw     bool   `json:"isDraw"`
+	// WinnerSquad is set instead of the winner snake in squad games.
+	WinnerSquad string `json:"winnerSquad,omitempty"`
+	IsDraw      bool   `json:"isDraw"`
 }
 
 func (ge *GameExporter) FlushToFile(outputFile io.Writer) (int, error) {
@@ -51,10 +57,11 @@ func (ge *GameExporter) ConvertToJSON() ([]string, error) {
 		}
 		output = append(output, string(serialisedBoard))
 	}
-	serialisedResult, err := json.Marshal(result{
-		WinnerID:   ge.winner.ID,
-		WinnerName: ge.winner.Name,
-		IsDraw:     ge.isDraw,
+	serialisedResult, err := json.Marshal(ReplayResult{
+		WinnerID:    ge.winner.ID,
+		WinnerName:  ge.winner.Name,
+		WinnerSquad: ge.winnerSquad,
+		IsDraw:      ge.isDraw,
 	})
 	if err != nil {
 		return output, err
@@ -66,3 +73,55 @@ func (ge *GameExporter) ConvertToJSON() ([]string, error) {
 func (ge *GameExporter) AddSnakeRequest(snakeRequest client.SnakeRequest) {
 	ge.snakeRequests = append(ge.snakeRequests, snakeRequest)
 }
+
+// Replay is a game read back from the JSONL file written by GameExporter.
+type Replay struct {
+	Game   client.Game           `json:"game"`
+	Turns  []client.SnakeRequest `json:"turns"`
+	Result *ReplayResult         `json:"result"`
+}
+
+// ReadReplay parses an exported game. Games that are still running have no
+// result line yet, so Result is nil for them.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var lines [][]byte
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("empty replay")
+	}
+
+	replay := &Replay{Turns: make([]client.SnakeRequest, 0, len(lines))}
+	if err := json.Unmarshal(lines[0], &replay.Game); err != nil {
+		return nil, fmt.Errorf("invalid game line: %w", err)
+	}
+
+	for i, line := range lines[1:] {
+		var turn client.SnakeRequest
+		if err := json.Unmarshal(line, &turn); err != nil {
+			return nil, fmt.Errorf("invalid replay line %d: %w", i+2, err)
+		}
+		// the result line decodes into an empty request, so tell them apart by the game ID
+		if turn.Game.ID == "" && i == len(lines)-2 {
+			var result ReplayResult
+			if err := json.Unmarshal(line, &result); err != nil {
+				return nil, fmt.Errorf("invalid result line: %w", err)
+			}
+			replay.Result = &result
+			break
+		}
+		replay.Turns = append(replay.Turns, turn)
+	}
+
+	return replay, nil
+}
@@ -2,14 +2,18 @@ package game
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,7 +24,9 @@ import (
 	"github.com/BattlesnakeOfficial/rules/client"
 	"github.com/BattlesnakeOfficial/rules/maps"
 	"github.com/google/uuid"
-	log "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/cobra"
+
+	"github.com/secomp2025/localsnake/logging"
 )
 
 // Used to store state for each SnakeState while running a local game
@@ -38,6 +44,22 @@ type SnakeState struct {
 	Error      error
 	StatusCode int
 	Latency    time.Duration
+	// TimedOut is set when the last /move request took too long, Timeouts
+	// and ConsecutiveTimeouts count such requests.
+	TimedOut            bool
+	Timeouts            int
+	ConsecutiveTimeouts int
+	// TimeBank is the extra time left to the snake, see TimeoutOptions.
+	TimeBank time.Duration
+	// StatusErrors counts the /move answers with a status other than 200,
+	// DecodeErrors the ones without a valid JSON move.
+	StatusErrors int
+	DecodeErrors int
+	// Squad is the squad of the snake in squad games.
+	Squad string
+	// Shout is the sanitised shout of the last /move answer, passed on to
+	// the other snakes in the next request.
+	Shout string
 }
 
 type GameState struct {
@@ -56,45 +78,192 @@ type GameState struct {
 	Seed                int64
 	TurnDelay           int
 	OutputPath          string
+	OutputDir           string
 	ViewInBrowser       bool
 	BoardURL            string
 	FoodSpawnChance     int
 	MinimumFood         int
 	HazardDamagePerTurn int
 	ShrinkEveryNTurns   int
+	// Headless skips printing the board every turn, for batch runs.
+	Headless bool
+	// GameID, when set, is used instead of a random game ID.
+	GameID string
+	// Providers play the snakes without HTTP when set: Providers[i], if not
+	// nil, plays the snake of URLs[i] in place of its URL. A provider plays
+	// in a single game.
+	Providers []MoveProvider
+	TimeoutOptions
+	FogOptions
+	SquadOptions
 
 	// Internal game state
 	settings    map[string]string
 	snakeStates map[string]SnakeState
+	snakeIDs    []string // snake IDs in the order of Names and URLs
+	providers   map[string]MoveProvider
+	latencies   map[string][]time.Duration
 	gameID      string
 	httpClient  TimedHttpClient
 	ruleset     rules.Ruleset
 	gameMap     maps.GameMap
 	outputFile  io.WriteCloser
 	idGenerator func(int) string
+	rand        *rand.Rand // seeded with Seed, drives everything random but the game ID
+	pause       *pauseGate
+	humans      *humanPlayers
+	progress    *progress
+	result      Result
+}
+
+func NewPlayCommand() *cobra.Command {
+	gameState := &GameState{}
+
+	var playCmd = &cobra.Command{
+		Use:   "play",
+		Short: "Play a game of Battlesnake locally.",
+		Long:  "Play a game of Battlesnake locally against arbitrary snake URLs.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := gameState.Initialize(); err != nil {
+				return fmt.Errorf("error initializing game: %w", err)
+			}
+			return gameState.play(cmd.Context())
+		},
+	}
+
+	playCmd.Flags().IntVarP(&gameState.Width, "width", "W", 11, "Width of Board")
+	playCmd.Flags().IntVarP(&gameState.Height, "height", "H", 11, "Height of Board")
+	playCmd.Flags().StringArrayVarP(&gameState.Names, "name", "n", nil, "Name of Snake")
+	playCmd.Flags().StringArrayVarP(&gameState.URLs, "url", "u", nil, "URL of Snake, bot://greedy or stdio:<command>")
+	playCmd.Flags().IntVarP(&gameState.Timeout, "timeout", "t", 500, "Request Timeout")
+	gameState.TimeoutPolicy = TimeoutRepeatLast
+	playCmd.Flags().Var(&gameState.TimeoutPolicy, "timeout-policy", "Move of a snake that times out: repeat, random-safe or eliminate")
+	playCmd.Flags().IntVar(&gameState.MaxTimeouts, "max-timeouts", DefaultMaxTimeouts, "Consecutive timeouts that eliminate a snake with --timeout-policy eliminate")
+	playCmd.Flags().IntVar(&gameState.TimeBank, "time-bank", 0, "Extra milliseconds each snake may spend over the game on slow moves")
+	gameState.Fog = FogOff
+	playCmd.Flags().Var(&gameState.Fog, "fog", "Fog of war: off, radius (snakes see only near their bodies) or sight (nor behind bodies and walls)")
+	playCmd.Flags().IntVar(&gameState.VisionRadius, "vision-radius", DefaultVisionRadius, "How many moves away the snakes see with --fog")
+	playCmd.Flags().BoolVar(&gameState.VisionOverlay, "vision-overlay", false, "Send what each snake sees to the board viewer with --fog")
+	playCmd.Flags().StringArrayVar(&gameState.Squads, "squad", nil, "Squad of Snake, given for every snake to play in squads")
+	playCmd.Flags().Var(&gameState.SquadRules, "squad-rules", "Rules of the squads: pass-through, shared-health, shared-length and shared-elimination, comma separated")
+	playCmd.Flags().BoolVarP(&gameState.Sequential, "sequential", "s", false, "Use Sequential Processing")
+	playCmd.Flags().StringVarP(&gameState.GameType, "gametype", "g", "standard", "Type of Game Rules")
+	playCmd.Flags().StringVarP(&gameState.MapName, "map", "m", "standard", "Game map to use to populate the board")
+	playCmd.Flags().BoolVarP(&gameState.ViewMap, "viewmap", "v", false, "View the Map Each Turn")
+	playCmd.Flags().BoolVarP(&gameState.UseColor, "color", "c", false, "Use color to draw the map")
+	playCmd.Flags().Int64VarP(&gameState.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed")
+	playCmd.Flags().IntVarP(&gameState.TurnDelay, "delay", "d", 0, "Turn Delay in Milliseconds")
+	playCmd.Flags().IntVarP(&gameState.TurnDuration, "duration", "D", 0, "Minimum Turn Duration in Milliseconds")
+	playCmd.Flags().StringVarP(&gameState.OutputPath, "output", "o", "", "File path to output game state to. Existing files will be overwritten")
+	playCmd.Flags().StringVar(&gameState.OutputDir, "output-dir", "", "Directory to output the game state to, named after the game ID")
+	playCmd.Flags().BoolVar(&gameState.ViewInBrowser, "browser", false, "View the game in the browser using the Battlesnake game board")
+	playCmd.Flags().StringVar(&gameState.BoardURL, "board-url", "https://board.battlesnake.com", "Base URL for the game board when using --browser")
+
+	playCmd.Flags().IntVar(&gameState.FoodSpawnChance, "foodSpawnChance", 15, "Percentage chance of spawning a new food every round")
+	playCmd.Flags().IntVar(&gameState.MinimumFood, "minimumFood", 1, "Minimum food to keep on the board every turn")
+	playCmd.Flags().IntVar(&gameState.HazardDamagePerTurn, "hazardDamagePerTurn", 14, "Health damage a snake will take when ending its turn in a hazard")
+	playCmd.Flags().IntVar(&gameState.ShrinkEveryNTurns, "shrinkEveryNTurns", 25, "In Royale mode, the number of turns between generating new hazards")
+
+	playCmd.Flags().SortFlags = false
+
+	return playCmd
+}
+
+// play runs the game in the foreground. With ViewInBrowser the board events
+// are served on a local port for the board at BoardURL.
+func (gameState *GameState) play(ctx context.Context) error {
+	boardGame := board.Game{
+		ID:     gameState.gameID,
+		Status: "running",
+		Width:  gameState.Width,
+		Height: gameState.Height,
+		Ruleset: map[string]string{
+			rules.ParamGameType: gameState.GameType,
+		},
+		RulesetName: gameState.GameType,
+		RulesStages: []string{},
+		Map:         gameState.MapName,
+	}
+	if !gameState.ViewInBrowser {
+		return gameState.Run(ctx, boardGame, nil)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("error starting board server: %w", err)
+	}
+	defer listener.Close()
+
+	boardServer := NewBoardServer(boardGame)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/games/"+gameState.gameID, boardServer.HandleGame)
+	// the board only listens on localhost, its viewers may drive the game
+	mux.HandleFunc("/games/"+gameState.gameID+"/events", func(w http.ResponseWriter, r *http.Request) {
+		boardServer.HandleWebsocket(w, r, func(cmd ControlCommand) error {
+			if err := gameState.Control(cmd); err != nil {
+				return err
+			}
+			boardServer.SendEvent(gameState.ControlEvent())
+			return nil
+		})
+	})
+	go http.Serve(listener, mux)
+
+	engineURL := "http://" + listener.Addr().String()
+	boardURL := fmt.Sprintf("%s?engine=%s&game=%s&autoplay=true", gameState.BoardURL, url.QueryEscape(engineURL), gameState.gameID)
+	slog.InfoContext(ctx, "Open the game board", "url", boardURL)
+
+	defer boardServer.Shutdown()
+	return gameState.Run(ctx, boardGame, boardServer)
 }
 
 // Setup a GameState once all the fields have been parsed from the command-line.
 func (gameState *GameState) Initialize() error {
 	// Generate game ID
-	gameState.gameID = uuid.New().String()
+	gameState.gameID = gameState.GameID
+	if gameState.gameID == "" {
+		gameState.gameID = uuid.New().String()
+	}
+	gameState.rand = rand.New(rand.NewSource(gameState.Seed))
+	gameState.pause = &pauseGate{
+		delay:   time.Duration(gameState.TurnDelay) * time.Millisecond,
+		changed: make(chan struct{}),
+	}
+	gameState.progress = &progress{}
 
 	// Set up HTTP client with request timeout
 	if gameState.Timeout == 0 {
 		gameState.Timeout = 5500
 	}
+	policy, err := ParseTimeoutPolicy(string(gameState.TimeoutPolicy))
+	if err != nil {
+		return err
+	}
+	gameState.TimeoutPolicy = policy
+	if gameState.Fog, err = ParseFogMode(string(gameState.Fog)); err != nil {
+		return err
+	}
+	if err := CheckSquads(gameState.Squads, max(len(gameState.Names), len(gameState.URLs))); err != nil {
+		return err
+	}
+	// move requests get their own deadline, which may draw from the time bank
 	gameState.httpClient = timedHTTPClient{
 		&http.Client{
-			Timeout: time.Duration(gameState.Timeout) * time.Millisecond,
+			Timeout: gameState.requestTimeout(),
 		},
 	}
+	gameState.humans = newHumanPlayers(gameState.URLs)
 
 	// Load game map
-	gameMap, err := maps.GetMap(gameState.MapName)
+	gameMap, err := getMap(gameState.MapName)
 	if err != nil {
 		return fmt.Errorf("failed to load game map %#v: %v", gameState.MapName, err)
 	}
 	gameState.gameMap = gameMap
+	// maps made for a single board size, like the custom maps, choose it
+	if sizes := gameMap.Meta().BoardSizes; len(sizes) == 1 && !sizes.IsUnlimited() && !sizes.IsAllowable(gameState.Width, gameState.Height) {
+		gameState.Width, gameState.Height = sizes[0].Width, sizes[0].Height
+	}
 
 	// Create settings object
 	gameState.settings = map[string]string{
@@ -114,6 +283,16 @@ func (gameState *GameState) Initialize() error {
 
 	// Initialize snake states as empty until we can ping the snake URLs
 	gameState.snakeStates = map[string]SnakeState{}
+	gameState.snakeIDs = nil
+	gameState.providers = map[string]MoveProvider{}
+	gameState.latencies = map[string][]time.Duration{}
+
+	if gameState.OutputPath == "" && gameState.OutputDir != "" {
+		if err := os.MkdirAll(gameState.OutputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		gameState.OutputPath = filepath.Join(gameState.OutputDir, gameState.gameID+".jsonl")
+	}
 
 	if gameState.OutputPath != "" {
 		f, err := os.OpenFile(gameState.OutputPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
@@ -126,20 +305,24 @@ func (gameState *GameState) Initialize() error {
 	return nil
 }
 
-// Setup and run a full game.
-func (gameState *GameState) Run(boardGame board.Game, boardServer *BoardServer) error {
+// Setup and run a full game. boardServer may be nil when nobody watches the
+// game, e.g. in batch runs. Cancelling ctx aborts the game before its next
+// turn: no more requests are sent to the snakes and Run returns the cause of
+// ctx, see Abort.
+func (gameState *GameState) Run(ctx context.Context, boardGame board.Game, boardServer *BoardServer) error {
 	var gameOver bool
 	var err error
+	ctx = logging.With(ctx, "game_id", gameState.gameID)
+	// the snake programs live as long as the game
+	defer gameState.closeProviders(ctx)
 
 	// Setup local state for snakes
-	gameState.snakeStates, err = gameState.buildSnakesFromOptions()
+	gameState.snakeStates, err = gameState.buildSnakesFromOptions(ctx)
 	if err != nil {
 		return fmt.Errorf("error getting snake metadata: %w", err)
 	}
 
-	rand.New(rand.NewSource(gameState.Seed))
-
-	gameOver, boardState, err := gameState.initializeBoardFromArgs()
+	gameOver, boardState, err := gameState.initializeBoardFromArgs(ctx)
 	if err != nil {
 		return fmt.Errorf("error initializing board: %w", err)
 	}
@@ -155,13 +338,10 @@ func (gameState *GameState) Run(boardGame board.Game, boardServer *BoardServer)
 		defer gameState.outputFile.Close()
 	}
 
-	log.INFO.Printf("Ruleset: %v, Seed: %v", gameState.GameType, gameState.Seed)
+	slog.InfoContext(ctx, "Game started", "ruleset", gameState.GameType, "map", gameState.MapName, "seed", gameState.Seed)
+	gameState.progress.update(boardState)
 
-	if gameState.ViewMap {
-		gameState.printMap(boardState)
-	} else {
-		gameState.printState(boardState)
-	}
+	gameState.printBoard(ctx, boardState)
 
 	// Export game first, if enabled, so that we capture the request for turn zero.
 	if exportGame {
@@ -172,105 +352,171 @@ func (gameState *GameState) Run(boardGame board.Game, boardServer *BoardServer)
 		// In all cases the API request is technically non-compliant with how the actual API request should be.
 		// The third option (filling the `you` key with an arbitrary snake) is the closest to the actual API request that would need the least manipulation to
 		// be adjusted to look like an API call for a specific snake in the game.
-		for _, snakeState := range gameState.snakeStates {
-			snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-			gameExporter.AddSnakeRequest(snakeRequest)
-			break
-		}
+		snakeRequest := gameState.getFullRequestBody(boardState, gameState.snakeStates[gameState.snakeIDs[0]])
+		gameExporter.AddSnakeRequest(snakeRequest)
 	}
 
 	var endTime time.Time
+	var abortErr error
 	for !gameOver {
+		if abortErr = gameState.waitWhilePaused(ctx); abortErr != nil {
+			break
+		}
+
 		if gameState.TurnDuration > 0 {
 			endTime = time.Now().Add(time.Duration(gameState.TurnDuration) * time.Millisecond)
 		}
 
-		gameOver, boardState, err = gameState.createNextBoardState(boardState)
+		var played bool
+		gameOver, played, boardState, err = gameState.createNextBoardState(ctx, boardState)
+		if ctx.Err() != nil {
+			// the moves of this turn were cut short, it is not played
+			abortErr = context.Cause(ctx)
+			break
+		}
 		if err != nil {
 			return fmt.Errorf("error processing game: %w", err)
 		}
 
-		if gameOver {
+		if gameOver && !played {
 			// Stop processing here - because game over is detected at the start of the pipeline, nothing will have changed.
 			break
 		}
+		gameState.progress.update(boardState)
 
-		if gameState.ViewMap {
-			gameState.printMap(boardState)
-		} else {
-			gameState.printState(boardState)
-		}
+		gameState.printBoard(ctx, boardState)
 
-		if gameState.TurnDelay > 0 {
-			time.Sleep(time.Duration(gameState.TurnDelay) * time.Millisecond)
-		}
+		gameState.waitTurnDelay(ctx)
 
 		if gameState.TurnDuration > 0 {
-			time.Sleep(time.Until(endTime))
+			sleep(ctx, time.Until(endTime))
 		}
 
-		// if gameState.ViewInBrowser {
-		boardServer.SendEvent(gameState.buildFrameEvent(boardState))
-		// }
+		if boardServer != nil {
+			boardServer.SendEvent(gameState.buildFrameEvent(boardState))
+		}
 
 		if exportGame {
-			for _, snakeState := range gameState.snakeStates {
-				snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-				gameExporter.AddSnakeRequest(snakeRequest)
-				break
-			}
+			snakeRequest := gameState.getFullRequestBody(boardState, gameState.snakeStates[gameState.snakeIDs[0]])
+			gameExporter.AddSnakeRequest(snakeRequest)
 		}
 	}
 
 	gameExporter.isDraw = false
 
-	if len(gameState.snakeStates) > 1 {
+	if len(gameState.snakeStates) > 1 && abortErr == nil {
 		// A draw is possible if there is more than one snake in the game.
 		gameExporter.isDraw = true
 	}
 
-	for _, snake := range boardState.Snakes {
-		snakeState := gameState.snakeStates[snake.ID]
-		if snake.EliminatedCause == rules.NotEliminated {
-			gameExporter.isDraw = false
-			gameExporter.winner = snakeState
+	// an aborted game has no winner and its snakes may already be gone
+	if abortErr == nil {
+		for _, snake := range boardState.Snakes {
+			snakeState := gameState.snakeStates[snake.ID]
+			if snake.EliminatedCause == rules.NotEliminated {
+				gameExporter.isDraw = false
+				gameExporter.winner = snakeState
+			}
+
+			gameState.sendEndRequest(ctx, boardState, snakeState)
 		}
+		// squad games are won by a squad, whatever number of its snakes
+		// are left
+		if gameState.SquadOptions.enabled() {
+			squads := gameState.aliveSquads(boardState)
+			gameExporter.winner = SnakeState{}
+			gameExporter.isDraw = len(squads) != 1
+			if len(squads) == 1 {
+				gameExporter.winnerSquad = squads[0]
+			}
+		}
+	}
 
-		gameState.sendEndRequest(boardState, snakeState)
+	gameState.result = Result{
+		GameID:        gameState.gameID,
+		Turns:         boardState.Turn,
+		WinnerName:    gameExporter.winner.Name,
+		WinnerURL:     gameExporter.winner.URL,
+		WinnerSquad:   gameExporter.winnerSquad,
+		IsDraw:        gameExporter.isDraw,
+		Snakes:        gameState.snakeResults(boardState),
+		TimeoutPolicy: gameState.TimeoutPolicy,
 	}
 
-	if gameExporter.isDraw {
-		log.INFO.Printf("Game completed after %v turns. It was a draw.", boardState.Turn)
+	if abortErr != nil {
+		slog.WarnContext(ctx, "Game aborted", "turns", boardState.Turn, "err", abortErr)
+	} else if gameExporter.isDraw {
+		slog.InfoContext(ctx, "Game completed in a draw", "turns", boardState.Turn)
+	} else if gameExporter.winnerSquad != "" {
+		slog.InfoContext(ctx, "Game completed", "turns", boardState.Turn, "winner_squad", gameExporter.winnerSquad)
 	} else if gameExporter.winner.Name != "" {
-		log.INFO.Printf("Game completed after %v turns. %v was the winner.", boardState.Turn, gameExporter.winner.Name)
+		slog.InfoContext(ctx, "Game completed", "turns", boardState.Turn, "winner", gameExporter.winner.Name)
 	} else {
-		log.INFO.Printf("Game completed after %v turns.", boardState.Turn)
+		slog.InfoContext(ctx, "Game completed", "turns", boardState.Turn)
 	}
 
-	// if gameState.ViewInBrowser {
-	boardServer.SendEvent(board.GameEvent{
-		EventType: board.EVENT_TYPE_GAME_END,
-		Data:      boardGame,
-	})
-	// }
+	if boardServer != nil {
+		boardServer.SendEvent(board.GameEvent{
+			EventType: board.EVENT_TYPE_GAME_END,
+			Data:      boardGame,
+		})
+	}
 
 	if exportGame {
 		lines, err := gameExporter.FlushToFile(gameState.outputFile)
 		if err != nil {
 			return fmt.Errorf("unable to export game: %w", err)
 		}
-		log.INFO.Printf("Wrote %d lines to output file: %s", lines, gameState.OutputPath)
+		slog.InfoContext(ctx, "Wrote game output file", "lines", lines, "path", gameState.OutputPath)
 	}
 
-	return nil
+	return abortErr
 }
 
-func (gameState *GameState) initializeBoardFromArgs() (bool, *rules.BoardState, error) {
-	snakeIds := []string{}
-	for _, snakeState := range gameState.snakeStates {
-		snakeIds = append(snakeIds, snakeState.ID)
+// snakeResults reports the final state of every snake, in the order the
+// snakes were given.
+func (gameState *GameState) snakeResults(boardState *rules.BoardState) []SnakeResult {
+	results := make([]SnakeResult, 0, len(gameState.snakeIDs))
+	for _, id := range gameState.snakeIDs {
+		snakeState := gameState.snakeStates[id]
+		result := SnakeResult{
+			Name:         snakeState.Name,
+			URL:          snakeState.URL,
+			Latencies:    gameState.latencies[id],
+			Timeouts:     snakeState.Timeouts,
+			StatusErrors: snakeState.StatusErrors,
+			DecodeErrors: snakeState.DecodeErrors,
+			Squad:        snakeState.Squad,
+		}
+		for _, snake := range boardState.Snakes {
+			if snake.ID == id {
+				result.Length = len(snake.Body)
+				result.EliminatedCause = snake.EliminatedCause
+				result.EliminatedOnTurn = snake.EliminatedOnTurn
+				break
+			}
+		}
+		results = append(results, result)
 	}
-	boardState, err := maps.SetupBoard(gameState.gameMap.ID(), gameState.ruleset.Settings(), gameState.Width, gameState.Height, snakeIds)
+	return results
+}
+
+// ID returns the game ID generated by Initialize.
+func (gameState *GameState) ID() string {
+	return gameState.gameID
+}
+
+// Result returns the outcome of the game once Run has returned.
+func (gameState *GameState) Result() Result {
+	return gameState.result
+}
+
+func (gameState *GameState) initializeBoardFromArgs(ctx context.Context) (bool, *rules.BoardState, error) {
+	// the snake order decides the start positions, so it must not come from
+	// iterating over snakeStates
+	boardState := rules.NewBoardState(gameState.Width, gameState.Height)
+	rules.InitializeSnakes(boardState, gameState.snakeIDs)
+	err := gameState.gameMap.SetupBoard(boardState, gameState.ruleset.Settings(), maps.NewBoardStateEditor(boardState))
 	if err != nil {
 		return false, nil, fmt.Errorf("error initializing BoardState with map: %w", err)
 	}
@@ -279,34 +525,43 @@ func (gameState *GameState) initializeBoardFromArgs() (bool, *rules.BoardState,
 		return false, nil, fmt.Errorf("error initializing BoardState with ruleset: %w", err)
 	}
 
-	for _, snakeState := range gameState.snakeStates {
+	for _, id := range gameState.snakeIDs {
+		snakeState := gameState.snakeStates[id]
 		snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-		requestBody := serialiseSnakeRequest(snakeRequest)
-		u, _ := url.ParseRequestURI(snakeState.URL)
-		u.Path = path.Join(u.Path, "start")
-		log.DEBUG.Printf("POST %s: %v", u, string(requestBody))
-		_, _, err = gameState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
-		if err != nil {
-			log.WARN.Printf("Request to %v failed", u.String())
+		snakeCtx, cancel := context.WithTimeout(snakeLogContext(ctx, snakeState), gameState.requestTimeout())
+		if err := gameState.providers[id].Start(snakeCtx, snakeRequest); err != nil {
+			slog.WarnContext(snakeCtx, "Start request failed", "url", snakeState.URL, "err", err)
 		}
+		cancel()
 	}
 	return gameOver, boardState, nil
 }
 
-func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (bool, *rules.BoardState, error) {
+// createNextBoardState plays a turn of the game. It returns whether the game
+// is over and whether the turn was played: the ruleset detects the end of the
+// game before moving the snakes, so the turn that ends it is not played,
+// unless the snakes eliminated for their timeouts end it.
+func (gameState *GameState) createNextBoardState(ctx context.Context, boardState *rules.BoardState) (bool, bool, *rules.BoardState, error) {
+	// the ruleset ends the game when one snake is left, a squad game ends
+	// when one squad is
+	if gameState.SquadOptions.enabled() && len(gameState.aliveSquads(boardState)) <= 1 {
+		return true, false, boardState, nil
+	}
+
 	// apply PreUpdateBoard before making requests to snakes
 	boardState, err := maps.PreUpdateBoard(gameState.gameMap, boardState, gameState.ruleset.Settings())
 	if err != nil {
-		return false, boardState, fmt.Errorf("error pre-updating board with game map: %w", err)
+		return false, false, boardState, fmt.Errorf("error pre-updating board with game map: %w", err)
 	}
 
 	// get moves from snakes
 	stateUpdates := make(chan SnakeState, len(gameState.snakeStates))
 	if gameState.Sequential {
-		for _, snakeState := range gameState.snakeStates {
+		for _, id := range gameState.snakeIDs {
+			snakeState := gameState.snakeStates[id]
 			for _, snake := range boardState.Snakes {
 				if snakeState.ID == snake.ID && snake.EliminatedCause == rules.NotEliminated {
-					nextSnakeState := gameState.getSnakeUpdate(boardState, snakeState)
+					nextSnakeState := gameState.getSnakeUpdate(ctx, boardState, snakeState)
 					stateUpdates <- nextSnakeState
 				}
 			}
@@ -315,13 +570,14 @@ func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (
 	} else {
 		var wg sync.WaitGroup
 
-		for _, snakeState := range gameState.snakeStates {
+		for _, id := range gameState.snakeIDs {
+			snakeState := gameState.snakeStates[id]
 			for _, snake := range boardState.Snakes {
 				if snakeState.ID == snake.ID && snake.EliminatedCause == rules.NotEliminated {
 					wg.Add(1)
 					go func(snakeState SnakeState) {
 						defer wg.Done()
-						nextSnakeState := gameState.getSnakeUpdate(boardState, snakeState)
+						nextSnakeState := gameState.getSnakeUpdate(ctx, boardState, snakeState)
 						stateUpdates <- nextSnakeState
 					}(snakeState)
 				}
@@ -332,119 +588,149 @@ func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (
 		close(stateUpdates)
 	}
 
-	var moves []rules.SnakeMove
+	if ctx.Err() != nil {
+		return false, false, boardState, context.Cause(ctx)
+	}
+
+	moved := map[string]bool{}
 	for snakeState := range stateUpdates {
+		observeMove(gameState.snakeStates[snakeState.ID], snakeState)
 		gameState.snakeStates[snakeState.ID] = snakeState
-		moves = append(moves, rules.SnakeMove{ID: snakeState.ID, Move: snakeState.LastMove})
+		gameState.latencies[snakeState.ID] = append(gameState.latencies[snakeState.ID], snakeState.Latency)
+		moved[snakeState.ID] = true
+	}
+	// responses arrive in any order, the moves are applied in snake order
+	var moves []rules.SnakeMove
+	timedOut := false
+	for _, id := range gameState.snakeIDs {
+		if !moved[id] {
+			continue
+		}
+		snakeState := gameState.snakeStates[id]
+		if snakeState.TimedOut && gameState.applyTimeoutPolicy(boardState, &snakeState) {
+			for i := range boardState.Snakes {
+				if boardState.Snakes[i].ID == id {
+					rules.EliminateSnake(&boardState.Snakes[i], EliminatedByTimeout, "", boardState.Turn+1)
+				}
+			}
+			slog.WarnContext(snakeLogContext(ctx, snakeState), "Snake eliminated after consecutive timeouts", "timeouts", snakeState.ConsecutiveTimeouts)
+			gameState.snakeStates[id] = snakeState
+			timedOut = true
+			continue
+		}
+		gameState.snakeStates[id] = snakeState
+		moves = append(moves, rules.SnakeMove{ID: id, Move: snakeState.LastMove})
 	}
 
-	gameOver, boardState, err := gameState.ruleset.Execute(boardState, moves)
+	gameOver, nextBoardState, err := gameState.ruleset.Execute(boardState, moves)
 	if err != nil {
-		return false, boardState, fmt.Errorf("error updating board state from ruleset: %w", err)
+		return false, false, nextBoardState, fmt.Errorf("error updating board state from ruleset: %w", err)
+	}
+	if gameOver && timedOut {
+		// the game over check comes first in the pipeline, which ends the
+		// game on the eliminations without moving anyone: they make the
+		// last turn
+		nextBoardState.Turn += 1
+		return true, true, nextBoardState, nil
+	}
+	boardState = nextBoardState
+	if m, ok := gameState.gameMap.(customGameMap); ok {
+		for _, id := range m.eliminateWallCollisions(boardState) {
+			slog.InfoContext(snakeLogContext(ctx, gameState.snakeStates[id]), "Snake ran into a wall")
+		}
+	}
+	if gameState.SquadOptions.enabled() {
+		for _, id := range gameState.applySquadRules(boardState) {
+			slog.InfoContext(snakeLogContext(ctx, gameState.snakeStates[id]), "Snake eliminated with its squad", "squad", gameState.snakeStates[id].Squad)
+		}
 	}
 
 	// apply PostUpdateBoard after ruleset operates on snake moves
 	boardState, err = maps.PostUpdateBoard(gameState.gameMap, boardState, gameState.ruleset.Settings())
 	if err != nil {
-		return false, boardState, fmt.Errorf("error post-updating board with game map: %w", err)
+		return false, false, boardState, fmt.Errorf("error post-updating board with game map: %w", err)
 	}
 
 	boardState.Turn += 1
 
-	return gameOver, boardState, nil
+	return gameOver, !gameOver, boardState, nil
 }
 
-func (gameState *GameState) getSnakeUpdate(boardState *rules.BoardState, snakeState SnakeState) SnakeState {
+func (gameState *GameState) getSnakeUpdate(ctx context.Context, boardState *rules.BoardState, snakeState SnakeState) SnakeState {
 	snakeState.StatusCode = 0
 	snakeState.Error = nil
 	snakeState.Latency = 0
+	ctx = snakeLogContext(ctx, snakeState)
 
 	snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-	requestBody := serialiseSnakeRequest(snakeRequest)
+	// the shout only lasts one turn, the request above still carries it
+	snakeState.Shout = ""
 
-	u, err := url.ParseRequestURI(snakeState.URL)
-	if err != nil {
-		log.ERROR.Printf("Error parsing snake URL %#v: %v", snakeState.URL, err)
-		snakeState.Error = err
-		return snakeState
-	}
-	u.Path = path.Join(u.Path, "move")
-	log.DEBUG.Printf("POST %s: %v", u, string(requestBody))
-	res, responseTime, err := gameState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
+	moveCtx, cancel := context.WithTimeout(ctx, gameState.moveDeadline(snakeState))
+	defer cancel()
+	playerResponse, responseTime, err := gameState.providers[snakeState.ID].Move(moveCtx, snakeRequest)
 
 	snakeState.Latency = responseTime
-
-	if err != nil {
-		log.WARN.Printf(
-			"Request to %v failed\n"+
-				"\tError: %s", u.String(), err)
-		snakeState.Error = err
-		return snakeState
-	}
-
-	snakeState.StatusCode = res.StatusCode
-
-	if res.Body == nil {
-		log.WARN.Printf(
-			"Failed to parse response from %v\n"+
-				"\tError: body is empty", u.String())
+	gameState.chargeTimeBank(&snakeState, err)
+
+	var statusErr *StatusError
+	var decodeErr *DecodeError
+	switch {
+	case errors.As(err, &statusErr):
+		snakeState.StatusCode = statusErr.Code
+		snakeState.StatusErrors++
+		slog.WarnContext(ctx, "Got non-ok status code", "url", snakeState.URL, "status", statusErr.Code, "body", statusErr.Body)
 		return snakeState
-	}
-	defer res.Body.Close()
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		log.WARN.Printf(
-			"Failed to read response body from %v\n"+
-				"\tError: %v", u.String(), readErr)
-		snakeState.Error = readErr
+	case errors.As(err, &decodeErr):
+		snakeState.StatusCode = http.StatusOK
+		snakeState.DecodeErrors++
+		slog.WarnContext(ctx, "Failed to decode JSON, see https://docs.battlesnake.com/references/api#post-move", "url", snakeState.URL, "err", decodeErr.Err, "body", decodeErr.Body)
+		snakeState.Error = err
 		return snakeState
-	}
-	if res.StatusCode != http.StatusOK {
-		log.WARN.Printf(
-			"Got non-ok status code from %v\n"+
-				"\tStatusCode: %d (expected %d)\n"+
-				"\tBody: %q", u.String(), res.StatusCode, http.StatusOK, body)
+	case err != nil:
+		slog.WarnContext(ctx, "Request failed", "url", snakeState.URL, "err", err)
+		snakeState.Error = err
 		return snakeState
 	}
+	snakeState.StatusCode = http.StatusOK
 
-	playerResponse := client.MoveResponse{}
-	jsonErr := json.Unmarshal(body, &playerResponse)
-	if jsonErr != nil {
-		log.WARN.Printf(
-			"Failed to decode JSON from %v\n"+
-				"\tError: %v\n"+
-				"\tBody: %q\n"+
-				"\tSee https://docs.battlesnake.com/references/api#post-move", u.String(), jsonErr, body)
-		snakeState.Error = jsonErr
-		return snakeState
-	}
 	if playerResponse.Move != "up" && playerResponse.Move != "down" && playerResponse.Move != "left" && playerResponse.Move != "right" {
-		log.WARN.Printf(
-			"Failed to parse JSON data from %v\n"+
-				"\tError: invalid move %q, valid moves are \"up\", \"down\", \"left\" or \"right\"\n"+
-				"\tBody: %q\n"+
-				"\tSee https://docs.battlesnake.com/references/api#post-move", u.String(), playerResponse.Move, body)
+		snakeState.DecodeErrors++
+		slog.WarnContext(ctx, "Invalid move, valid moves are up, down, left or right", "url", snakeState.URL, "move", playerResponse.Move)
 		return snakeState
 	}
 
 	snakeState.LastMove = playerResponse.Move
+	snakeState.Shout = sanitizeShout(playerResponse.Shout)
+	if len(snakeState.Shout) < len(playerResponse.Shout) {
+		slog.DebugContext(ctx, "Shout sanitised", "shout", playerResponse.Shout)
+	}
 
 	return snakeState
 }
 
-func (gameState *GameState) sendEndRequest(boardState *rules.BoardState, snakeState SnakeState) {
+func (gameState *GameState) sendEndRequest(ctx context.Context, boardState *rules.BoardState, snakeState SnakeState) {
 	snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-	requestBody := serialiseSnakeRequest(snakeRequest)
-	u, _ := url.ParseRequestURI(snakeState.URL)
-	u.Path = path.Join(u.Path, "end")
-	log.DEBUG.Printf("POST %s: %v", u, string(requestBody))
-	_, _, err := gameState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
-	if err != nil {
-		log.WARN.Printf("Request to %v failed", u.String())
+	ctx, cancel := context.WithTimeout(snakeLogContext(ctx, snakeState), gameState.requestTimeout())
+	defer cancel()
+	if err := gameState.providers[snakeState.ID].End(ctx, snakeRequest); err != nil {
+		slog.WarnContext(ctx, "End request failed", "url", snakeState.URL, "err", err)
 	}
 }
 
+// getRequestBodyForSnake builds the request sent to a snake, which only
+// shows what the snake sees when the game has fog of war.
 func (gameState *GameState) getRequestBodyForSnake(boardState *rules.BoardState, snakeState SnakeState) client.SnakeRequest {
+	request := gameState.getFullRequestBody(boardState, snakeState)
+	if gameState.FogOptions.enabled() {
+		request.Board = fogBoard(request.Board, snakeState.ID, gameState.visibleCells(boardState, snakeState.ID))
+	}
+	return request
+}
+
+// getFullRequestBody builds the request of a snake with the whole board, as
+// stored in the replays.
+func (gameState *GameState) getFullRequestBody(boardState *rules.BoardState, snakeState SnakeState) client.SnakeRequest {
 	var youSnake rules.Snake
 	for _, snk := range boardState.Snakes {
 		if snakeState.ID == snk.ID {
@@ -462,19 +748,21 @@ func (gameState *GameState) getRequestBodyForSnake(boardState *rules.BoardState,
 }
 
 func (gameState *GameState) createClientGame() client.Game {
+	settings := client.ConvertRulesetSettings(gameState.ruleset.Settings())
+	settings.SquadSettings = gameState.squadSettings()
 	return client.Game{
 		ID:      gameState.gameID,
 		Timeout: gameState.Timeout,
 		Ruleset: client.Ruleset{
 			Name:     gameState.ruleset.Name(),
 			Version:  "cli", // TODO: Use GitHub Release Version
-			Settings: client.ConvertRulesetSettings(gameState.ruleset.Settings()),
+			Settings: settings,
 		},
 		Map: gameState.gameMap.ID(),
 	}
 }
 
-func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, error) {
+func (gameState *GameState) buildSnakesFromOptions(ctx context.Context) (map[string]SnakeState, error) {
 	bodyChars := []rune{'■', '⌀', '●', '☻', '◘', '☺', '□', '⍟'}
 	var numSnakes int
 	snakes := map[string]SnakeState{}
@@ -493,52 +781,53 @@ func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, err
 		if gameState.idGenerator != nil {
 			id = gameState.idGenerator(i)
 		} else {
-			id = uuid.New().String()
+			// derived from the seed, so that a replayed game sends the same requests
+			uid, err := uuid.NewRandomFromReader(gameState.rand)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate snake ID: %w", err)
+			}
+			id = uid.String()
 		}
 
 		if i < numNames {
 			snakeName = gameState.Names[i]
 		} else {
-			log.DEBUG.Printf("Name for URL %v is missing: a name will be generated automatically", gameState.URLs[i])
+			slog.DebugContext(ctx, "Name for URL is missing, a name will be generated", "url", gameState.URLs[i])
 			snakeName = GenerateSnakeName()
 		}
 
-		if i < numURLs {
-			u, err := url.ParseRequestURI(gameState.URLs[i])
-			if err != nil {
-				return nil, fmt.Errorf("URL %v is not valid: %w", gameState.URLs[i], err)
-			}
-			snakeURL = u.String()
-		} else {
+		if i >= numURLs {
 			return nil, fmt.Errorf("URL for name %v is missing", gameState.Names[i])
 		}
-
-		snakeState := SnakeState{
-			Name: snakeName, URL: snakeURL, ID: id, LastMove: "up", Character: bodyChars[i%8],
+		snakeURL = gameState.URLs[i]
+		var provider MoveProvider
+		if i < len(gameState.Providers) {
+			provider = gameState.Providers[i]
 		}
-		// var snakeErr error
-		res, _, err := gameState.httpClient.Get(snakeURL)
-		if err != nil {
-			return nil, fmt.Errorf("snake metadata request to %v failed: %w", snakeURL, err)
+		if provider == nil {
+			var err error
+			if provider, err = gameState.newProvider(snakeURL); err != nil {
+				return nil, fmt.Errorf("URL %v is not valid: %w", snakeURL, err)
+			}
 		}
 
-		snakeState.StatusCode = res.StatusCode
-
-		if res.Body == nil {
-			return nil, fmt.Errorf("empty response body from snake metadata URL: %v", snakeURL)
+		snakeState := SnakeState{
+			Name: snakeName, URL: snakeURL, ID: id, LastMove: "up", Character: bodyChars[i%8],
+			TimeBank: time.Duration(max(gameState.TimeBank, 0)) * time.Millisecond,
 		}
-
-		defer res.Body.Close()
-		body, readErr := io.ReadAll(res.Body)
-		if readErr != nil {
-			return nil, fmt.Errorf("error reading from snake metadata URL %v: %w", snakeURL, readErr)
+		if gameState.SquadOptions.enabled() {
+			snakeState.Squad = gameState.Squads[i]
 		}
-
-		pingResponse := client.SnakeMetadataResponse{}
-		jsonErr := json.Unmarshal(body, &pingResponse)
-		if jsonErr != nil {
-			return nil, fmt.Errorf("failed to parse response from %v: %w", snakeURL, jsonErr)
+		// the provider is kept first, so that its process is stopped even
+		// when the snake does not answer
+		gameState.providers[id] = provider
+		infoCtx, cancel := context.WithTimeout(ctx, gameState.requestTimeout())
+		pingResponse, err := provider.Info(infoCtx)
+		cancel()
+		if err != nil {
+			return nil, err
 		}
+		snakeState.StatusCode = http.StatusOK
 
 		snakeState.Head = pingResponse.Head
 		snakeState.Tail = pingResponse.Tail
@@ -546,27 +835,36 @@ func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, err
 		snakeState.Author = pingResponse.Author
 		snakeState.Version = pingResponse.Version
 
-		// if snakeErr != nil {
-		// 	snakeState.Error = snakeErr
-		// }
-
 		snakes[snakeState.ID] = snakeState
+		gameState.snakeIDs = append(gameState.snakeIDs, snakeState.ID)
 
-		log.INFO.Printf("Snake ID: %v URL: %v, Name: \"%v\"", snakeState.ID, snakeURL, snakeState.Name)
+		slog.InfoContext(snakeLogContext(ctx, snakeState), "Snake joined", "url", snakeURL)
 	}
 	return snakes, nil
 }
 
-func (gameState *GameState) printState(boardState *rules.BoardState) {
+func (gameState *GameState) printBoard(ctx context.Context, boardState *rules.BoardState) {
+	switch {
+	case gameState.Headless:
+	case gameState.ViewMap:
+		gameState.printMap(boardState)
+	default:
+		gameState.printState(ctx, boardState)
+	}
+}
+
+func (gameState *GameState) printState(ctx context.Context, boardState *rules.BoardState) {
 	var aliveSnakeNames []string
 	for _, snake := range boardState.Snakes {
 		if snake.EliminatedCause == rules.NotEliminated {
 			aliveSnakeNames = append(aliveSnakeNames, gameState.snakeStates[snake.ID].Name)
 		}
 	}
-	log.INFO.Printf(
-		"Turn: %d, Snakes Alive: [%v], Food: %d, Hazards: %d",
-		boardState.Turn, strings.Join(aliveSnakeNames, ", "), len(boardState.Food), len(boardState.Hazards),
+	slog.InfoContext(ctx, "Turn",
+		"turn", boardState.Turn,
+		"alive", strings.Join(aliveSnakeNames, ", "),
+		"food", len(boardState.Food),
+		"hazards", len(boardState.Hazards),
 	)
 }
 
@@ -649,8 +947,35 @@ func (gameState *GameState) printMap(boardState *rules.BoardState) {
 	fmt.Println(o.String())
 }
 
+// gameFrame is a board.GameFrame with the timeout policy of the game.
+type gameFrame struct {
+	Turn          int           `json:"Turn"`
+	Snakes        []frameSnake  `json:"Snakes"`
+	Food          []rules.Point `json:"Food"`
+	Hazards       []rules.Point `json:"Hazards"`
+	TimeoutPolicy TimeoutPolicy `json:"TimeoutPolicy"`
+	// Fog is set in games with fog of war.
+	Fog *frameFog `json:"Fog,omitempty"`
+}
+
+type frameFog struct {
+	Mode         FogMode `json:"Mode"`
+	VisionRadius int     `json:"VisionRadius"`
+}
+
+// frameSnake is a board.Snake with its timeout counts and time bank.
+type frameSnake struct {
+	board.Snake
+	TimedOut            bool  `json:"TimedOut"`
+	Timeouts            int   `json:"Timeouts"`
+	ConsecutiveTimeouts int   `json:"ConsecutiveTimeouts"`
+	TimeBank            int64 `json:"TimeBank"`
+	// Vision holds the cells the snake sees, with FogOptions.VisionOverlay.
+	Vision []rules.Point `json:"Vision,omitempty"`
+}
+
 func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.GameEvent {
-	snakes := []board.Snake{}
+	snakes := []frameSnake{}
 
 	for _, snake := range boardState.Snakes {
 		snakeState := gameState.snakeStates[snake.ID]
@@ -673,6 +998,8 @@ func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.
 			IsBot:         false,
 			IsEnvironment: false,
 			Latency:       fmt.Sprint(latencyMS),
+			Shout:         snakeState.Shout,
+			Squad:         snakeState.Squad,
 		}
 		if snakeState.Error != nil {
 			// Instead of trying to keep in sync with the production engine's
@@ -689,14 +1016,28 @@ func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.
 				EliminatedBy: snake.EliminatedBy,
 			}
 		}
-		snakes = append(snakes, convertedSnake)
+		frameSnake := frameSnake{
+			Snake:               convertedSnake,
+			TimedOut:            snakeState.TimedOut,
+			Timeouts:            snakeState.Timeouts,
+			ConsecutiveTimeouts: snakeState.ConsecutiveTimeouts,
+			TimeBank:            snakeState.TimeBank.Milliseconds(),
+		}
+		if gameState.FogOptions.enabled() && gameState.VisionOverlay && snake.EliminatedCause == rules.NotEliminated {
+			frameSnake.Vision = sortedPoints(gameState.visibleCells(boardState, snake.ID))
+		}
+		snakes = append(snakes, frameSnake)
 	}
 
-	gameFrame := board.GameFrame{
-		Turn:    boardState.Turn,
-		Snakes:  snakes,
-		Food:    boardState.Food,
-		Hazards: boardState.Hazards,
+	gameFrame := gameFrame{
+		Turn:          boardState.Turn,
+		Snakes:        snakes,
+		Food:          boardState.Food,
+		Hazards:       boardState.Hazards,
+		TimeoutPolicy: gameState.TimeoutPolicy,
+	}
+	if gameState.FogOptions.enabled() {
+		gameFrame.Fog = &frameFog{Mode: gameState.Fog, VisionRadius: gameState.radius()}
 	}
 
 	return board.GameEvent{
@@ -709,7 +1050,7 @@ func serialiseSnakeRequest(snakeRequest client.SnakeRequest) []byte {
 	requestJSON, err := json.Marshal(snakeRequest)
 	if err != nil {
 		// This is likely to be a programming error like a unsupported type or cyclical reference
-		log.ERROR.Panicf("Error marshalling JSON from State: %v", err)
+		panic(fmt.Sprintf("Error marshalling JSON from State: %v", err))
 	}
 	return requestJSON
 }
@@ -724,7 +1065,8 @@ func convertRulesSnake(snake rules.Snake, snakeState SnakeState) client.Snake {
 		Latency: fmt.Sprint(latencyMS),
 		Head:    client.CoordFromPoint(snake.Body[0]),
 		Length:  int(len(snake.Body)),
-		Shout:   "",
+		Shout:   snakeState.Shout,
+		Squad:   snakeState.Squad,
 		Customizations: client.Customizations{
 			Head:  snakeState.Head,
 			Tail:  snakeState.Tail,
@@ -767,3 +1109,8 @@ func parseSnakeColor(color string) (int64, int64, int64) {
 	// Default gray color from Battlesnake board
 	return 136, 136, 136
 }
+
+// snakeLogContext adds the ID and name of a snake to the log records of ctx.
+func snakeLogContext(ctx context.Context, snakeState SnakeState) context.Context {
+	return logging.With(ctx, "snake_id", snakeState.ID, "snake", snakeState.Name)
+}
@@ -0,0 +1,209 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// MoveProvider plays a snake: it answers the requests the engine would send
+// to a snake server, over HTTP or otherwise. The snakes of a game may each
+// use a different kind of provider.
+type MoveProvider interface {
+	// Info returns the metadata of the snake, as GET /.
+	Info(ctx context.Context) (client.SnakeMetadataResponse, error)
+	// Start tells the snake a game begins, as POST /start.
+	Start(ctx context.Context, request client.SnakeRequest) error
+	// Move asks the snake for its move before the deadline of ctx, as POST
+	// /move. It returns how long the snake took to answer.
+	Move(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, time.Duration, error)
+	// End tells the snake the game is over, as POST /end.
+	End(ctx context.Context, request client.SnakeRequest) error
+}
+
+// StatusError is returned by the providers of snakes that answered a request
+// with an error status.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("got status %d", e.Code)
+}
+
+// DecodeError is returned by the providers of snakes whose answer is not
+// valid JSON.
+type DecodeError struct {
+	Err  error
+	Body string
+}
+
+func (e *DecodeError) Error() string {
+	return "failed to decode answer: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newProvider returns the provider of the snake at snakeURL: http and https
+// URLs are snake servers, the other schemes are played without HTTP, see
+// HumanURL, BotURL and StdioURLScheme.
+func (gameState *GameState) newProvider(snakeURL string) (MoveProvider, error) {
+	if command, ok := strings.CutPrefix(snakeURL, StdioURLScheme+":"); ok {
+		return newStdioCommandProvider(command)
+	}
+	u, err := url.ParseRequestURI(snakeURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return httpProvider{client: gameState.httpClient, url: u}, nil
+	case HumanURLScheme:
+		return humanProvider{players: gameState.humans, player: u.Host, timeout: time.Duration(gameState.Timeout) * time.Millisecond}, nil
+	case BotURLScheme:
+		bot, ok := bots[u.Host]
+		if !ok {
+			return nil, fmt.Errorf("unknown bot %q", u.Host)
+		}
+		return bot, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+}
+
+// closeProviders stops the providers with a process or a connection behind
+// them, once the game is over.
+func (gameState *GameState) closeProviders(ctx context.Context) {
+	for id, provider := range gameState.providers {
+		closer, ok := provider.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			slog.WarnContext(ctx, "Error stopping snake", "snake_id", id, "err", err)
+		}
+	}
+}
+
+// httpProvider plays a snake server through the game's HTTP client.
+type httpProvider struct {
+	client TimedHttpClient
+	url    *url.URL
+}
+
+func (p httpProvider) Info(ctx context.Context) (client.SnakeMetadataResponse, error) {
+	var metadata client.SnakeMetadataResponse
+	res, _, err := p.client.Get(ctx, p.url.String())
+	if err != nil {
+		return metadata, fmt.Errorf("snake metadata request to %v failed: %w", p.url, err)
+	}
+	if res.Body == nil {
+		return metadata, fmt.Errorf("empty response body from snake metadata URL: %v", p.url)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return metadata, fmt.Errorf("error reading from snake metadata URL %v: %w", p.url, err)
+	}
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse response from %v: %w", p.url, err)
+	}
+	return metadata, nil
+}
+
+func (p httpProvider) Start(ctx context.Context, request client.SnakeRequest) error {
+	_, _, err := p.post(ctx, "start", request)
+	return err
+}
+
+func (p httpProvider) Move(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, time.Duration, error) {
+	var move client.MoveResponse
+	res, latency, err := p.post(ctx, "move", request)
+	if err != nil {
+		return move, latency, err
+	}
+	if res.Body == nil {
+		return move, latency, &DecodeError{Err: io.ErrUnexpectedEOF}
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return move, latency, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return move, latency, &StatusError{Code: res.StatusCode, Body: string(body)}
+	}
+	if err := json.Unmarshal(body, &move); err != nil {
+		return move, latency, &DecodeError{Err: err, Body: string(body)}
+	}
+	return move, latency, nil
+}
+
+func (p httpProvider) End(ctx context.Context, request client.SnakeRequest) error {
+	_, _, err := p.post(ctx, "end", request)
+	return err
+}
+
+func (p httpProvider) post(ctx context.Context, endpoint string, request client.SnakeRequest) (*http.Response, time.Duration, error) {
+	u := *p.url
+	u.Path = path.Join(u.Path, endpoint)
+	requestBody := serialiseSnakeRequest(request)
+	slog.DebugContext(ctx, "POST", "url", u.String(), "body", string(requestBody))
+	return p.client.Post(ctx, u.String(), "application/json", bytes.NewBuffer(requestBody))
+}
+
+// MoveFunc plays a snake from the engine's process, see FuncProvider.
+type MoveFunc func(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, error)
+
+// FuncProvider plays a snake with a Go function, without HTTP: simulations
+// of in-process snakes run as fast as the rules.
+type FuncProvider struct {
+	Metadata client.SnakeMetadataResponse
+	MoveFunc MoveFunc
+}
+
+func (p FuncProvider) Info(ctx context.Context) (client.SnakeMetadataResponse, error) {
+	return p.Metadata, nil
+}
+
+func (p FuncProvider) Start(ctx context.Context, request client.SnakeRequest) error {
+	return nil
+}
+
+// Move runs MoveFunc, which is abandoned if it is still running at the
+// deadline of ctx.
+func (p FuncProvider) Move(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, time.Duration, error) {
+	type answer struct {
+		move client.MoveResponse
+		err  error
+	}
+	start := time.Now()
+	answered := make(chan answer, 1)
+	go func() {
+		move, err := p.MoveFunc(ctx, request)
+		answered <- answer{move, err}
+	}()
+	select {
+	case a := <-answered:
+		return a.move, time.Since(start), a.err
+	case <-ctx.Done():
+		return client.MoveResponse{}, time.Since(start), ctx.Err()
+	}
+}
+
+func (p FuncProvider) End(ctx context.Context, request client.SnakeRequest) error {
+	return nil
+}
@@ -0,0 +1,258 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// stdioSnakeEnv makes the test binary play a snake over its standard input
+// and output, see TestStdioSnakeProgram.
+const stdioSnakeEnv = "LOCALSNAKE_STDIO_SNAKE"
+
+// TestStdioSnakeProgram is the snake program of the stdio tests, run by
+// stdioSnakeCommand. It plays greedyMove, answering turn 2 late, never
+// reads its input when the variable is "deaf" and only answers the info
+// request when it is "info-only".
+func TestStdioSnakeProgram(t *testing.T) {
+	switch os.Getenv(stdioSnakeEnv) {
+	case "":
+		t.Skip("only run as a snake program")
+	case "deaf":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "info-only":
+		// reads everything, answers only info
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(nil, maxStdioLine)
+		for scanner.Scan() {
+			if bytes.Contains(scanner.Bytes(), []byte(`"type":"info"`)) {
+				fmt.Println(`{"apiversion": "1", "author": "stdio"}`)
+			}
+		}
+		os.Exit(0)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, maxStdioLine)
+	for scanner.Scan() {
+		var message stdioMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		switch message.Type {
+		case "info":
+			fmt.Println(`{"apiversion": "1", "author": "stdio"}`)
+		case "move":
+			if message.Request.Turn == 2 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			answer, _ := json.Marshal(client.MoveResponse{Move: greedyMove(*message.Request), Shout: fmt.Sprint(message.Request.Turn)})
+			fmt.Println(string(answer))
+		default:
+			fmt.Println("{}")
+		}
+	}
+	os.Exit(0)
+}
+
+func stdioSnakeCommand() *exec.Cmd {
+	return stdioSnakeModeCommand("1")
+}
+
+func stdioSnakeModeCommand(mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStdioSnakeProgram$")
+	cmd.Env = append(os.Environ(), stdioSnakeEnv+"="+mode)
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+func TestStdioProvider(t *testing.T) {
+	provider := NewStdioProvider(stdioSnakeCommand())
+	defer provider.Close()
+
+	ctx := context.Background()
+	metadata, err := provider.Info(ctx)
+	if err != nil || metadata.Author != "stdio" {
+		t.Fatalf("got metadata %+v and error %v, want the snake's", metadata, err)
+	}
+
+	request := client.SnakeRequest{
+		Board: client.Board{Width: 5, Height: 5},
+		You:   client.Snake{Head: client.Coord{X: 0, Y: 0}, Body: []client.Coord{{X: 0, Y: 0}}},
+	}
+	move := func(turn int, timeout time.Duration) (client.MoveResponse, error) {
+		moveCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		request.Turn = turn
+		response, _, err := provider.Move(moveCtx, request)
+		return response, err
+	}
+	if _, err := move(2, 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v for the late turn, want a timeout", err)
+	}
+	// the late answer of turn 2 is not taken for the answer of turn 3
+	response, err := move(3, time.Second)
+	if err != nil || response.Shout != "3" {
+		t.Errorf("got %+v and error %v, want the answer of turn 3", response, err)
+	}
+
+	if err := provider.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if _, err := move(4, time.Second); err == nil {
+		t.Error("the snake still answers once closed")
+	}
+}
+
+func TestStdioProviderDeafProgram(t *testing.T) {
+	cmd := stdioSnakeModeCommand("deaf")
+	provider := NewStdioProvider(cmd)
+
+	// requests bigger than the pipe, which fills up after the first one
+	request := client.SnakeRequest{Board: client.Board{Width: 5, Height: 5}}
+	for range 20000 {
+		request.Board.Food = append(request.Board.Food, client.Coord{X: 1, Y: 1})
+	}
+	for turn := range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		start := time.Now()
+		request.Turn = turn
+		_, _, err := provider.Move(ctx, request)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+			t.Fatalf("turn %d: got error %v after %v, want a timeout at the deadline", turn, err, time.Since(start))
+		}
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- provider.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(stdioStopTimeout + 2*time.Second):
+		t.Fatal("Close blocks behind the request the program does not read")
+	}
+	if cmd.ProcessState == nil {
+		t.Error("the program still runs once closed")
+	}
+}
+
+func TestSilentStdioSnakes(t *testing.T) {
+	for _, mode := range []string{"deaf", "info-only"} {
+		t.Run(mode, func(t *testing.T) {
+			gameState := &GameState{
+				Width:    7,
+				Height:   7,
+				Names:    []string{"silent", "bot"},
+				URLs:     []string{StdioURLScheme + ":" + os.Args[0], BotURL},
+				Timeout:  100,
+				GameType: "standard",
+				MapName:  "standard",
+				Seed:     42,
+				Headless: true,
+				Providers: []MoveProvider{
+					NewStdioProvider(stdioSnakeModeCommand(mode)),
+				},
+			}
+			done := make(chan error, 1)
+			go func() {
+				err := gameState.Initialize()
+				if err == nil {
+					err = gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil)
+				}
+				done <- err
+			}()
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("the game hangs on a snake that does not answer")
+			}
+			// a snake without info cannot play, one without the other
+			// answers times out
+			if mode == "deaf" && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got error %v, want the info request timed out", err)
+			}
+			if mode == "info-only" && err != nil {
+				t.Errorf("got error %v, want the game played", err)
+			}
+			gameState.closeProviders(context.Background())
+		})
+	}
+}
+
+func TestMixedProviders(t *testing.T) {
+	var funcTurns []int
+	gameState := &GameState{
+		Width:    7,
+		Height:   7,
+		Names:    []string{"func", "stdio", "bot"},
+		URLs:     []string{"func://counter", StdioURLScheme + ":" + os.Args[0], BotURL},
+		Timeout:  500,
+		GameType: "standard",
+		MapName:  "standard",
+		Seed:     42,
+		Headless: true,
+		Providers: []MoveProvider{
+			FuncProvider{MoveFunc: func(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, error) {
+				funcTurns = append(funcTurns, request.Turn)
+				return client.MoveResponse{Move: greedyMove(request)}, nil
+			}},
+			NewStdioProvider(stdioSnakeCommand()),
+		},
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	result := gameState.Result()
+	if len(funcTurns) == 0 || funcTurns[0] != 0 {
+		t.Errorf("the Go function played turns %v, want it to play from turn 0", funcTurns)
+	}
+	if len(result.Snakes[1].Latencies) == 0 || result.Snakes[1].DecodeErrors > 0 {
+		t.Errorf("the stdio snake answered %d moves with %d errors, want it to play", len(result.Snakes[1].Latencies), result.Snakes[1].DecodeErrors)
+	}
+	if stdio := gameState.providers[gameState.snakeIDs[1]].(*StdioProvider); stdio.cmd.ProcessState == nil {
+		t.Error("the stdio snake still runs after the game")
+	}
+}
+
+func TestCreateGameInvalidSettings(t *testing.T) {
+	stdio := NewStdioProvider(stdioSnakeCommand())
+	snakes := []Snake{
+		{Name: "stdio", URL: StdioURLScheme + ":" + os.Args[0], Provider: stdio},
+		{Name: "bot", URL: BotURL},
+	}
+	ended := false
+	options := []CreateOptions{
+		{Fog: FogOptions{Fog: "thick"}},
+		{Map: "no-such-map"},
+		{Timeouts: TimeoutOptions{TimeoutPolicy: "forgive"}},
+	}
+	for _, opts := range options {
+		opts.OnEnd = func(Result) { ended = true }
+		gameState, boardServer, err := CreateGame(context.Background(), snakes, opts)
+		if err == nil || gameState != nil || boardServer != nil {
+			t.Errorf("%+v: got game %v and error %v, want the game refused", opts, gameState, err)
+		}
+	}
+	if ended {
+		t.Error("a refused game ended")
+	}
+	if _, err := stdio.Info(context.Background()); !errors.Is(err, errStdioExited) {
+		t.Errorf("got error %v asking the snake of a refused game, want its provider closed", err)
+	}
+}
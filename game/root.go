@@ -1,46 +1,147 @@
 package game
 
 import (
-	stdlog "log"
-	"os"
+	"context"
+	"io"
+	"log/slog"
 	"time"
 
 	"github.com/BattlesnakeOfficial/rules"
 	"github.com/BattlesnakeOfficial/rules/board"
-	log "github.com/spf13/jwalterweatherman"
 )
 
-var verbose bool
-
 type Snake struct {
 	Name string
 	URL  string
+	// SnakeID is the database ID of an uploaded snake, zero for external URLs.
+	SnakeID int64
+	// Squad groups the snake with the snakes of the same squad. Either every
+	// snake of a game has a squad or none does.
+	Squad string
+	// Provider, when set, plays the snake in place of its URL, which is
+	// still recorded. See GameState.Providers.
+	Provider MoveProvider
+}
+
+// Result summarises how a game started with CreateGame ended.
+type Result struct {
+	GameID     string
+	Turns      int
+	WinnerName string
+	WinnerURL  string
+	// WinnerSquad is the squad left at the end of squad games, which have no
+	// winner snake.
+	WinnerSquad string
+	IsDraw      bool
+	// Snakes holds the final state of every snake, in the order they were
+	// given to the game.
+	Snakes        []SnakeResult
+	TimeoutPolicy TimeoutPolicy
+	Err           error
 }
 
-func CreateGame(snakes []Snake) (string, *BoardServer) {
-	verbose = true
+// SnakeResult is the final state of a snake in a game.
+type SnakeResult struct {
+	Name string
+	URL  string
+	// Length is the length of the snake when the game ended or when it was
+	// eliminated.
+	Length           int
+	EliminatedCause  string
+	EliminatedOnTurn int
+	// Latencies holds the response time of every move request.
+	Latencies []time.Duration
+	// Timeouts counts the move requests that took too long.
+	Timeouts int
+	// StatusErrors counts the move answers with a status other than 200,
+	// DecodeErrors the ones without a valid JSON move.
+	StatusErrors int
+	DecodeErrors int
+	// Squad is the squad of the snake in squad games.
+	Squad string
+}
+
+// DefaultMoveTimeout is the /move timeout, in milliseconds, of the games
+// started with CreateGame.
+const DefaultMoveTimeout = 500
+
+// CreateOptions customises games started with CreateGame.
+type CreateOptions struct {
+	// OutputDir, when set, receives a JSONL replay named after the game ID.
+	OutputDir string
+	// OnEnd is called from the game goroutine once the game is over.
+	OnEnd func(Result)
+	// Seed of the game, a time based seed when zero. Given the same seed and
+	// the same snakes in the same order, deterministic snakes play the same
+	// game again.
+	Seed int64
+	// Timeouts configures how snakes that answer /move late are treated.
+	Timeouts TimeoutOptions
+	// Map is the ID of the map of the game, the standard map when empty.
+	// Custom maps bring their own board size.
+	Map string
+	// Fog configures fog of war, off when empty.
+	Fog FogOptions
+	// SquadRules apply when the snakes have squads.
+	SquadRules SquadRules
+}
+
+// CreateGame starts a game in its own goroutine. The game is aborted when ctx
+// is cancelled, see GameState.Run. It fails, starting nothing, when the
+// settings of the game are not valid; the providers of the snakes are then
+// closed and OnEnd is not called.
+func CreateGame(ctx context.Context, snakes []Snake, opts CreateOptions) (*GameState, *BoardServer, error) {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+	mapName := opts.Map
+	if mapName == "" {
+		mapName = "standard"
+	}
 
 	gameState := &GameState{
 		Width:           11,
 		Height:          11,
 		Names:           make([]string, len(snakes)),
 		URLs:            make([]string, len(snakes)),
-		Timeout:         500,
+		Providers:       make([]MoveProvider, len(snakes)),
+		Timeout:         DefaultMoveTimeout,
 		GameType:        "standard",
-		MapName:         "standard",
-		Seed:            time.Now().UTC().UnixNano(),
+		MapName:         mapName,
+		Seed:            seed,
 		TurnDelay:       0,
 		TurnDuration:    0,
 		FoodSpawnChance: 15,
+		OutputDir:       opts.OutputDir,
+		TimeoutOptions:  opts.Timeouts,
+		FogOptions:      opts.Fog,
+		SquadOptions:    SquadOptions{SquadRules: opts.SquadRules},
+		// the games are watched on the board, a turn log would flood the
+		// server log
+		Headless: true,
 	}
 
 	for i, snake := range snakes {
 		gameState.Names[i] = snake.Name
 		gameState.URLs[i] = snake.URL
+		gameState.Providers[i] = snake.Provider
+		if snake.Squad != "" {
+			gameState.Squads = append(gameState.Squads, snake.Squad)
+		}
+	}
+
+	if HasHumans(gameState.URLs) {
+		gameState.Timeout = HumanMoveTimeout
 	}
 
 	if err := gameState.Initialize(); err != nil {
-		log.ERROR.Printf("Error initializing game: %v", err)
+		for _, snake := range snakes {
+			if closer, ok := snake.Provider.(io.Closer); ok {
+				closer.Close()
+			}
+		}
+		return nil, nil, err
 	}
 
 	boardGame := board.Game{
@@ -59,26 +160,17 @@ func CreateGame(snakes []Snake) (string, *BoardServer) {
 
 	go func() {
 		defer boardServer.Shutdown()
-		if err := gameState.Run(boardGame, boardServer); err != nil {
-			log.ERROR.Printf("Error running game: %v", err)
+		err := gameState.Run(ctx, boardGame, boardServer)
+		if err != nil {
+			slog.Error("Error running game", "game_id", gameState.gameID, "err", err)
+		}
+		if opts.OnEnd != nil {
+			result := gameState.Result()
+			result.GameID = gameState.gameID
+			result.Err = err
+			opts.OnEnd(result)
 		}
 	}()
 
-	return gameState.gameID, boardServer
-}
-
-func init() {
-	initConfig()
-}
-
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	// Setup logging
-	log.SetStdoutOutput(os.Stderr)
-	log.SetFlags(stdlog.Ltime | stdlog.Lmicroseconds)
-	if verbose {
-		log.SetStdoutThreshold(log.LevelDebug)
-	} else {
-		log.SetStdoutThreshold(log.LevelDebug)
-	}
+	return gameState, boardServer, nil
 }
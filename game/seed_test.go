@@ -0,0 +1,120 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// greedySnakeClient answers every snake with the first move that does not
+// hit a wall or a body, so that the snakes are deterministic.
+type greedySnakeClient struct{}
+
+func (greedySnakeClient) Get(ctx context.Context, url string) (*http.Response, time.Duration, error) {
+	return stubResponse(`{"apiversion": "1"}`), time.Millisecond, nil
+}
+
+func (greedySnakeClient) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+	if !strings.HasSuffix(url, "/move") {
+		return stubResponse(`{}`), time.Millisecond, nil
+	}
+
+	var request client.SnakeRequest
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		return nil, 0, err
+	}
+	occupied := map[client.Coord]bool{}
+	for _, snake := range request.Board.Snakes {
+		for _, c := range snake.Body {
+			occupied[c] = true
+		}
+	}
+
+	head := request.You.Head
+	move := "up"
+	for _, m := range []struct {
+		name string
+		to   client.Coord
+	}{
+		{"up", client.Coord{X: head.X, Y: head.Y + 1}},
+		{"right", client.Coord{X: head.X + 1, Y: head.Y}},
+		{"down", client.Coord{X: head.X, Y: head.Y - 1}},
+		{"left", client.Coord{X: head.X - 1, Y: head.Y}},
+	} {
+		if m.to.X >= 0 && m.to.Y >= 0 && m.to.X < request.Board.Width && m.to.Y < request.Board.Height && !occupied[m.to] {
+			move = m.name
+			break
+		}
+	}
+	return stubResponse(`{"move": "` + move + `"}`), time.Millisecond, nil
+}
+
+func stubResponse(body string) *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+// playSeededGame plays a game with the greedy snakes and returns its replay.
+func playSeededGame(t *testing.T, seed int64, sequential bool) []byte {
+	t.Helper()
+
+	output := filepath.Join(t.TempDir(), "game.jsonl")
+	gameState := &GameState{
+		Width:               11,
+		Height:              11,
+		Names:               []string{"one", "two", "three", "four"},
+		URLs:                []string{"http://one", "http://two", "http://three", "http://four"},
+		Timeout:             500,
+		Sequential:          sequential,
+		GameType:            "standard",
+		MapName:             "standard",
+		Seed:                seed,
+		OutputPath:          output,
+		FoodSpawnChance:     15,
+		MinimumFood:         1,
+		HazardDamagePerTurn: 14,
+		ShrinkEveryNTurns:   25,
+		Headless:            true,
+		GameID:              "seeded-game",
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	gameState.httpClient = greedySnakeClient{}
+
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	replay, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("reading replay: %v", err)
+	}
+	return replay
+}
+
+func TestSeededGamesAreReproducible(t *testing.T) {
+	for _, sequential := range []bool{false, true} {
+		first := playSeededGame(t, 42, sequential)
+		for range 5 {
+			if again := playSeededGame(t, 42, sequential); !bytes.Equal(first, again) {
+				t.Fatalf("sequential=%v: the same seed played a different game", sequential)
+			}
+		}
+	}
+}
+
+func TestSeedChangesTheGame(t *testing.T) {
+	if bytes.Equal(playSeededGame(t, 42, false), playSeededGame(t, 43, false)) {
+		t.Fatal("different seeds played the same game")
+	}
+}
@@ -0,0 +1,38 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxShoutLength bounds the shouts, in characters, as on the Battlesnake
+// platform. Longer shouts are cut.
+const MaxShoutLength = 256
+
+// sanitizeShout makes the shout of a /move answer safe to pass on to the
+// other snakes and to the viewer: invalid UTF-8, control and formatting
+// characters are dropped, white space runs become a single space and the
+// shout is cut to MaxShoutLength characters.
+func sanitizeShout(shout string) string {
+	var runes []rune
+	space := false
+	for _, r := range strings.ToValidUTF8(shout, "") {
+		switch {
+		case unicode.IsSpace(r):
+			space = len(runes) > 0
+		// the zero width joiner builds emoji, the other formatting
+		// characters can reorder or hide text
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r) && r != '\u200d':
+		default:
+			if space {
+				runes = append(runes, ' ')
+				space = false
+			}
+			runes = append(runes, r)
+		}
+		if len(runes) >= MaxShoutLength {
+			break
+		}
+	}
+	return strings.TrimRight(string(runes[:min(len(runes), MaxShoutLength)]), " ")
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestSanitizeShout(t *testing.T) {
+	for shout, want := range map[string]string{
+		"  hello\n\tthere  ":         "hello there",
+		"bell\x07 and \u202eflip":    "bell and flip",
+		"bad \xff utf-8":             "bad utf-8",
+		"\U0001F469\u200d\U0001F4BB": "\U0001F469\u200d\U0001F4BB",
+		strings.Repeat("é", 300):     strings.Repeat("é", MaxShoutLength),
+		strings.Repeat("a ", 200):    strings.TrimSpace(strings.Repeat("a ", 128)),
+	} {
+		if got := sanitizeShout(shout); got != want {
+			t.Errorf("sanitizeShout(%q) = %q, want %q", shout, got, want)
+		}
+	}
+}
+
+// shoutingSnakeClient plays like greedySnakeClient, the snake at
+// http://loud shouts its turn and the shouts each snake hears are recorded.
+type shoutingSnakeClient struct {
+	greedySnakeClient
+	lock  sync.Mutex
+	heard map[int]string // turn of the request to http://quiet, shout of loud
+}
+
+func (c *shoutingSnakeClient) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+	if !strings.HasSuffix(url, "/move") {
+		return c.greedySnakeClient.Post(ctx, url, contentType, body)
+	}
+	var request client.SnakeRequest
+	if err := json.NewDecoder(body).Decode(&request); err != nil {
+		return nil, 0, err
+	}
+	data, _ := json.Marshal(request)
+	res, latency, err := c.greedySnakeClient.Post(ctx, url, contentType, bytes.NewReader(data))
+	if err != nil || !strings.HasPrefix(url, "http://loud") {
+		for _, snake := range request.Board.Snakes {
+			if snake.ID != request.You.ID {
+				c.lock.Lock()
+				c.heard[request.Turn] = snake.Shout
+				c.lock.Unlock()
+			}
+		}
+		return res, latency, err
+	}
+
+	var move client.MoveResponse
+	if err := json.NewDecoder(res.Body).Decode(&move); err != nil {
+		return nil, 0, err
+	}
+	move.Shout = fmt.Sprintf("turn %d\n<b>", request.Turn)
+	answer, _ := json.Marshal(move)
+	return stubResponse(string(answer)), latency, nil
+}
+
+func TestShoutsReachOtherSnakes(t *testing.T) {
+	gameState := &GameState{
+		Width:           11,
+		Height:          11,
+		Names:           []string{"loud", "quiet"},
+		URLs:            []string{"http://loud", "http://quiet"},
+		Timeout:         500,
+		GameType:        "standard",
+		MapName:         "standard",
+		Seed:            42,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+		Headless:        true,
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	httpClient := &shoutingSnakeClient{heard: map[int]string{}}
+	gameState.httpClient = httpClient
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// the shout of a turn is heard in the request of the next one
+	if got := httpClient.heard[0]; got != "" {
+		t.Errorf("quiet heard %q before loud shouted", got)
+	}
+	for turn := 1; turn < 4; turn++ {
+		want := fmt.Sprintf("turn %d <b>", turn-1)
+		if got := httpClient.heard[turn]; got != want {
+			t.Errorf("quiet heard %q on turn %d, want %q", got, turn, want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// EliminatedBySquad is the elimination cause of the snakes taken out with a
+// squad-mate under the shared elimination rule.
+const EliminatedBySquad = "squad-eliminated"
+
+// MaxSquadNameLength bounds the squad names, which are sent in every request.
+const MaxSquadNameLength = 32
+
+// SquadRules are the rules shared by the snakes of a squad.
+type SquadRules struct {
+	// PassThrough lets squad-mates move through each other's bodies.
+	PassThrough bool
+	// SharedHealth gives the snakes of a squad the health of the healthiest.
+	SharedHealth bool
+	// SharedLength grows the snakes of a squad to the length of the longest.
+	SharedLength bool
+	// SharedElimination eliminates a squad as soon as one of its snakes is.
+	SharedElimination bool
+}
+
+var squadRuleNames = []string{"pass-through", "shared-health", "shared-length", "shared-elimination"}
+
+func (r *SquadRules) flags() []*bool {
+	return []*bool{&r.PassThrough, &r.SharedHealth, &r.SharedLength, &r.SharedElimination}
+}
+
+// ParseSquadRules reads a comma separated list of squad rules: pass-through,
+// shared-health, shared-length and shared-elimination.
+func ParseSquadRules(list string) (SquadRules, error) {
+	var squadRules SquadRules
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		i := slices.Index(squadRuleNames, name)
+		if i < 0 {
+			return SquadRules{}, fmt.Errorf("unknown squad rule %q, valid rules are %s", name, strings.Join(squadRuleNames, ", "))
+		}
+		*squadRules.flags()[i] = true
+	}
+	return squadRules, nil
+}
+
+// String lists the rules in the format of ParseSquadRules. String, Set and
+// Type let SquadRules be used as a command flag.
+func (r *SquadRules) String() string {
+	var names []string
+	for i, on := range r.flags() {
+		if *on {
+			names = append(names, squadRuleNames[i])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (r *SquadRules) Set(list string) error {
+	squadRules, err := ParseSquadRules(list)
+	if err != nil {
+		return err
+	}
+	*r = squadRules
+	return nil
+}
+
+func (r *SquadRules) Type() string { return "rules" }
+
+// SquadOptions groups the snakes of a game into squads. The squad-mates play
+// together: the game is over when the snakes left are all of one squad.
+type SquadOptions struct {
+	// Squads holds the squad of each snake, in the order of Names and URLs.
+	// The game is free-for-all when it is empty.
+	Squads     []string
+	SquadRules SquadRules
+}
+
+func (opts SquadOptions) enabled() bool {
+	return len(opts.Squads) > 0
+}
+
+// CheckSquads checks the squads of the given number of snakes: every snake
+// needs one and there must be two squads at least.
+func CheckSquads(squads []string, snakes int) error {
+	if len(squads) == 0 {
+		return nil
+	}
+	if len(squads) != snakes {
+		return fmt.Errorf("got %d squads for %d snakes, every snake needs a squad", len(squads), snakes)
+	}
+	var errs []error
+	distinct := map[string]bool{}
+	for i, squad := range squads {
+		switch {
+		case strings.TrimSpace(squad) == "":
+			errs = append(errs, fmt.Errorf("snake %d has no squad", i+1))
+		case len(squad) > MaxSquadNameLength:
+			errs = append(errs, fmt.Errorf("squad %q is longer than %d bytes", squad, MaxSquadNameLength))
+		}
+		distinct[squad] = true
+	}
+	if len(distinct) < 2 {
+		errs = append(errs, errors.New("a squad game needs two squads at least"))
+	}
+	return errors.Join(errs...)
+}
+
+// squadSettings reports the squad rules in the deprecated squad settings of
+// the requests, which the snakes may still read.
+func (opts SquadOptions) squadSettings() client.SquadSettings {
+	return client.SquadSettings{
+		AllowBodyCollisions: opts.SquadRules.PassThrough,
+		SharedElimination:   opts.SquadRules.SharedElimination,
+		SharedHealth:        opts.SquadRules.SharedHealth,
+		SharedLength:        opts.SquadRules.SharedLength,
+	}
+}
+
+func (gameState *GameState) sameSquad(a, b string) bool {
+	return a != b && gameState.snakeStates[a].Squad != "" && gameState.snakeStates[a].Squad == gameState.snakeStates[b].Squad
+}
+
+// aliveSquads lists the squads with snakes still in the game.
+func (gameState *GameState) aliveSquads(boardState *rules.BoardState) []string {
+	var squads []string
+	for _, snake := range boardState.Snakes {
+		squad := gameState.snakeStates[snake.ID].Squad
+		if snake.EliminatedCause == rules.NotEliminated && !slices.Contains(squads, squad) {
+			squads = append(squads, squad)
+		}
+	}
+	return squads
+}
+
+// applySquadRules runs after the moves of a turn: it brings back the snakes
+// that ran into a squad-mate and shares health, length and eliminations
+// within the squads. It returns the IDs of the snakes eliminated with their
+// squad.
+func (gameState *GameState) applySquadRules(boardState *rules.BoardState) []string {
+	squadRules := gameState.SquadRules
+	if squadRules.PassThrough {
+		turn := boardState.Turn + 1
+		for i := range boardState.Snakes {
+			snake := &boardState.Snakes[i]
+			if snake.EliminatedCause != rules.EliminatedByCollision || snake.EliminatedOnTurn != turn || !gameState.sameSquad(snake.ID, snake.EliminatedBy) {
+				continue
+			}
+			// the first collision only is recorded, the snake may have run
+			// into an enemy too
+			if cause, by := gameState.collisionThroughSquad(boardState, snake, turn); cause != rules.NotEliminated {
+				rules.EliminateSnake(snake, cause, by, turn)
+			} else {
+				rules.EliminateSnake(snake, rules.NotEliminated, "", 0)
+			}
+		}
+	}
+
+	var eliminated []string
+	for i := range boardState.Snakes {
+		snake := &boardState.Snakes[i]
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		for _, other := range boardState.Snakes {
+			if !gameState.sameSquad(snake.ID, other.ID) {
+				continue
+			}
+			if other.EliminatedCause != rules.NotEliminated {
+				if squadRules.SharedElimination {
+					// there may be several culprits, EliminatedBy stays empty
+					rules.EliminateSnake(snake, EliminatedBySquad, "", boardState.Turn+1)
+					eliminated = append(eliminated, snake.ID)
+					break
+				}
+				continue
+			}
+			if squadRules.SharedHealth {
+				snake.Health = max(snake.Health, other.Health)
+			}
+			if squadRules.SharedLength {
+				for len(snake.Body) > 0 && len(snake.Body) < len(other.Body) {
+					snake.Body = append(snake.Body, snake.Body[len(snake.Body)-1])
+				}
+			}
+		}
+	}
+	return eliminated
+}
+
+// collisionThroughSquad finds the collision that eliminates snake on turn when
+// it passes through the bodies of its squad-mates, as in
+// rules.EliminateSnakesStandard: the bodies of the other snakes first, the
+// longest first, then the head-to-heads, lost against squad-mates too.
+func (gameState *GameState) collisionThroughSquad(boardState *rules.BoardState, snake *rules.Snake, turn int) (string, string) {
+	// the snakes still there when the collisions were checked
+	var others []rules.Snake
+	for _, other := range boardState.Snakes {
+		if other.ID == snake.ID || len(other.Body) == 0 {
+			continue
+		}
+		switch other.EliminatedCause {
+		case rules.NotEliminated:
+		case rules.EliminatedByOutOfHealth, rules.EliminatedByOutOfBounds:
+			continue
+		default:
+			if other.EliminatedOnTurn != turn {
+				continue
+			}
+		}
+		others = append(others, other)
+	}
+	slices.SortStableFunc(others, func(a, b rules.Snake) int {
+		return len(b.Body) - len(a.Body)
+	})
+
+	head := snake.Body[0]
+	sameCell := func(a, b rules.Point) bool {
+		return a.X == b.X && a.Y == b.Y
+	}
+	for _, other := range others {
+		if gameState.sameSquad(snake.ID, other.ID) {
+			continue
+		}
+		for _, segment := range other.Body[1:] {
+			if sameCell(head, segment) {
+				return rules.EliminatedByCollision, other.ID
+			}
+		}
+	}
+	for _, other := range others {
+		if sameCell(head, other.Body[0]) && len(snake.Body) <= len(other.Body) {
+			return rules.EliminatedByHeadToHeadCollision, other.ID
+		}
+	}
+	return rules.NotEliminated, ""
+}
@@ -0,0 +1,197 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+func TestParseSquadRules(t *testing.T) {
+	squadRules, err := ParseSquadRules("shared-health, pass-through")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !squadRules.PassThrough || !squadRules.SharedHealth || squadRules.SharedLength || squadRules.SharedElimination {
+		t.Errorf("got %+v, want pass-through and shared health", squadRules)
+	}
+	if got := squadRules.String(); got != "pass-through,shared-health" {
+		t.Errorf("got %q, want the rules in their usual order", got)
+	}
+	if _, err := ParseSquadRules("friendly-fire"); err == nil {
+		t.Error("an unknown rule was accepted")
+	}
+}
+
+func TestCheckSquads(t *testing.T) {
+	if err := CheckSquads(nil, 3); err != nil {
+		t.Errorf("free-for-all games were refused: %v", err)
+	}
+	if err := CheckSquads([]string{"a", "a", "b"}, 3); err != nil {
+		t.Errorf("valid squads were refused: %v", err)
+	}
+	for _, squads := range [][]string{{"a", "b"}, {"a", "a", "a"}, {"a", "", "b"}} {
+		if err := CheckSquads(squads, 3); err == nil {
+			t.Errorf("squads %q were accepted for 3 snakes", squads)
+		}
+	}
+}
+
+func TestApplySquadRules(t *testing.T) {
+	gameState := &GameState{
+		snakeStates: map[string]SnakeState{
+			"red1": {ID: "red1", Squad: "red"},
+			"red2": {ID: "red2", Squad: "red"},
+			"blue": {ID: "blue", Squad: "blue"},
+		},
+		SquadOptions: SquadOptions{SquadRules: SquadRules{PassThrough: true, SharedHealth: true, SharedLength: true}},
+	}
+	newBoard := func() *rules.BoardState {
+		boardState := rules.NewBoardState(7, 7)
+		boardState.Turn = 2
+		boardState.Snakes = []rules.Snake{
+			{ID: "red1", Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}}, Health: 50},
+			{ID: "red2", Body: []rules.Point{{X: 1, Y: 2}, {X: 2, Y: 2}}, Health: 90,
+				EliminatedCause: rules.EliminatedByCollision, EliminatedBy: "red1", EliminatedOnTurn: 3},
+			{ID: "blue", Body: []rules.Point{{X: 2, Y: 1}, {X: 3, Y: 1}}, Health: 100,
+				EliminatedCause: rules.EliminatedByCollision, EliminatedBy: "red1", EliminatedOnTurn: 3},
+		}
+		return boardState
+	}
+
+	boardState := newBoard()
+	gameState.applySquadRules(boardState)
+	red1, red2, blue := boardState.Snakes[0], boardState.Snakes[1], boardState.Snakes[2]
+	if red2.EliminatedCause != rules.NotEliminated || red2.EliminatedOnTurn != 0 {
+		t.Errorf("red2 ran into its squad-mate and was eliminated by %q", red2.EliminatedCause)
+	}
+	if blue.EliminatedCause != rules.EliminatedByCollision {
+		t.Error("blue ran into another squad and was brought back")
+	}
+	if red1.Health != 90 || red2.Health != 90 {
+		t.Errorf("got health %d and %d, want the 90 of red2 shared", red1.Health, red2.Health)
+	}
+	if len(red2.Body) != 3 {
+		t.Errorf("red2 has length %d, want the 3 of red1 shared", len(red2.Body))
+	}
+
+	gameState.SquadRules = SquadRules{SharedElimination: true}
+	boardState = newBoard()
+	eliminated := gameState.applySquadRules(boardState)
+	if red1 := boardState.Snakes[0]; red1.EliminatedCause != EliminatedBySquad || len(eliminated) != 1 {
+		t.Errorf("red1 left with its eliminated squad-mate: %q, %v", red1.EliminatedCause, eliminated)
+	}
+}
+
+func TestPassThroughCollisions(t *testing.T) {
+	gameState := &GameState{
+		snakeStates: map[string]SnakeState{
+			"red1": {ID: "red1", Squad: "red"},
+			"red2": {ID: "red2", Squad: "red"},
+			"blue": {ID: "blue", Squad: "blue"},
+		},
+		SquadOptions: SquadOptions{SquadRules: SquadRules{PassThrough: true}},
+	}
+	// red2 moves onto (2, 2), in the body of red1, the longest snake, which
+	// the rules record as the only cause
+	red1 := []rules.Point{{X: 2, Y: 3}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 0}, {X: 3, Y: 0}}
+	red2 := []rules.Point{{X: 2, Y: 2}, {X: 1, Y: 2}}
+	tests := []struct {
+		name      string
+		blue      []rules.Point
+		wantCause string
+		wantBy    string
+	}{
+		{"alone", []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 6}}, rules.NotEliminated, ""},
+		{"head-on with an enemy", []rules.Point{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}}, rules.EliminatedByHeadToHeadCollision, "blue"},
+		{"into an enemy body too", []rules.Point{{X: 3, Y: 2}, {X: 2, Y: 2}, {X: 1, Y: 2}, {X: 0, Y: 2}}, rules.EliminatedByCollision, "blue"},
+	}
+	for _, test := range tests {
+		boardState := rules.NewBoardState(7, 7)
+		boardState.Turn = 4
+		boardState.Snakes = []rules.Snake{
+			{ID: "red1", Body: red1, Health: 100},
+			{ID: "red2", Body: red2, Health: 100, EliminatedCause: rules.EliminatedByCollision, EliminatedBy: "red1", EliminatedOnTurn: 5},
+			{ID: "blue", Body: test.blue, Health: 100},
+		}
+		gameState.applySquadRules(boardState)
+		if got := boardState.Snakes[1]; got.EliminatedCause != test.wantCause || got.EliminatedBy != test.wantBy {
+			t.Errorf("%s: red2 got eliminated by %q of %q, want %q of %q", test.name, got.EliminatedCause, got.EliminatedBy, test.wantCause, test.wantBy)
+		}
+	}
+}
+
+// squadSnakeClient plays like lateSnakeClient and records the squad each
+// snake is told it is in.
+type squadSnakeClient struct {
+	lateSnakeClient
+	lock   sync.Mutex
+	squads map[string]string
+}
+
+func (c *squadSnakeClient) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	var request client.SnakeRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, 0, err
+	}
+	c.lock.Lock()
+	c.squads[strings.TrimPrefix(url, "http://")] = request.You.Squad
+	c.lock.Unlock()
+	return c.lateSnakeClient.Post(ctx, url, contentType, bytes.NewReader(data))
+}
+
+func TestSquadGame(t *testing.T) {
+	gameState := &GameState{
+		Width:           11,
+		Height:          11,
+		Names:           []string{"red1", "red2", "late"},
+		URLs:            []string{"http://red1", "http://red2", "http://late"},
+		Timeout:         500,
+		GameType:        "standard",
+		MapName:         "standard",
+		Seed:            42,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+		Headless:        true,
+		TimeoutOptions:  TimeoutOptions{TimeoutPolicy: TimeoutEliminate, MaxTimeouts: 1},
+		SquadOptions:    SquadOptions{Squads: []string{"red", "red", "blue"}},
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	httpClient := &squadSnakeClient{squads: map[string]string{}}
+	gameState.httpClient = httpClient
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// the late snake is out on turn 1, the red squad wins with both snakes
+	result := gameState.Result()
+	if result.WinnerSquad != "red" || result.WinnerName != "" || result.IsDraw {
+		t.Errorf("got winner squad %q, winner %q and draw %v, want the red squad", result.WinnerSquad, result.WinnerName, result.IsDraw)
+	}
+	if result.Turns > 3 {
+		t.Errorf("the game went on for %d turns with one squad left", result.Turns)
+	}
+	if result.Snakes[1].Squad != "red" || result.Snakes[1].EliminatedCause != rules.NotEliminated {
+		t.Errorf("got red2 as %+v, want it alive in the red squad", result.Snakes[1])
+	}
+	for url, want := range map[string]string{"red1/move": "red", "late/move": "blue"} {
+		if got := httpClient.squads[url]; got != want {
+			t.Errorf("%s was told its squad is %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// StdioURLScheme prefixes the command of the snakes played by a program
+// over its standard input and output, as in "stdio:python3 snake.py". See
+// StdioProvider.
+const StdioURLScheme = "stdio"
+
+// maxStdioLine bounds the lines a snake program may answer with.
+const maxStdioLine = 1 << 20
+
+// stdioStopTimeout is how long a snake program has to exit once its
+// standard input is closed, before it is killed.
+const stdioStopTimeout = 2 * time.Second
+
+// errStdioExited is returned for the requests to a snake program that
+// stopped answering.
+var errStdioExited = errors.New("the snake program exited")
+
+// stdioMessage is a request written to a snake program, on its own line.
+// Type is info, start, move or end; the snake answers each one with a line
+// holding the JSON body a snake server would answer with.
+type stdioMessage struct {
+	Type    string               `json:"type"`
+	Request *client.SnakeRequest `json:"request,omitempty"`
+}
+
+// StdioProvider plays a snake with a program that reads the requests of the
+// engine on its standard input and answers on its standard output, one JSON
+// line each, see stdioMessage. The program is started by the first request
+// and stopped by Close, it lives as long as the game.
+type StdioProvider struct {
+	// OnStart, when set, is called with the process of the program once it
+	// runs, before its first request.
+	OnStart func(process *os.Process)
+
+	cmd *exec.Cmd
+
+	turn chan struct{} // one request at a time
+	late int           // answers of the requests given up on, still to come, the turn must be held
+
+	lock    sync.Mutex // the fields below, never held while writing or reading
+	started bool
+	stdin   io.WriteCloser
+	writes  chan stdioWrite // the requests to write to the program
+	lines   chan []byte     // the answers, closed once the program exits
+	closed  chan struct{}   // closed by Close
+	err     error           // why the program takes no more requests
+}
+
+// stdioWrite is a line to write to a snake program, done gets the result of
+// the write.
+type stdioWrite struct {
+	line []byte
+	done chan error
+}
+
+// NewStdioProvider plays a snake with the program cmd, which must not be
+// started. Its standard error is left as set on cmd.
+func NewStdioProvider(cmd *exec.Cmd) *StdioProvider {
+	return &StdioProvider{cmd: cmd, turn: make(chan struct{}, 1), closed: make(chan struct{})}
+}
+
+// newStdioCommandProvider plays a snake with a command line, as in the
+// URLs of StdioURLScheme. The snake's standard error goes to ours.
+func newStdioCommandProvider(command string) (*StdioProvider, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("missing snake command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	return NewStdioProvider(cmd), nil
+}
+
+// start starts the program and returns where to write its requests and
+// read its answers.
+func (p *StdioProvider) start() (chan<- stdioWrite, <-chan []byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.started {
+		return p.writes, p.lines, p.err
+	}
+	p.started = true
+
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		p.err = err
+		return nil, nil, err
+	}
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		p.err = err
+		return nil, nil, err
+	}
+	if err := p.cmd.Start(); err != nil {
+		p.err = fmt.Errorf("error starting snake program: %w", err)
+		return nil, nil, p.err
+	}
+	if p.OnStart != nil {
+		p.OnStart(p.cmd.Process)
+	}
+	p.stdin = stdin
+	p.writes = make(chan stdioWrite)
+	p.lines = make(chan []byte)
+	go func() {
+		// a program that stops reading its input blocks this write, not
+		// the game: the requests give up on their context, and Close
+		// unblocks it by closing the input
+		for {
+			select {
+			case write := <-p.writes:
+				_, err := stdin.Write(write.line)
+				write.done <- err
+			case <-p.closed:
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(p.lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxStdioLine)
+		for scanner.Scan() {
+			p.lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+	}()
+	return p.writes, p.lines, nil
+}
+
+// request writes a message to the program and waits for its answer until
+// ctx is done. It returns how long the program took to answer.
+func (p *StdioProvider) request(ctx context.Context, message stdioMessage) ([]byte, time.Duration, error) {
+	select {
+	case p.turn <- struct{}{}:
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	}
+	defer func() { <-p.turn }()
+	writes, lines, err := p.start()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	line, err := json.Marshal(message)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
+	write := stdioWrite{line: append(line, '\n'), done: make(chan error, 1)}
+	select {
+	case writes <- write:
+	case <-p.closed:
+		return nil, 0, errStdioExited
+	case <-ctx.Done():
+		return nil, time.Since(start), ctx.Err()
+	}
+	written := write.done
+	for {
+		select {
+		case err := <-written:
+			if err != nil {
+				return nil, time.Since(start), fmt.Errorf("error writing to snake program: %w", err)
+			}
+			written = nil
+		case answer, ok := <-lines:
+			if !ok {
+				return nil, time.Since(start), errStdioExited
+			}
+			// the answers of the requests given up on come first
+			if p.late > 0 {
+				p.late--
+				continue
+			}
+			return answer, time.Since(start), nil
+		case <-ctx.Done():
+			// the program may still read the request and answer it
+			p.late++
+			return nil, time.Since(start), ctx.Err()
+		}
+	}
+}
+
+func (p *StdioProvider) Info(ctx context.Context) (client.SnakeMetadataResponse, error) {
+	var metadata client.SnakeMetadataResponse
+	answer, _, err := p.request(ctx, stdioMessage{Type: "info"})
+	if err != nil {
+		return metadata, fmt.Errorf("snake metadata request failed: %w", err)
+	}
+	if err := json.Unmarshal(answer, &metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse snake metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+func (p *StdioProvider) Start(ctx context.Context, request client.SnakeRequest) error {
+	_, _, err := p.request(ctx, stdioMessage{Type: "start", Request: &request})
+	return err
+}
+
+func (p *StdioProvider) Move(ctx context.Context, request client.SnakeRequest) (client.MoveResponse, time.Duration, error) {
+	var move client.MoveResponse
+	answer, latency, err := p.request(ctx, stdioMessage{Type: "move", Request: &request})
+	if err != nil {
+		return move, latency, err
+	}
+	if err := json.Unmarshal(answer, &move); err != nil {
+		return move, latency, &DecodeError{Err: err, Body: string(answer)}
+	}
+	return move, latency, nil
+}
+
+func (p *StdioProvider) End(ctx context.Context, request client.SnakeRequest) error {
+	_, _, err := p.request(ctx, stdioMessage{Type: "end", Request: &request})
+	return err
+}
+
+// Close stops the program: its standard input is closed and it is killed
+// if it has not exited after a while. It does not wait for the requests
+// under way, they fail.
+func (p *StdioProvider) Close() error {
+	p.lock.Lock()
+	running := p.started && p.err == nil
+	// a program not started yet never will be
+	p.started = true
+	if p.err == nil {
+		p.err = errStdioExited
+		close(p.closed)
+	}
+	p.lock.Unlock()
+	if !running {
+		return nil
+	}
+
+	// this also unblocks a write the program does not read
+	p.stdin.Close()
+	exited := make(chan error, 1)
+	go func() {
+		// the answers nobody waits for any more must not block the reader
+		for range p.lines {
+		}
+		exited <- p.cmd.Wait()
+	}()
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(stdioStopTimeout):
+		p.cmd.Process.Kill()
+		return <-exited
+	}
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// TimeoutPolicy decides the move of a snake that did not answer /move in
+// time.
+type TimeoutPolicy string
+
+const (
+	// TimeoutRepeatLast keeps the last move of the snake, like the official
+	// engine.
+	TimeoutRepeatLast TimeoutPolicy = "repeat"
+	// TimeoutRandomSafe picks a random move that neither leaves the board nor
+	// hits a body, or repeats the last move when there is none.
+	TimeoutRandomSafe TimeoutPolicy = "random-safe"
+	// TimeoutEliminate repeats the last move and eliminates the snake after
+	// MaxTimeouts consecutive timeouts.
+	TimeoutEliminate TimeoutPolicy = "eliminate"
+)
+
+// EliminatedByTimeout is the elimination cause of snakes removed by
+// TimeoutEliminate.
+const EliminatedByTimeout = "timeout"
+
+// DefaultMaxTimeouts is used by TimeoutEliminate when MaxTimeouts is not set.
+const DefaultMaxTimeouts = 3
+
+// ParseTimeoutPolicy checks a policy name, an empty name is TimeoutRepeatLast.
+func ParseTimeoutPolicy(name string) (TimeoutPolicy, error) {
+	switch policy := TimeoutPolicy(name); policy {
+	case "":
+		return TimeoutRepeatLast, nil
+	case TimeoutRepeatLast, TimeoutRandomSafe, TimeoutEliminate:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown timeout policy %q, valid policies are %q, %q and %q", name, TimeoutRepeatLast, TimeoutRandomSafe, TimeoutEliminate)
+	}
+}
+
+// String, Set and Type let a TimeoutPolicy be used as a command flag.
+func (p *TimeoutPolicy) String() string { return string(*p) }
+
+func (p *TimeoutPolicy) Set(name string) error {
+	policy, err := ParseTimeoutPolicy(name)
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+func (p *TimeoutPolicy) Type() string { return "policy" }
+
+// TimeoutOptions configures how a game treats snakes that answer /move late.
+type TimeoutOptions struct {
+	TimeoutPolicy TimeoutPolicy
+	// MaxTimeouts is how many consecutive timeouts eliminate a snake with
+	// TimeoutEliminate.
+	MaxTimeouts int
+	// TimeBank, in milliseconds, is extra time each snake may spend over the
+	// whole game: a move that takes longer than Timeout draws the excess from
+	// it instead of timing out.
+	TimeBank int
+}
+
+// isTimeout reports whether a request failed for taking too long.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// requestTimeout bounds the info, start and end requests of every provider,
+// as the HTTP client bounds all of its requests: a program that never
+// answers must not hang the game.
+func (gameState *GameState) requestTimeout() time.Duration {
+	return time.Duration(gameState.Timeout+max(gameState.TimeBank, 0)) * time.Millisecond
+}
+
+// moveDeadline is how long the snake may take to answer /move this turn.
+func (gameState *GameState) moveDeadline(snakeState SnakeState) time.Duration {
+	return time.Duration(gameState.Timeout)*time.Millisecond + snakeState.TimeBank
+}
+
+// chargeTimeBank updates the timeout counts and the time bank of a snake
+// after its /move request.
+func (gameState *GameState) chargeTimeBank(snakeState *SnakeState, err error) {
+	snakeState.TimedOut = err != nil && isTimeout(err)
+	if snakeState.TimedOut {
+		snakeState.Timeouts++
+		snakeState.ConsecutiveTimeouts++
+		snakeState.TimeBank = 0
+		return
+	}
+	snakeState.ConsecutiveTimeouts = 0
+	if over := snakeState.Latency - time.Duration(gameState.Timeout)*time.Millisecond; over > 0 {
+		snakeState.TimeBank = max(snakeState.TimeBank-over, 0)
+	}
+}
+
+// applyTimeoutPolicy sets the move of a snake that timed out this turn. It
+// returns true when the snake must be eliminated instead.
+func (gameState *GameState) applyTimeoutPolicy(boardState *rules.BoardState, snakeState *SnakeState) bool {
+	switch gameState.TimeoutPolicy {
+	case TimeoutRandomSafe:
+		if moves := safeMoves(boardState, snakeState.ID); len(moves) > 0 {
+			snakeState.LastMove = moves[gameState.rand.Intn(len(moves))]
+		}
+	case TimeoutEliminate:
+		maxTimeouts := gameState.MaxTimeouts
+		if maxTimeouts <= 0 {
+			maxTimeouts = DefaultMaxTimeouts
+		}
+		return snakeState.ConsecutiveTimeouts >= maxTimeouts
+	}
+	return false
+}
+
+// safeMoves lists the moves of a snake that neither leave the board nor hit
+// a body, in a fixed order.
+func safeMoves(boardState *rules.BoardState, id string) []string {
+	occupied := map[rules.Point]bool{}
+	var head rules.Point
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		if snake.ID == id {
+			head = snake.Body[0]
+		}
+		for _, p := range snake.Body {
+			occupied[p] = true
+		}
+	}
+
+	var moves []string
+	for _, m := range []struct {
+		name string
+		to   rules.Point
+	}{
+		{rules.MoveUp, rules.Point{X: head.X, Y: head.Y + 1}},
+		{rules.MoveDown, rules.Point{X: head.X, Y: head.Y - 1}},
+		{rules.MoveLeft, rules.Point{X: head.X - 1, Y: head.Y}},
+		{rules.MoveRight, rules.Point{X: head.X + 1, Y: head.Y}},
+	} {
+		if m.to.X >= 0 && m.to.Y >= 0 && m.to.X < boardState.Width && m.to.Y < boardState.Height && !occupied[m.to] {
+			moves = append(moves, m.name)
+		}
+	}
+	return moves
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/board"
+)
+
+// lateSnakeClient plays like greedySnakeClient, except that the /move
+// requests to URLs containing "late" time out.
+type lateSnakeClient struct {
+	greedySnakeClient
+}
+
+func (c lateSnakeClient) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+	if strings.Contains(url, "late") && strings.HasSuffix(url, "/move") {
+		return nil, 500 * time.Millisecond, context.DeadlineExceeded
+	}
+	return c.greedySnakeClient.Post(ctx, url, contentType, body)
+}
+
+func playLateGame(t *testing.T, timeouts TimeoutOptions) Result {
+	t.Helper()
+
+	gameState := &GameState{
+		Width:           11,
+		Height:          11,
+		Names:           []string{"late", "greedy"},
+		URLs:            []string{"http://late", "http://greedy"},
+		Timeout:         500,
+		GameType:        "standard",
+		MapName:         "standard",
+		Seed:            42,
+		FoodSpawnChance: 15,
+		MinimumFood:     1,
+		Headless:        true,
+		TimeoutOptions:  timeouts,
+	}
+	if err := gameState.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	gameState.httpClient = lateSnakeClient{}
+
+	if err := gameState.Run(context.Background(), board.Game{ID: gameState.gameID}, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return gameState.Result()
+}
+
+func TestTimeoutEliminate(t *testing.T) {
+	result := playLateGame(t, TimeoutOptions{TimeoutPolicy: TimeoutEliminate, MaxTimeouts: 2})
+
+	late := result.Snakes[0]
+	if late.EliminatedCause != EliminatedByTimeout || late.EliminatedOnTurn != 2 {
+		t.Fatalf("late snake eliminated by %q on turn %d, want %q on turn 2", late.EliminatedCause, late.EliminatedOnTurn, EliminatedByTimeout)
+	}
+	if late.Timeouts != 2 {
+		t.Errorf("late snake has %d timeouts, want 2", late.Timeouts)
+	}
+	// the eliminations end the game, nobody plays another turn
+	if greedy := result.Snakes[1]; result.Turns != 2 || len(greedy.Latencies) != 2 {
+		t.Errorf("the game lasted %d turns with %d moves of the winner, want it over on turn 2", result.Turns, len(greedy.Latencies))
+	}
+	if result.WinnerName != "greedy" {
+		t.Errorf("winner is %q, want greedy", result.WinnerName)
+	}
+	if result.TimeoutPolicy != TimeoutEliminate {
+		t.Errorf("result policy is %q, want %q", result.TimeoutPolicy, TimeoutEliminate)
+	}
+}
+
+func TestTimeoutRandomSafe(t *testing.T) {
+	result := playLateGame(t, TimeoutOptions{TimeoutPolicy: TimeoutRandomSafe})
+
+	late := result.Snakes[0]
+	if late.EliminatedCause == EliminatedByTimeout {
+		t.Fatal("random-safe eliminated the late snake")
+	}
+	if late.Timeouts == 0 || late.Timeouts != len(late.Latencies) {
+		t.Errorf("late snake has %d timeouts for %d moves", late.Timeouts, len(late.Latencies))
+	}
+	// repeating "up" would hit the wall within 10 turns
+	if late.EliminatedOnTurn != 0 && late.EliminatedOnTurn <= 10 {
+		t.Errorf("late snake eliminated on turn %d by %q", late.EliminatedOnTurn, late.EliminatedCause)
+	}
+}
+
+func TestParseTimeoutPolicy(t *testing.T) {
+	if policy, err := ParseTimeoutPolicy(""); err != nil || policy != TimeoutRepeatLast {
+		t.Errorf(`ParseTimeoutPolicy("") = %q, %v`, policy, err)
+	}
+	if _, err := ParseTimeoutPolicy("skip"); err == nil {
+		t.Error(`ParseTimeoutPolicy("skip") did not fail`)
+	}
+}
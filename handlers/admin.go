@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/a-h/templ"
 	"github.com/secomp2025/localsnake/controllers"
@@ -64,6 +65,14 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	moveStats, err := controllers.NewGameController(database.DB).SnakeMoveStats(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// the manager is nil while the server starts or shuts down
+	manager := controllers.GetServerManager()
 	for i, team := range modelTeamList {
 		teamSnakes, err := snakes.ListTeamSnakes(r.Context(), team.ID)
 		if err != nil {
@@ -78,9 +87,11 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
 
 		lastSnake := teamSnakes[len(teamSnakes)-1]
 
-		status, err := controllers.GetServerManager().GetSnakeStatus(r.Context(), &lastSnake)
-		if err != nil {
-			status = game.StatusOffline
+		status := game.StatusOffline
+		if manager != nil {
+			if status, err = manager.GetSnakeStatus(r.Context(), &lastSnake); err != nil {
+				status = game.StatusOffline
+			}
 		}
 
 		modelTeamList[i].Snake = &models.Snake{
@@ -89,6 +100,17 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
 			Lang:      lastSnake.Lang,
 			Status:    string(status),
 		}
+		if s, ok := moveStats[lastSnake.ID]; ok {
+			stats := newMoveStatsModel(s)
+			modelTeamList[i].Snake.MoveStats = &stats
+		}
+		if manager == nil {
+			continue
+		}
+		if history, ok := manager.ResourceHistory(lastSnake.ID); ok {
+			usage := newResourceUsageModel(history)
+			modelTeamList[i].Snake.Resources = &usage
+		}
 	}
 
     // build codes list with claimed status
@@ -109,5 +131,40 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
         modelCodes = append(modelCodes, models.Code{ID: c.ID, Code: c.Code, Used: used})
     }
 
-	templ.Handler(pages.Admin(modelTeamList, modelCodes)).ServeHTTP(w, r)
+	templ.Handler(pages.Admin(modelTeamList, modelCodes, gameMapModels())).ServeHTTP(w, r)
+}
+
+// resourceNames are the resources of controllers.ResourceLimits as shown on
+// the admin page.
+var resourceNames = map[string]string{
+	"cpu":     "CPU",
+	"rss":     "memória",
+	"threads": "threads",
+	"files":   "arquivos abertos",
+}
+
+func newResourceUsageModel(h controllers.ResourceHistory) models.ResourceUsage {
+	usage := models.ResourceUsage{Warnings: h.Warnings, Kills: h.Kills}
+	for _, s := range h.Samples {
+		usage.Samples = append(usage.Samples, models.ResourceSample{
+			At:         s.At,
+			CPUPercent: s.CPUPercent,
+			RSSMB:      float64(s.RSS) / (1 << 20),
+			Threads:    s.Threads,
+			OpenFiles:  s.OpenFiles,
+		})
+	}
+	if e := h.LastEvent; e != nil {
+		var names []string
+		for _, r := range e.Resources {
+			names = append(names, resourceNames[r])
+		}
+		usage.LastEventAt = e.At
+		if e.Killed {
+			usage.LastEvent = "encerrada por exceder o limite de " + strings.Join(names, ", ")
+		} else {
+			usage.LastEvent = "perto do limite de " + strings.Join(names, ", ")
+		}
+	}
+	return usage
 }
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/pages"
+)
+
+// Limits of batch jobs started from the admin page.
+const (
+	maxBatchGames       = 1000
+	maxBatchParallelism = 16
+)
+
+// AdminBatchHandler lists batch simulation jobs on GET and starts one on
+// POST. The snakes are team snakes given by ID and external snake URLs.
+func AdminBatchHandler(w http.ResponseWriter, r *http.Request) {
+	team := sessionAdmin(r)
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	batches := controllers.NewBatchController()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		snakes, games, parallelism, seed, err := parseBatchForm(r)
+		if err != nil {
+			var httpErr *httpError
+			if !errors.As(err, &httpErr) {
+				slog.ErrorContext(r.Context(), "admin: error preparing batch", "err", err)
+				httpErr = &httpError{status: http.StatusInternalServerError, message: "Falha ao preparar as snakes"}
+			}
+			http.Error(w, httpErr.message, httpErr.status)
+			return
+		}
+
+		job := batches.StartBatch(snakes, games, parallelism, seed)
+		names := make([]string, len(snakes))
+		for i, snake := range snakes {
+			names[i] = snake.Name
+		}
+		recordAudit(r, team, controllers.AuditBatch, "batch:"+strconv.FormatInt(job.ID, 10), map[string]any{
+			"snakes":      names,
+			"games":       games,
+			"parallelism": parallelism,
+			"seed":        seed,
+		})
+
+		http.Redirect(w, r, "/adm/batch", http.StatusSeeOther)
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var modelJobs []models.BatchJob
+	for _, job := range batches.ListBatches() {
+		modelJobs = append(modelJobs, newBatchJobModel(&job))
+	}
+
+	templ.Handler(pages.AdminBatch(modelJobs)).ServeHTTP(w, r)
+}
+
+// AdminBatchCancelHandler stops a running batch job.
+func AdminBatchCancelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if sessionAdmin(r) == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !controllers.NewBatchController().CancelBatch(id) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	http.Redirect(w, r, "/adm/batch", http.StatusSeeOther)
+}
+
+func parseBatchForm(r *http.Request) ([]game.Snake, int, int, int64, error) {
+	games, err := strconv.Atoi(r.FormValue("games"))
+	if err != nil || games < 1 || games > maxBatchGames {
+		return nil, 0, 0, 0, &httpError{http.StatusBadRequest, fmt.Sprintf("O número de jogos deve estar entre 1 e %d", maxBatchGames)}
+	}
+	parallelism, err := strconv.Atoi(r.FormValue("parallelism"))
+	if err != nil || parallelism < 1 || parallelism > maxBatchParallelism {
+		return nil, 0, 0, 0, &httpError{http.StatusBadRequest, fmt.Sprintf("O paralelismo deve estar entre 1 e %d", maxBatchParallelism)}
+	}
+	seed := time.Now().UnixNano()
+	if s := strings.TrimSpace(r.FormValue("seed")); s != "" {
+		if seed, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, 0, 0, 0, &httpError{http.StatusBadRequest, "Seed inválida"}
+		}
+	}
+
+	var snakeIDs []int64
+	for _, field := range strings.FieldsFunc(r.FormValue("snake_ids"), func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, 0, 0, 0, &httpError{http.StatusBadRequest, "IDs de snake inválidos"}
+		}
+		snakeIDs = append(snakeIDs, id)
+	}
+
+	var snakes []game.Snake
+	if len(snakeIDs) > 0 {
+		if snakes, err = battleGameSnakes(r.Context(), snakeIDs); err != nil {
+			return nil, 0, 0, 0, err
+		}
+	}
+	for _, line := range strings.Split(r.FormValue("urls"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		u, err := url.ParseRequestURI(line)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, 0, 0, 0, &httpError{http.StatusBadRequest, "URL inválida: " + line}
+		}
+		snakes = append(snakes, game.Snake{Name: u.Host, URL: u.String()})
+	}
+	if len(snakes) == 0 {
+		return nil, 0, 0, 0, &httpError{http.StatusBadRequest, "Informe ao menos uma snake"}
+	}
+
+	return snakes, games, parallelism, seed, nil
+}
+
+func newBatchJobModel(job *controllers.BatchJob) models.BatchJob {
+	model := models.BatchJob{
+		ID:          job.ID,
+		Games:       job.Games,
+		Parallelism: job.Parallelism,
+		Seed:        job.Seed,
+		Finished:    job.Finished,
+		Running:     job.Running(),
+		StartedAt:   job.StartedAt,
+	}
+	for _, snake := range job.Snakes {
+		model.Snakes = append(model.Snakes, snake.Name)
+	}
+	if job.Running() {
+		model.Duration = time.Since(job.StartedAt)
+	} else {
+		model.Duration = job.FinishedAt.Sub(job.StartedAt)
+	}
+	if job.Err != nil {
+		model.Error = job.Err.Error()
+	}
+
+	if job.Stats != nil {
+		model.Draws = job.Stats.Draws
+		model.Errors = job.Stats.Errors
+		model.AverageTurns = job.Stats.AverageTurns
+		for _, s := range job.Stats.Snakes {
+			model.SnakeStats = append(model.SnakeStats, models.BatchSnakeStats{
+				Name:          s.Name,
+				Wins:          s.Wins,
+				WinRate:       s.WinRate,
+				WinRateLow:    s.WinRateLow,
+				WinRateHigh:   s.WinRateHigh,
+				AverageLength: s.AverageLength,
+				DeathCauses:   s.DeathSummary(),
+				LatencyP50:    s.LatencyP50,
+				LatencyP90:    s.LatencyP90,
+				LatencyP99:    s.LatencyP99,
+				Timeouts:      s.Timeouts,
+			})
+		}
+	}
+	return model
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/templates/pages"
+)
+
+// AdminGamesHandler lists the running games for admins, with pause, resume,
+// step and abort controls.
+func AdminGamesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if sessionAdmin(r) == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	templ.Handler(pages.AdminGames(runningGames(), controllers.NewGameController(database.DB).MaxRunningGames())).ServeHTTP(w, r)
+}
+
+// AdminGameActionHandler pauses, resumes or aborts a running game from the
+// admin games page.
+func AdminGameActionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	team := sessionAdmin(r)
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	gameID := r.FormValue("id")
+	games := controllers.NewGameController(database.DB)
+
+	var ok bool
+	switch r.FormValue("action") {
+	case game.ControlPause, game.ControlResume, game.ControlStep:
+		ok = controlGame(r, team, gameID, game.ControlCommand{Command: r.FormValue("action")}, nil) == nil
+	case "abort":
+		reason := strings.TrimSpace(r.FormValue("reason"))
+		if reason == "" {
+			reason = "interrompida pela administração"
+		}
+		if ok = games.AbortGame(gameID, reason); ok {
+			recordAudit(r, team, controllers.AuditGameAbort, "game:"+gameID, map[string]any{"reason": reason})
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	http.Redirect(w, r, "/adm/games", http.StatusSeeOther)
+}
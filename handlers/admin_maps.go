@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/pages"
+)
+
+// maxMapUpload bounds the size of an uploaded map file.
+const maxMapUpload = 256 << 10 // 256 KiB
+
+// AdminMapsHandler lists the maps on GET and stores an uploaded custom map on
+// POST. An invalid map is shown on the page with the problems found.
+func AdminMapsHandler(w http.ResponseWriter, r *http.Request) {
+	team := sessionAdmin(r)
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var message string
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		customMap, err := acceptMapUpload(w, r)
+		var httpErr *httpError
+		switch {
+		case errors.As(err, &httpErr):
+			w.WriteHeader(httpErr.status)
+			message = httpErr.message
+		case err != nil:
+			slog.ErrorContext(r.Context(), "admin: error saving map", "err", err)
+			http.Error(w, "Falha ao salvar o mapa", http.StatusInternalServerError)
+			return
+		default:
+			recordAudit(r, team, controllers.AuditMapUpload, "map:"+customMap.ID, map[string]any{"version": customMap.Version})
+			http.Redirect(w, r, "/adm/maps", http.StatusSeeOther)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	templ.Handler(pages.AdminMaps(gameMapModels(), message)).ServeHTTP(w, r)
+}
+
+// acceptMapUpload reads the "map" file of a multipart upload and saves it
+// as a custom map. Invalid maps are reported as an httpError listing the
+// problems.
+func acceptMapUpload(w http.ResponseWriter, r *http.Request) (game.CustomMap, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxMapUpload+4096)
+	if err := r.ParseMultipartForm(maxMapUpload); err != nil {
+		return game.CustomMap{}, &httpError{http.StatusRequestEntityTooLarge, "Arquivo excede o limite de 256KB"}
+	}
+	file, header, err := r.FormFile("map")
+	if err != nil {
+		return game.CustomMap{}, &httpError{http.StatusBadRequest, "Arquivo não recebido"}
+	}
+	defer file.Close()
+	if !game.IsCustomMapFile(header.Filename) {
+		return game.CustomMap{}, &httpError{http.StatusBadRequest, "O mapa deve ser um arquivo .json, .yaml ou .yml"}
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return game.CustomMap{}, err
+	}
+
+	customMap, err := game.ParseCustomMap(header.Filename, data)
+	if err != nil {
+		return customMap, &httpError{http.StatusBadRequest, "Mapa inválido:\n" + err.Error()}
+	}
+	if customMap, err = controllers.SaveMap(header.Filename, data); err != nil {
+		return customMap, err
+	}
+	slog.InfoContext(r.Context(), "admin: map saved", "map", customMap.ID, "version", customMap.Version)
+	return customMap, nil
+}
+
+// gameMapModels lists the maps for the admin pages, custom maps first.
+func gameMapModels() []models.GameMap {
+	var builtin, custom []models.GameMap
+	for _, info := range game.ListMaps() {
+		model := models.GameMap{
+			ID:          info.ID,
+			Name:        info.Name,
+			Author:      info.Author,
+			Description: info.Description,
+			Sizes:       strings.Join(info.Sizes, ", "),
+			Custom:      info.Custom,
+		}
+		if info.MaxPlayers > 0 {
+			model.Players = fmt.Sprintf("%d–%d", info.MinPlayers, info.MaxPlayers)
+		}
+		if info.Custom {
+			custom = append(custom, model)
+		} else {
+			builtin = append(builtin, model)
+		}
+	}
+	return append(custom, builtin...)
+}
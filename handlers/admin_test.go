@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+)
+
+func TestAdminHandlerWithoutServerManager(t *testing.T) {
+	apiTestServer(t)
+	ctx := context.Background()
+	code, err := controllers.NewCodeController(database.DB).CreateCode(ctx, "ADMBSNAKE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	teams := controllers.NewTeamController(database.DB)
+	if _, err := teams.CreateAdminTeam(ctx, "root", code.ID); err != nil {
+		t.Fatal(err)
+	}
+	teamsList, err := teams.ListTeams(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a snake for every team, whose status and resources are asked for
+	snakes := controllers.NewSnakeController(database.DB)
+	for _, team := range teamsList {
+		if _, err := snakes.CreateSnake(ctx, &database.Snake{TeamID: team.ID, Path: "snake.py", Lang: ".py"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if controllers.GetServerManager() != nil {
+		t.Fatal("the server manager is running")
+	}
+
+	r := httptest.NewRequest("GET", "/adm", nil)
+	r.AddCookie(&http.Cookie{Name: "team_code", Value: "ADMBSNAKE"})
+	w := httptest.NewRecorder()
+	AdminHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want the page with the snakes offline", w.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/pages"
+)
+
+const uploadsPerPage = 50
+
+// AdminUploadsHandler renders the upload audit log for admins.
+func AdminUploadsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	team := sessionAdmin(r)
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	// fetch one extra row to know whether there is a next page
+	uploads := controllers.NewUploadController(database.DB)
+	uploadList, err := uploads.ListUploads(r.Context(), uploadsPerPage+1, int64((page-1)*uploadsPerPage))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "admin: error listing uploads", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	hasNext := len(uploadList) > uploadsPerPage
+	if hasNext {
+		uploadList = uploadList[:uploadsPerPage]
+	}
+
+	var modelUploads []models.Upload
+	for _, u := range uploadList {
+		var findings []string
+		if u.Findings.Valid {
+			findings = strings.Split(u.Findings.String, "\n")
+		}
+		modelUploads = append(modelUploads, models.Upload{
+			ID:         u.ID,
+			TeamName:   u.TeamName,
+			Filename:   u.Filename,
+			Size:       u.Size,
+			Lang:       u.Lang.String,
+			Status:     u.Status,
+			Findings:   findings,
+			RemoteAddr: u.RemoteAddr.String,
+			CreatedAt:  u.CreatedAt.Time,
+		})
+	}
+
+	templ.Handler(pages.AdminUploads(modelUploads, page, hasNext)).ServeHTTP(w, r)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+)
+
+// apiPrefix is the base path of the versioned JSON API.
+const apiPrefix = "/api/v1"
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// apiAccess tells who may call an API route.
+type apiAccess int
+
+const (
+	accessPublic apiAccess = iota
+	accessTeam
+	// accessAdmin routes are called by admin teams from their session: the
+	// ones that pause, abort or replay games, restart snakes or replace maps
+	// take no scope, so that a leaked token cannot call them. Admin tokens
+	// only reach the admin routes that read, such as GET /teams.
+	accessAdmin
+)
+
+// apiCaller is the authenticated team behind an API request. Token is set
+// when the request was authenticated with an API token rather than the
+// session cookie.
+type apiCaller struct {
+	Team    *database.Team
+	IsAdmin bool
+	Token   *database.ApiToken
+}
+
+// hasScope reports whether the caller may use a route requiring scope.
+// Session callers hold every scope; token callers only the ones granted.
+func (c *apiCaller) hasScope(scope string) bool {
+	if c.Token == nil {
+		return true
+	}
+	return scope != "" && slices.Contains(controllers.TokenScopes(c.Token), scope)
+}
+
+// apiParam documents a path or query parameter in the OpenAPI document.
+type apiParam struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+}
+
+// apiRoute declares an API endpoint. The same table drives both the mux
+// registration and the generated OpenAPI document. Scope is the token scope
+// the route requires; routes without one are reserved to session callers.
+// Upload names the multipart file field of upload routes.
+type apiRoute struct {
+	Method    string
+	Path      string
+	Summary   string
+	Access    apiAccess
+	Scope     string
+	Params    []apiParam
+	Paginated bool
+	Request   any
+	Upload    string
+	Response  any
+	Status    int
+	Handler   func(w http.ResponseWriter, r *http.Request, caller *apiCaller)
+}
+
+// ErrorResponse is the body of every API error.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Pagination describes the page returned by list endpoints.
+type Pagination struct {
+	Page    int   `json:"page"`
+	PerPage int   `json:"per_page"`
+	Total   int64 `json:"total"`
+}
+
+// RegisterAPIRoutes mounts every /api/v1 route on the given mux.
+func RegisterAPIRoutes(mux *http.ServeMux) {
+	for _, route := range apiRoutes() {
+		mux.HandleFunc(route.Method+" "+apiPrefix+route.Path, route.serve)
+	}
+	mux.HandleFunc("GET "+apiPrefix+"/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, buildOpenAPI(apiRoutes()))
+	})
+	mux.HandleFunc(apiPrefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "not_found", "unknown endpoint")
+	})
+}
+
+func (route apiRoute) serve(w http.ResponseWriter, r *http.Request) {
+	var caller *apiCaller
+	if route.Access != accessPublic {
+		var ok bool
+		caller, ok = apiAuthenticate(w, r)
+		if !ok {
+			return
+		}
+		if route.Access == accessAdmin && !caller.IsAdmin {
+			writeAPIError(w, http.StatusForbidden, "forbidden", "admin access required")
+			return
+		}
+		if !caller.hasScope(route.Scope) {
+			writeAPIError(w, http.StatusForbidden, "insufficient_scope", "token lacks the "+route.Scope+" scope")
+			return
+		}
+	}
+	route.Handler(w, r, caller)
+}
+
+// apiAuthenticate resolves the team of the request from a bearer API token or,
+// failing that, the session cookie. It writes the error response itself and
+// reports whether the caller is known.
+func apiAuthenticate(w http.ResponseWriter, r *http.Request) (*apiCaller, bool) {
+	var team *database.Team
+	var token *database.ApiToken
+	var err error
+
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		tokens := controllers.NewTokenController(database.DB)
+		token, err = tokens.Authenticate(r.Context(), strings.TrimSpace(bearer))
+		if err != nil {
+			slog.ErrorContext(r.Context(), "api: error checking token", "err", err)
+			writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+			return nil, false
+		}
+		if token == nil {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "invalid or revoked token")
+			return nil, false
+		}
+
+		teams := controllers.NewTeamController(database.DB)
+		team, err = teams.GetTeam(r.Context(), token.TeamID)
+	} else {
+		teamCode := GetCookieValue(r, "team_code")
+		if teamCode == "" {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "missing credentials")
+			return nil, false
+		}
+		team, err = teamFromCode(r.Context(), teamCode)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error getting team", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return nil, false
+	}
+	if team == nil {
+		writeAPIError(w, http.StatusUnauthorized, "unauthorized", "invalid credentials")
+		return nil, false
+	}
+
+	return &apiCaller{
+		Team:    team,
+		IsAdmin: team.IsAdmin.Valid && team.IsAdmin.Bool,
+		Token:   token,
+	}, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("api: error encoding response", "err", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, ErrorResponse{Error: APIError{Code: code, Message: message}})
+}
+
+// readJSON decodes the request body into v, answering 400 on failure.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// parsePagination reads the page and per_page query parameters.
+func parsePagination(w http.ResponseWriter, r *http.Request) (Pagination, bool) {
+	p := Pagination{Page: 1, PerPage: defaultPerPage}
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			writeAPIError(w, http.StatusBadRequest, "bad_request", "page must be a positive integer")
+			return p, false
+		}
+		p.Page = page
+	}
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		perPage, err := strconv.Atoi(v)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			writeAPIError(w, http.StatusBadRequest, "bad_request", "per_page must be between 1 and "+strconv.Itoa(maxPerPage))
+			return p, false
+		}
+		p.PerPage = perPage
+	}
+
+	return p, true
+}
+
+func (p Pagination) offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// paginate returns the slice of items that belongs to the requested page.
+func paginate[T any](items []T, p *Pagination) []T {
+	p.Total = int64(len(items))
+	start := min(p.offset(), len(items))
+	end := min(start+p.PerPage, len(items))
+	return items[start:end]
+}
+
+// pathID parses an integer path value, answering 400 on failure.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", name+" must be an integer")
+		return 0, false
+	}
+	return id, true
+}
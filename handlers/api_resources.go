@@ -0,0 +1,771 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+)
+
+type StatusResponse struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+type TeamResponse struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	IsAdmin   bool       `json:"is_admin"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type TeamList struct {
+	Items      []TeamResponse `json:"items"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+type SnakeResponse struct {
+	ID        int64      `json:"id"`
+	TeamID    int64      `json:"team_id"`
+	Lang      string     `json:"lang"`
+	Status    string     `json:"status,omitempty"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	// Warnings lists the findings of an upload that was accepted but
+	// flagged for review.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type SnakeList struct {
+	Items      []SnakeResponse `json:"items"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+type GameSnakeResponse struct {
+	SnakeID  *int64 `json:"snake_id"`
+	Name     string `json:"name"`
+	IsWinner bool   `json:"is_winner"`
+	Squad    string `json:"squad,omitempty"`
+	// Timeouts counts the move requests of the snake that took too long.
+	Timeouts int64 `json:"timeouts"`
+}
+
+type GameResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Seed   int64  `json:"seed"`
+	Turns  int64  `json:"turns"`
+	Winner string `json:"winner,omitempty"`
+	// WinnerSquad is the winner of squad games, all its snakes are winners.
+	WinnerSquad string `json:"winner_squad,omitempty"`
+	IsDraw      bool   `json:"is_draw"`
+	Paused      bool   `json:"paused,omitempty"`
+	// TurnDelayMS is the time a running game waits after each turn.
+	TurnDelayMS int `json:"turn_delay_ms,omitempty"`
+	// Phase is created, running or finished while the game is in memory and
+	// archived afterwards.
+	Phase string `json:"phase"`
+	// AbortReason is set for games stopped before their end.
+	AbortReason string `json:"abort_reason,omitempty"`
+	// TimeoutPolicy is how the game treated snakes that answered late, once
+	// it is over.
+	TimeoutPolicy string `json:"timeout_policy,omitempty"`
+	Map           string `json:"map,omitempty"`
+	// Fog is the fog of war mode, radius or sight, of games played with it.
+	Fog           string `json:"fog,omitempty"`
+	VisionRadius  int64  `json:"vision_radius,omitempty"`
+	VisionOverlay bool   `json:"vision_overlay,omitempty"`
+	// SquadRules are the rules of squad games, see CreateGameRequest.
+	SquadRules string              `json:"squad_rules,omitempty"`
+	CreatedAt  *time.Time          `json:"created_at"`
+	FinishedAt *time.Time          `json:"finished_at"`
+	Snakes     []GameSnakeResponse `json:"snakes,omitempty"`
+	StreamURL  string              `json:"stream_url"`
+	ReplayURL  string              `json:"replay_url"`
+}
+
+type GameList struct {
+	Items      []GameResponse `json:"items"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+// LiveGameResponse is a running game, as listed for spectators.
+type LiveGameResponse struct {
+	ID        string              `json:"id"`
+	Snakes    []GameSnakeResponse `json:"snakes"`
+	Turn      int                 `json:"turn"`
+	Alive     int                 `json:"alive"`
+	Viewers   int                 `json:"viewers"`
+	Paused    bool                `json:"paused"`
+	StartedAt time.Time           `json:"started_at"`
+	StreamURL string              `json:"stream_url"`
+	WatchURL  string              `json:"watch_url"`
+}
+
+type LiveGameList struct {
+	Items []LiveGameResponse `json:"items"`
+}
+
+// AbortGameRequest gives the reason recorded for an aborted game.
+type AbortGameRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GameDelayRequest sets the time a running game waits after each turn.
+type GameDelayRequest struct {
+	DelayMS int `json:"delay_ms"`
+}
+
+// CreateGameRequest starts a practice game for the caller's team when
+// SnakeIDs is empty, or a battle between the given snakes (admin only). Map
+// is the ID of the map, the standard one when empty, see GET /maps. Fog is off,
+// radius or sight: with fog of war the snakes only see the board within
+// VisionRadius moves of their bodies, and in sight mode not behind bodies
+// and walls either. VisionOverlay sends what each snake sees to spectators.
+// Squads, given with SnakeIDs, puts each snake in a squad: the game ends when
+// one squad is left. SquadRules lists the squad rules, comma separated:
+// pass-through, shared-health, shared-length and shared-elimination.
+type CreateGameRequest struct {
+	SnakeIDs      []int64  `json:"snake_ids,omitempty"`
+	Ghost         bool     `json:"ghost,omitempty"`
+	Map           string   `json:"map,omitempty"`
+	Fog           string   `json:"fog,omitempty"`
+	VisionRadius  int      `json:"vision_radius,omitempty"`
+	VisionOverlay bool     `json:"vision_overlay,omitempty"`
+	Squads        []string `json:"squads,omitempty"`
+	SquadRules    string   `json:"squad_rules,omitempty"`
+}
+
+type MapList struct {
+	Items []game.MapInfo `json:"items"`
+}
+
+// apiRoutes is the route table of the API. Tournaments have no endpoints:
+// the server has no tournaments yet, they are left to a follow-up.
+func apiRoutes() []apiRoute {
+	idParam := func(name, description string) apiParam {
+		return apiParam{Name: name, In: "path", Type: "integer", Description: description}
+	}
+	gameParam := apiParam{Name: "id", In: "path", Type: "string", Description: "Game ID"}
+
+	return []apiRoute{
+		{Method: "GET", Path: "/status", Summary: "Server status", Access: accessPublic, Response: StatusResponse{}, Handler: apiGetStatus},
+		{Method: "GET", Path: "/teams", Summary: "List teams", Access: accessAdmin, Scope: controllers.ScopeRead, Paginated: true, Response: TeamList{}, Handler: apiListTeams},
+		{Method: "GET", Path: "/teams/me", Summary: "Current team", Access: accessTeam, Scope: controllers.ScopeRead, Response: TeamResponse{}, Handler: apiGetMyTeam},
+		{Method: "GET", Path: "/snakes", Summary: "List snakes of the current team (all snakes for admins)", Access: accessTeam, Scope: controllers.ScopeRead, Paginated: true, Response: SnakeList{}, Handler: apiListSnakes},
+		{Method: "POST", Path: "/snakes", Summary: "Upload a new version of the team's snake", Access: accessTeam, Scope: controllers.ScopeUpload, Upload: "snake", Response: SnakeResponse{}, Status: http.StatusCreated, Handler: apiUploadSnake},
+		{Method: "GET", Path: "/snakes/{id}", Summary: "Get a snake and its server status", Access: accessTeam, Scope: controllers.ScopeRead, Params: []apiParam{idParam("id", "Snake ID")}, Response: SnakeResponse{}, Handler: apiGetSnake},
+		{Method: "POST", Path: "/snakes/{id}/rerun", Summary: "Restart a snake server", Access: accessAdmin, Params: []apiParam{idParam("id", "Snake ID")}, Response: SnakeResponse{}, Handler: apiRerunSnake},
+		{Method: "GET", Path: "/maps", Summary: "List the maps games can be played on", Access: accessTeam, Scope: controllers.ScopeRead, Response: MapList{}, Handler: apiListMaps},
+		{Method: "POST", Path: "/maps", Summary: "Upload a custom JSON or YAML map, replacing the map with the same ID", Access: accessAdmin, Upload: "map", Response: game.MapInfo{}, Status: http.StatusCreated, Handler: apiUploadMap},
+		{Method: "GET", Path: "/live", Summary: "List the running games, oldest first", Access: accessPublic, Response: LiveGameList{}, Handler: apiListLiveGames},
+		{Method: "GET", Path: "/games", Summary: "List games, newest first", Access: accessTeam, Scope: controllers.ScopeRead, Paginated: true, Response: GameList{}, Handler: apiListGames},
+		{Method: "POST", Path: "/games", Summary: "Start a practice game or, for admins, a battle", Access: accessTeam, Scope: controllers.ScopePlay, Request: CreateGameRequest{}, Response: GameResponse{}, Status: http.StatusCreated, Handler: apiCreateGame},
+		{Method: "POST", Path: "/games/{id}/rematch", Summary: "Play a game again with its seed and snake order, against the current version of each snake", Access: accessAdmin, Params: []apiParam{gameParam}, Response: GameResponse{}, Status: http.StatusCreated, Handler: apiRematchGame},
+		{Method: "POST", Path: "/games/{id}/abort", Summary: "Abort a running game, it is recorded with the given reason", Access: accessAdmin, Params: []apiParam{gameParam}, Request: AbortGameRequest{}, Response: GameResponse{}, Status: http.StatusAccepted, Handler: apiAbortGame},
+		{Method: "POST", Path: "/games/{id}/pause", Summary: "Pause a running game before its next turn", Access: accessAdmin, Params: []apiParam{gameParam}, Response: GameResponse{}, Handler: apiPauseGame},
+		{Method: "POST", Path: "/games/{id}/resume", Summary: "Resume a paused game", Access: accessAdmin, Params: []apiParam{gameParam}, Response: GameResponse{}, Handler: apiResumeGame},
+		{Method: "POST", Path: "/games/{id}/step", Summary: "Play one turn of a paused game, which stays paused", Access: accessAdmin, Params: []apiParam{gameParam}, Response: GameResponse{}, Handler: apiStepGame},
+		{Method: "POST", Path: "/games/{id}/delay", Summary: "Set the time a running game waits after each turn", Access: accessAdmin, Params: []apiParam{gameParam}, Request: GameDelayRequest{}, Response: GameResponse{}, Handler: apiSetGameDelay},
+		{Method: "GET", Path: "/games/{id}", Summary: "Get a game and its result", Access: accessTeam, Scope: controllers.ScopeRead, Params: []apiParam{gameParam}, Response: GameResponse{}, Handler: apiGetGame},
+		{Method: "GET", Path: "/replays/{id}", Summary: "Get the turn by turn replay of a finished game", Access: accessTeam, Scope: controllers.ScopeRead, Params: []apiParam{gameParam}, Response: game.Replay{}, Handler: apiGetReplay},
+	}
+}
+
+func apiGetStatus(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	writeJSON(w, http.StatusOK, StatusResponse{Status: "ok", Time: time.Now().UTC()})
+}
+
+func apiListTeams(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	teams := controllers.NewTeamController(database.DB)
+	teamsList, err := teams.ListTeams(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error listing teams", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	list := TeamList{Items: []TeamResponse{}}
+	for _, team := range paginate(teamsList, &page) {
+		list.Items = append(list.Items, newTeamResponse(&team))
+	}
+	list.Pagination = page
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func apiGetMyTeam(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	writeJSON(w, http.StatusOK, newTeamResponse(caller.Team))
+}
+
+func apiListSnakes(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	snakes := controllers.NewSnakeController(database.DB)
+	var snakesList []database.Snake
+	var err error
+	if caller.IsAdmin {
+		snakesList, err = snakes.ListSnakes(r.Context())
+	} else {
+		snakesList, err = snakes.ListTeamSnakes(r.Context(), caller.Team.ID)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error listing snakes", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	list := SnakeList{Items: []SnakeResponse{}}
+	for _, snake := range paginate(snakesList, &page) {
+		list.Items = append(list.Items, newSnakeResponse(&snake))
+	}
+	list.Pagination = page
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// apiFindSnake loads a snake the caller is allowed to see.
+func apiFindSnake(w http.ResponseWriter, r *http.Request, caller *apiCaller) (*database.Snake, bool) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+
+	snakes := controllers.NewSnakeController(database.DB)
+	snake, err := snakes.GetSnake(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, http.StatusNotFound, "not_found", "snake not found")
+			return nil, false
+		}
+		slog.ErrorContext(r.Context(), "api: error getting snake", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return nil, false
+	}
+	if !caller.IsAdmin && snake.TeamID != caller.Team.ID {
+		writeAPIError(w, http.StatusNotFound, "not_found", "snake not found")
+		return nil, false
+	}
+
+	return snake, true
+}
+
+func apiGetSnake(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	snake, ok := apiFindSnake(w, r, caller)
+	if !ok {
+		return
+	}
+
+	response := newSnakeResponse(snake)
+	status, err := controllers.GetServerManager().GetSnakeStatus(r.Context(), snake)
+	if err != nil {
+		status = game.StatusOffline
+	}
+	response.Status = string(status)
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// apiUploadSnake stores a snake file or project archive sent as multipart
+// form data, exactly like the dashboard upload form.
+func apiUploadSnake(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	codes := controllers.NewCodeController(database.DB)
+	code, err := codes.GetCode(r.Context(), caller.Team.CodeID)
+	if err != nil || code == nil {
+		slog.ErrorContext(r.Context(), "api: error getting team code", "team_id", caller.Team.ID, "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	snake, findings, err := acceptSnakeUpload(w, r, caller.Team, code.Code)
+	if err != nil {
+		writeAPIUploadError(w, err)
+		return
+	}
+
+	response := newSnakeResponse(snake)
+	response.Status = string(game.StatusLoading)
+	for _, f := range findings {
+		response.Warnings = append(response.Warnings, f.String())
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func writeAPIUploadError(w http.ResponseWriter, err error) {
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		writeAPIError(w, httpErr.status, "upload_rejected", httpErr.message)
+		return
+	}
+	slog.Error("api: error uploading snake", "err", err)
+	writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+}
+
+func apiRerunSnake(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	snake, ok := apiFindSnake(w, r, caller)
+	if !ok {
+		return
+	}
+
+	status := rerunSnake(r.Context(), snake.ID)
+	recordAudit(r, caller.Team, controllers.AuditRerun, "snake:"+strconv.FormatInt(snake.ID, 10), map[string]any{"status": status, "api": true})
+	if status != http.StatusOK {
+		writeAPIError(w, status, "rerun_failed", "could not restart snake server")
+		return
+	}
+
+	response := newSnakeResponse(snake)
+	response.Status = string(game.StatusOnline)
+	writeJSON(w, http.StatusOK, response)
+}
+
+func apiListMaps(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	writeJSON(w, http.StatusOK, MapList{Items: game.ListMaps()})
+}
+
+// apiUploadMap stores a map file sent as multipart form data, like the form of
+// /adm/maps.
+func apiUploadMap(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	customMap, err := acceptMapUpload(w, r)
+	if err != nil {
+		var httpErr *httpError
+		if errors.As(err, &httpErr) {
+			writeAPIError(w, httpErr.status, "bad_map", httpErr.message)
+			return
+		}
+		slog.ErrorContext(r.Context(), "api: error saving map", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+	recordAudit(r, caller.Team, controllers.AuditMapUpload, "map:"+customMap.ID, map[string]any{"version": customMap.Version, "api": true})
+
+	for _, info := range game.ListMaps() {
+		if info.ID == customMap.ID {
+			writeJSON(w, http.StatusCreated, info)
+			return
+		}
+	}
+}
+
+func apiListLiveGames(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	list := LiveGameList{Items: []LiveGameResponse{}}
+	for _, g := range controllers.NewGameController(database.DB).RunningGames() {
+		item := LiveGameResponse{
+			ID:        g.ID,
+			Snakes:    []GameSnakeResponse{},
+			Turn:      g.State.Turn(),
+			Alive:     g.State.Alive(),
+			Viewers:   g.Server.Viewers(),
+			Paused:    g.State.Paused(),
+			StartedAt: g.StartedAt,
+			StreamURL: "/game/" + g.ID,
+			WatchURL:  "/live/" + g.ID,
+		}
+		for _, s := range g.Snakes {
+			snake := GameSnakeResponse{Name: s.Name, Squad: s.Squad}
+			if s.SnakeID != 0 {
+				snake.SnakeID = &s.SnakeID
+			}
+			item.Snakes = append(item.Snakes, snake)
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func apiListGames(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	page, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	games := controllers.NewGameController(database.DB)
+	total, err := games.CountGames(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error counting games", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+	gamesList, err := games.ListGames(r.Context(), int64(page.PerPage), int64(page.offset()))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error listing games", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	list := GameList{Items: []GameResponse{}}
+	for _, g := range gamesList {
+		list.Items = append(list.Items, newGameResponse(&g, nil))
+	}
+	page.Total = total
+	list.Pagination = page
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func apiCreateGame(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	var req CreateGameRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	fog, err := game.ParseFogOptions(req.Fog, req.VisionRadius, req.VisionOverlay)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_fog", err.Error())
+		return
+	}
+	squadRules, err := game.ParseSquadRules(req.SquadRules)
+	if err == nil && len(req.Squads) > 0 {
+		err = game.CheckSquads(req.Squads, len(req.SnakeIDs))
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_squads", err.Error())
+		return
+	}
+
+	var gameSnakes []game.Snake
+	if len(req.SnakeIDs) > 0 {
+		if !caller.IsAdmin {
+			writeAPIError(w, http.StatusForbidden, "forbidden", "only admins can pick the snakes of a game")
+			return
+		}
+		gameSnakes, err = battleGameSnakes(r.Context(), req.SnakeIDs)
+		for i, squad := range req.Squads {
+			if i < len(gameSnakes) {
+				gameSnakes[i].Squad = squad
+			}
+		}
+	} else if len(req.Squads) > 0 {
+		writeAPIError(w, http.StatusBadRequest, "bad_squads", "squads are given with snake_ids")
+		return
+	} else {
+		gameSnakes, err = teamGameSnakes(r.Context(), caller.Team, req.Ghost)
+	}
+	if err != nil {
+		var httpErr *httpError
+		switch {
+		case errors.Is(err, errNoSnakes):
+			writeAPIError(w, http.StatusConflict, "no_snakes", "no snake of the team is online")
+		case errors.As(err, &httpErr):
+			writeAPIError(w, httpErr.status, "bad_snake", httpErr.message)
+		default:
+			slog.ErrorContext(r.Context(), "api: error building game", "err", err)
+			writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		}
+		return
+	}
+
+	if req.Map != "" {
+		if err := game.CheckMap(req.Map, len(gameSnakes)); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "bad_map", err.Error())
+			return
+		}
+	}
+
+	gameInfo, err := startGame(r.Context(), gameSnakes, controllers.GameOptions{Map: req.Map, Fog: fog, SquadRules: squadRules})
+	if errors.Is(err, controllers.ErrServerBusy) {
+		w.Header().Set("Retry-After", retryAfterBusy)
+		writeAPIError(w, http.StatusServiceUnavailable, "server_busy", "too many games running, try again later")
+		return
+	}
+	if errors.Is(err, controllers.ErrGamesClosed) {
+		writeAPIError(w, http.StatusServiceUnavailable, "shutting_down", "the server is shutting down")
+		return
+	}
+	if errors.Is(err, controllers.ErrInvalidGame) {
+		writeAPIError(w, http.StatusBadRequest, "bad_game", err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error recording game", "game_id", gameInfo.ID, "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "game started but could not be recorded")
+		return
+	}
+	if len(req.SnakeIDs) > 0 {
+		recordAudit(r, caller.Team, controllers.AuditBattle, "game:"+gameInfo.ID, map[string]any{"snake_ids": req.SnakeIDs, "map": req.Map, "fog": fog.Fog, "squads": req.Squads, "api": true})
+	} else {
+		recordAudit(r, caller.Team, controllers.AuditGame, "game:"+gameInfo.ID, map[string]any{"ghost": req.Ghost, "map": req.Map, "fog": fog.Fog, "api": true})
+	}
+
+	apiWriteGame(w, r, gameInfo.ID, http.StatusCreated)
+}
+
+// apiRematchGame starts a new game with the seed and snakes of a stored game.
+// Uploaded snakes play with their current version, external URLs are reused
+// as they are.
+func apiRematchGame(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	games := controllers.NewGameController(database.DB)
+	original, err := games.GetGame(r.Context(), r.PathValue("id"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error getting game", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+	if original == nil {
+		writeAPIError(w, http.StatusNotFound, "not_found", "game not found")
+		return
+	}
+	originalSnakes, err := games.ListGameSnakes(r.Context(), original.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error listing game snakes", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	var gameSnakes []game.Snake
+	for _, s := range originalSnakes {
+		if !s.SnakeID.Valid {
+			gameSnakes = append(gameSnakes, game.Snake{Name: s.Name, URL: s.Url, Squad: s.Squad.String})
+			continue
+		}
+		current, err := battleGameSnakes(r.Context(), []int64{s.SnakeID.Int64})
+		if err != nil {
+			var httpErr *httpError
+			if errors.As(err, &httpErr) {
+				writeAPIError(w, httpErr.status, "bad_snake", httpErr.message)
+				return
+			}
+			slog.ErrorContext(r.Context(), "api: error building rematch", "err", err)
+			writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+			return
+		}
+		// keep the original name and squad, they are part of every request
+		current[0].Name = s.Name
+		current[0].Squad = s.Squad.String
+		gameSnakes = append(gameSnakes, current[0])
+	}
+	if len(gameSnakes) == 0 {
+		writeAPIError(w, http.StatusConflict, "no_snakes", "the game has no snakes")
+		return
+	}
+
+	if err := game.CheckMap(original.Map.String, len(gameSnakes)); original.Map.Valid && err != nil {
+		writeAPIError(w, http.StatusConflict, "bad_map", err.Error())
+		return
+	}
+
+	gameInfo, err := startGame(r.Context(), gameSnakes, controllers.StoredGameOptions(original))
+	if errors.Is(err, controllers.ErrServerBusy) {
+		w.Header().Set("Retry-After", retryAfterBusy)
+		writeAPIError(w, http.StatusServiceUnavailable, "server_busy", "too many games running, try again later")
+		return
+	}
+	if errors.Is(err, controllers.ErrGamesClosed) {
+		writeAPIError(w, http.StatusServiceUnavailable, "shutting_down", "the server is shutting down")
+		return
+	}
+	if errors.Is(err, controllers.ErrInvalidGame) {
+		writeAPIError(w, http.StatusConflict, "bad_game", err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error recording game", "game_id", gameInfo.ID, "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "game started but could not be recorded")
+		return
+	}
+	recordAudit(r, caller.Team, controllers.AuditRematch, "game:"+gameInfo.ID, map[string]any{"original": original.ID, "seed": original.Seed, "api": true})
+
+	apiWriteGame(w, r, gameInfo.ID, http.StatusCreated)
+}
+
+func apiAbortGame(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	var req AbortGameRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = "aborted by an admin"
+	}
+
+	gameID := r.PathValue("id")
+	games := controllers.NewGameController(database.DB)
+	if !games.AbortGame(gameID, reason) {
+		writeAPIError(w, http.StatusConflict, "not_running", "the game is not running")
+		return
+	}
+	recordAudit(r, caller.Team, controllers.AuditGameAbort, "game:"+gameID, map[string]any{"reason": reason, "api": true})
+
+	apiWriteGame(w, r, gameID, http.StatusAccepted)
+}
+
+func apiPauseGame(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	apiControlGame(w, r, caller, game.ControlCommand{Command: game.ControlPause})
+}
+
+func apiResumeGame(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	apiControlGame(w, r, caller, game.ControlCommand{Command: game.ControlResume})
+}
+
+func apiStepGame(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	apiControlGame(w, r, caller, game.ControlCommand{Command: game.ControlStep})
+}
+
+func apiSetGameDelay(w http.ResponseWriter, r *http.Request, caller *apiCaller) {
+	var req GameDelayRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	apiControlGame(w, r, caller, game.ControlCommand{Command: game.ControlDelay, DelayMS: req.DelayMS})
+}
+
+// apiControlGame applies a control command to the game of the path.
+func apiControlGame(w http.ResponseWriter, r *http.Request, caller *apiCaller, cmd game.ControlCommand) {
+	gameID := r.PathValue("id")
+	err := controlGame(r, caller.Team, gameID, cmd, map[string]any{"api": true})
+	switch {
+	case errors.Is(err, controllers.ErrGameNotRunning):
+		writeAPIError(w, http.StatusConflict, "not_running", "the game is not running")
+	case errors.Is(err, game.ErrNotPaused):
+		writeAPIError(w, http.StatusConflict, "not_paused", err.Error())
+	case errors.Is(err, game.ErrBadTurnDelay):
+		writeAPIError(w, http.StatusBadRequest, "bad_delay", err.Error())
+	case err != nil:
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err.Error())
+	default:
+		apiWriteGame(w, r, gameID, http.StatusOK)
+	}
+}
+
+func apiGetGame(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	apiWriteGame(w, r, r.PathValue("id"), http.StatusOK)
+}
+
+func apiWriteGame(w http.ResponseWriter, r *http.Request, gameID string, status int) {
+	games := controllers.NewGameController(database.DB)
+	g, err := games.GetGame(r.Context(), gameID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error getting game", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+	if g == nil {
+		writeAPIError(w, http.StatusNotFound, "not_found", "game not found")
+		return
+	}
+
+	gameSnakes, err := games.ListGameSnakes(r.Context(), g.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error listing game snakes", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+
+	response := newGameResponse(g, gameSnakes)
+	response.Paused = games.IsPaused(g.ID)
+	response.TurnDelayMS = games.TurnDelay(g.ID)
+	response.Phase = string(games.Phase(g.ID))
+	writeJSON(w, status, response)
+}
+
+func apiGetReplay(w http.ResponseWriter, r *http.Request, _ *apiCaller) {
+	games := controllers.NewGameController(database.DB)
+	g, err := games.GetGame(r.Context(), r.PathValue("id"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error getting game", "err", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "internal server error")
+		return
+	}
+	if g == nil || !g.ReplayPath.Valid {
+		writeAPIError(w, http.StatusNotFound, "not_found", "replay not found")
+		return
+	}
+	if g.Status == "running" {
+		writeAPIError(w, http.StatusConflict, "game_running", "replay is available once the game is over")
+		return
+	}
+
+	f, err := os.Open(g.ReplayPath.String)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "not_found", "replay not found")
+		return
+	}
+	defer f.Close()
+
+	replay, err := game.ReadReplay(f)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: error reading replay", "game_id", g.ID, "err", err)
+		writeAPIError(w, http.StatusNotFound, "not_found", "replay is empty or corrupted")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, replay)
+}
+
+func newTeamResponse(team *database.Team) TeamResponse {
+	return TeamResponse{
+		ID:        team.ID,
+		Name:      team.Name,
+		IsAdmin:   team.IsAdmin.Valid && team.IsAdmin.Bool,
+		CreatedAt: nullTime(team.CreatedAt),
+	}
+}
+
+func newSnakeResponse(snake *database.Snake) SnakeResponse {
+	return SnakeResponse{
+		ID:        snake.ID,
+		TeamID:    snake.TeamID,
+		Lang:      snake.Lang,
+		CreatedAt: nullTime(snake.CreatedAt),
+		UpdatedAt: nullTime(snake.UpdatedAt),
+	}
+}
+
+func newGameResponse(g *database.Game, gameSnakes []database.GameSnake) GameResponse {
+	response := GameResponse{
+		ID:            g.ID,
+		Status:        g.Status,
+		Seed:          g.Seed,
+		Turns:         g.Turns,
+		Winner:        g.Winner.String,
+		WinnerSquad:   g.WinnerSquad.String,
+		SquadRules:    g.SquadRules.String,
+		IsDraw:        g.IsDraw.Valid && g.IsDraw.Bool,
+		AbortReason:   g.AbortReason.String,
+		TimeoutPolicy: g.TimeoutPolicy.String,
+		Map:           g.Map.String,
+		Fog:           g.Fog.String,
+		VisionRadius:  g.VisionRadius.Int64,
+		VisionOverlay: g.VisionOverlay.Bool,
+		CreatedAt:     nullTime(g.CreatedAt),
+		FinishedAt:    nullTime(g.FinishedAt),
+		StreamURL:     "/game/" + g.ID,
+		ReplayURL:     apiPrefix + "/replays/" + g.ID,
+	}
+	for _, s := range gameSnakes {
+		snake := GameSnakeResponse{
+			Name:     s.Name,
+			IsWinner: s.IsWinner.Valid && s.IsWinner.Bool,
+			Squad:    s.Squad.String,
+			Timeouts: s.Timeouts,
+		}
+		if s.SnakeID.Valid {
+			snake.SnakeID = &s.SnakeID.Int64
+		}
+		response.Snakes = append(response.Snakes, snake)
+	}
+	return response
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
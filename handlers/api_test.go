@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	schema "github.com/secomp2025/localsnake/sql"
+)
+
+// apiCredentials authenticate a test request: a team code for the session
+// cookie or an API token.
+type apiCredentials struct {
+	code  string
+	token string
+}
+
+// apiTestServer serves the API over a fresh database holding a team and an
+// admin team, each with a read token and a revoked one, and returns their
+// credentials.
+func apiTestServer(t *testing.T) (*http.ServeMux, map[string]apiCredentials) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	ctx := context.Background()
+	if err := database.Init(ctx, filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(ctx, schema.Schema); err != nil {
+		t.Fatal(err)
+	}
+
+	codes := controllers.NewCodeController(database.DB)
+	teams := controllers.NewTeamController(database.DB)
+	tokens := controllers.NewTokenController(database.DB)
+	credentials := map[string]apiCredentials{}
+	for _, name := range []string{"team", "admin"} {
+		code, err := codes.CreateCode(ctx, strings.ToUpper(name)+"CODE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		create := teams.CreateTeam
+		if name == "admin" {
+			create = teams.CreateAdminTeam
+		}
+		team, err := create(ctx, name, code.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, _, err := tokens.CreateToken(ctx, team.ID, "ci", []string{controllers.ScopeRead})
+		if err != nil {
+			t.Fatal(err)
+		}
+		credentials[name] = apiCredentials{code: code.Code}
+		credentials[name+" token"] = apiCredentials{token: token}
+
+		revoked, revokedToken, err := tokens.CreateToken(ctx, team.ID, "old", []string{controllers.ScopeRead})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tokens.RevokeToken(ctx, team.ID, revokedToken.ID); err != nil {
+			t.Fatal(err)
+		}
+		credentials[name+" revoked token"] = apiCredentials{token: revoked}
+	}
+	credentials["unknown token"] = apiCredentials{token: "lsk_0000000000000000"}
+
+	mux := http.NewServeMux()
+	RegisterAPIRoutes(mux)
+	return mux, credentials
+}
+
+func TestAPIAccess(t *testing.T) {
+	mux, credentials := apiTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		caller string
+		status int
+		// code is the code of the error answered, if any
+		code string
+	}{
+		{name: "public", method: "GET", path: "/status", status: http.StatusOK},
+		{name: "no credentials", method: "GET", path: "/teams/me", status: http.StatusUnauthorized, code: "unauthorized"},
+		{name: "unknown token", method: "GET", path: "/teams/me", caller: "unknown token", status: http.StatusUnauthorized, code: "unauthorized"},
+		{name: "revoked token", method: "GET", path: "/teams/me", caller: "admin revoked token", status: http.StatusUnauthorized, code: "unauthorized"},
+		{name: "unknown team code", method: "GET", path: "/teams/me", caller: "nobody", status: http.StatusUnauthorized, code: "unauthorized"},
+		{name: "session", method: "GET", path: "/teams/me", caller: "team", status: http.StatusOK},
+		{name: "token", method: "GET", path: "/teams/me", caller: "team token", status: http.StatusOK},
+		{name: "token without the scope", method: "POST", path: "/games", body: "{}", caller: "team token", status: http.StatusForbidden, code: "insufficient_scope"},
+		{name: "admin route of a team", method: "GET", path: "/teams", caller: "team", status: http.StatusForbidden, code: "forbidden"},
+		{name: "admin route of a team token", method: "GET", path: "/teams", caller: "team token", status: http.StatusForbidden, code: "forbidden"},
+		{name: "admin route", method: "GET", path: "/teams", caller: "admin", status: http.StatusOK},
+		{name: "admin route of an admin token", method: "GET", path: "/teams", caller: "admin token", status: http.StatusOK},
+		// the admin routes without a scope are only for sessions
+		{name: "unscoped admin route of an admin token", method: "POST", path: "/games/g/pause", caller: "admin token", status: http.StatusForbidden, code: "insufficient_scope"},
+		{name: "unscoped admin route", method: "POST", path: "/games/g/pause", caller: "admin", status: http.StatusConflict, code: "not_running"},
+		{name: "bad page", method: "GET", path: "/teams?page=0", caller: "admin", status: http.StatusBadRequest, code: "bad_request"},
+		{name: "bad page size", method: "GET", path: "/snakes?per_page=1000", caller: "team", status: http.StatusBadRequest, code: "bad_request"},
+		{name: "bad id", method: "GET", path: "/snakes/one", caller: "team", status: http.StatusBadRequest, code: "bad_request"},
+		{name: "missing snake", method: "GET", path: "/snakes/99", caller: "team", status: http.StatusNotFound, code: "not_found"},
+		{name: "missing game", method: "GET", path: "/games/g", caller: "team", status: http.StatusNotFound, code: "not_found"},
+		{name: "bad JSON", method: "POST", path: "/games/g/abort", body: "{", caller: "admin", status: http.StatusBadRequest, code: "bad_request"},
+		{name: "unknown field", method: "POST", path: "/games/g/abort", body: `{"why": "x"}`, caller: "admin", status: http.StatusBadRequest, code: "bad_request"},
+		{name: "unknown endpoint", method: "GET", path: "/tournaments", caller: "team", status: http.StatusNotFound, code: "not_found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, apiPrefix+test.path, strings.NewReader(test.body))
+			if test.caller != "" {
+				c, ok := credentials[test.caller]
+				if !ok {
+					c = apiCredentials{code: "NOBODY"}
+				}
+				if c.token != "" {
+					r.Header.Set("Authorization", "Bearer "+c.token)
+				} else {
+					r.AddCookie(&http.Cookie{Name: "team_code", Value: c.code})
+				}
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if w.Code != test.status {
+				t.Fatalf("got status %d with %s, want %d", w.Code, w.Body, test.status)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+				t.Errorf("got content type %q, want JSON", got)
+			}
+			if test.code == "" {
+				return
+			}
+			// every error has the same shape
+			var body ErrorResponse
+			decoder := json.NewDecoder(w.Body)
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&body); err != nil {
+				t.Fatalf("the error is not an ErrorResponse: %v", err)
+			}
+			if body.Error.Code != test.code || body.Error.Message == "" {
+				t.Errorf("got error %+v, want code %q and a message", body.Error, test.code)
+			}
+		})
+	}
+}
+
+func TestAPIPagination(t *testing.T) {
+	mux, credentials := apiTestServer(t)
+	r := httptest.NewRequest("GET", apiPrefix+"/teams?page=2&per_page=1", nil)
+	r.AddCookie(&http.Cookie{Name: "team_code", Value: credentials["admin"].code})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	var list TeamList
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Pagination != (Pagination{Page: 2, PerPage: 1, Total: 2}) || len(list.Items) != 1 {
+		t.Errorf("got %+v, want the second of two teams", list)
+	}
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	mux, _ := apiTestServer(t)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", apiPrefix+"/openapi.json", nil))
+	var document struct {
+		Paths map[string]map[string]struct {
+			OperationID string           `json:"operationId"`
+			Parameters  []map[string]any `json:"parameters"`
+			Security    []map[string]any `json:"security"`
+			Responses   map[string]any   `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&document); err != nil {
+		t.Fatal(err)
+	}
+
+	pathParam := regexp.MustCompile(`\{(\w+)\}`)
+	operations := 0
+	var ids []string
+	for _, route := range apiRoutes() {
+		op, ok := document.Paths[apiPrefix+route.Path][strings.ToLower(route.Method)]
+		if !ok {
+			t.Errorf("%s %s is missing from the document", route.Method, route.Path)
+			continue
+		}
+		operations++
+		ids = append(ids, op.OperationID)
+
+		for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			if !slices.ContainsFunc(op.Parameters, func(p map[string]any) bool { return p["name"] == match[1] && p["in"] == "path" }) {
+				t.Errorf("%s %s: the path parameter %s is not documented", route.Method, route.Path, match[1])
+			}
+		}
+		status := strconv.Itoa(cmp.Or(route.Status, http.StatusOK))
+		if _, ok := op.Responses[status]; !ok {
+			t.Errorf("%s %s: no %s response documented", route.Method, route.Path, status)
+		}
+		// tokens are documented on the routes with a scope only
+		tokens := slices.ContainsFunc(op.Security, func(s map[string]any) bool { _, ok := s["apiToken"]; return ok })
+		if public := route.Access == accessPublic; public != (len(op.Security) == 0) || tokens != (route.Scope != "") {
+			t.Errorf("%s %s: got security %v for access %d and scope %q", route.Method, route.Path, op.Security, route.Access, route.Scope)
+		}
+	}
+
+	// and nothing else is documented
+	documented := 0
+	for _, item := range document.Paths {
+		documented += len(item)
+	}
+	if documented != operations {
+		t.Errorf("the document has %d operations, the routes %d", documented, operations)
+	}
+	slices.Sort(ids)
+	if len(slices.Compact(ids)) != len(ids) {
+		t.Errorf("the operation IDs are not unique: %v", ids)
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/pages"
+)
+
+const (
+	auditEventsPerPage = 50
+	maxAuditValueLen   = 200
+)
+
+// recordAudit stores an audit event for the request. actor is nil for
+// requests without a session, e.g. a failed login. payload is a short
+// summary of the action; long string values are truncated.
+func recordAudit(r *http.Request, actor *database.Team, action string, target string, payload map[string]any) {
+	event := database.CreateAuditEventParams{
+		Action:     action,
+		Target:     sql.NullString{String: target, Valid: target != ""},
+		RemoteAddr: sql.NullString{String: r.RemoteAddr, Valid: r.RemoteAddr != ""},
+	}
+	if actor != nil {
+		event.TeamID = sql.NullInt64{Int64: actor.ID, Valid: true}
+		event.Actor = actor.Name
+	}
+
+	if len(payload) > 0 {
+		for k, v := range payload {
+			if s, ok := v.(string); ok && len(s) > maxAuditValueLen {
+				payload[k] = s[:maxAuditValueLen] + "…"
+			}
+		}
+		if data, err := json.Marshal(payload); err == nil {
+			event.Payload = sql.NullString{String: string(data), Valid: true}
+		}
+	}
+
+	// The event must be stored even when the client went away.
+	audit := controllers.NewAuditController(database.DB)
+	if _, err := audit.RecordEvent(context.WithoutCancel(r.Context()), event); err != nil {
+		slog.ErrorContext(r.Context(), "audit: failed to record event", "action", action, "target", target, "err", err)
+	}
+}
+
+// AdminAuditHandler renders the audit log for admins, filtered by action,
+// team and date range.
+func AdminAuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	team := sessionAdmin(r)
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	filter, params := parseAuditFilter(r)
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	// fetch one extra row to know whether there is a next page
+	params.Limit = auditEventsPerPage + 1
+	params.Offset = int64((page - 1) * auditEventsPerPage)
+
+	audit := controllers.NewAuditController(database.DB)
+	events, err := audit.ListEvents(r.Context(), params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "admin: error listing audit events", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	hasNext := len(events) > auditEventsPerPage
+	if hasNext {
+		events = events[:auditEventsPerPage]
+	}
+
+	teams := controllers.NewTeamController(database.DB)
+	teamsList, err := teams.ListTeams(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "admin: error listing teams", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var modelTeams []models.Team
+	for _, t := range teamsList {
+		modelTeams = append(modelTeams, models.Team{ID: t.ID, Name: t.Name})
+	}
+
+	var modelEvents []models.AuditEvent
+	for _, e := range events {
+		modelEvents = append(modelEvents, models.AuditEvent{
+			ID:         e.ID,
+			Actor:      e.Actor,
+			Action:     e.Action,
+			Target:     e.Target.String,
+			RemoteAddr: e.RemoteAddr.String,
+			Payload:    e.Payload.String,
+			CreatedAt:  e.CreatedAt.Time,
+		})
+	}
+
+	query := r.URL.Query()
+	pageURL := func(p int) string {
+		query.Set("page", strconv.Itoa(p))
+		return "/adm/audit?" + query.Encode()
+	}
+	var prevURL, nextURL string
+	if page > 1 {
+		prevURL = pageURL(page - 1)
+	}
+	if hasNext {
+		nextURL = pageURL(page + 1)
+	}
+	query.Del("page")
+	exportURL := "/adm/audit/export?" + query.Encode()
+
+	templ.Handler(pages.AdminAudit(modelEvents, filter, controllers.AuditActions, modelTeams, prevURL, nextURL, exportURL)).ServeHTTP(w, r)
+}
+
+// auditEventJSON is a line of the JSONL export.
+type auditEventJSON struct {
+	ID         int64           `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	TeamID     *int64          `json:"team_id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	Target     string          `json:"target,omitempty"`
+	RemoteAddr string          `json:"remote_addr,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// AdminAuditExportHandler downloads the events matching the audit page
+// filter as JSON lines, newest first.
+func AdminAuditExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	team := sessionAdmin(r)
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_, params := parseAuditFilter(r)
+	params.Limit = -1
+
+	audit := controllers.NewAuditController(database.DB)
+	events, err := audit.ListEvents(r.Context(), params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "admin: error exporting audit events", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, team, controllers.AuditExport, "", map[string]any{"filter": r.URL.RawQuery, "events": len(events)})
+
+	filename := "audit-" + time.Now().Format("20060102-150405") + ".jsonl"
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		line := auditEventJSON{
+			ID:         e.ID,
+			CreatedAt:  e.CreatedAt.Time,
+			Actor:      e.Actor,
+			Action:     e.Action,
+			Target:     e.Target.String,
+			RemoteAddr: e.RemoteAddr.String,
+		}
+		if e.TeamID.Valid {
+			line.TeamID = &e.TeamID.Int64
+		}
+		if e.Payload.Valid && json.Valid([]byte(e.Payload.String)) {
+			line.Payload = json.RawMessage(e.Payload.String)
+		}
+		if err := enc.Encode(line); err != nil {
+			slog.ErrorContext(r.Context(), "admin: error writing audit export", "err", err)
+			return
+		}
+	}
+}
+
+// parseAuditFilter reads the audit filter from the query string. Unknown
+// actions and malformed dates are ignored; until is inclusive.
+func parseAuditFilter(r *http.Request) (models.AuditFilter, database.ListAuditEventsParams) {
+	query := r.URL.Query()
+	var filter models.AuditFilter
+	var params database.ListAuditEventsParams
+
+	if action := query.Get("action"); slices.Contains(controllers.AuditActions, action) {
+		filter.Action = action
+		params.Action = sql.NullString{String: action, Valid: true}
+	}
+	if teamID, err := strconv.ParseInt(query.Get("team"), 10, 64); err == nil && teamID > 0 {
+		filter.TeamID = teamID
+		params.TeamID = sql.NullInt64{Int64: teamID, Valid: true}
+	}
+	if since, err := time.Parse(time.DateOnly, query.Get("since")); err == nil {
+		filter.Since = query.Get("since")
+		params.Since = sql.NullTime{Time: since, Valid: true}
+	}
+	if until, err := time.Parse(time.DateOnly, query.Get("until")); err == nil {
+		filter.Until = query.Get("until")
+		params.Until = sql.NullTime{Time: until.AddDate(0, 0, 1), Valid: true}
+	}
+	return filter, params
+}
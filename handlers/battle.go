@@ -1,7 +1,9 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,6 +15,16 @@ import (
 	"github.com/secomp2025/localsnake/templates/pages"
 )
 
+// httpError carries the status code a handler should answer with.
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
 func HandleBattle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -31,81 +43,157 @@ func HandleBattle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	snakes := controllers.NewSnakeController(database.DB)
-	teams := controllers.NewTeamController(database.DB)
-
-	var gameSnakes []game.Snake
-
+	var snakeIDs []int64
 	for _, snakeID := range snake_ids {
 		snakeIDInt, err := strconv.ParseInt(snakeID, 10, 64)
 		if err != nil {
 			http.Error(w, "snake id must be an integer", http.StatusBadRequest)
 			return
 		}
-		snake, err := snakes.GetSnake(r.Context(), snakeIDInt)
-		if err != nil {
-			http.Error(w, "snake not found", http.StatusNotFound)
+		snakeIDs = append(snakeIDs, snakeIDInt)
+	}
+
+	mapName := r.URL.Query().Get("map")
+	if mapName != "" {
+		if err := game.CheckMap(mapName, len(snakeIDs)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		if snake == nil {
-			log.Println("snake not found:", snakeID)
-			http.Error(w, "snake not found", http.StatusNotFound)
+	}
+
+	visionRadius := 0
+	if param := r.URL.Query().Get("vision_radius"); param != "" {
+		var err error
+		if visionRadius, err = strconv.Atoi(param); err != nil {
+			http.Error(w, "vision_radius must be an integer", http.StatusBadRequest)
 			return
 		}
+	}
+	fog, err := game.ParseFogOptions(r.URL.Query().Get("fog"), visionRadius, r.URL.Query().Get("vision_overlay") != "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-		snakeTeam, err := teams.GetTeam(r.Context(), snake.TeamID)
-		if err != nil {
-			http.Error(w, "team not found", http.StatusNotFound)
+	// squads=a,a,b,b puts the snakes, in the order of snake_ids, in squads
+	var squads []string
+	if param := r.URL.Query().Get("squads"); param != "" {
+		squads = strings.Split(param, ",")
+	}
+	squadRules, err := game.ParseSquadRules(r.URL.Query().Get("squad_rules"))
+	if err == nil {
+		err = game.CheckSquads(squads, len(snakeIDs))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	gameSnakes, err := battleGameSnakes(r.Context(), snakeIDs)
+	if err != nil {
+		var httpErr *httpError
+		if errors.As(err, &httpErr) {
+			http.Error(w, httpErr.message, httpErr.status)
 			return
 		}
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	for i, squad := range squads {
+		gameSnakes[i].Squad = squad
+	}
+
+	slog.DebugContext(r.Context(), "battle: creating game", "snakes", len(gameSnakes), "map", mapName, "fog", fog.Fog, "squads", squads)
+
+	gameInfo, err := startGame(r.Context(), gameSnakes, controllers.GameOptions{Map: mapName, Fog: fog, SquadRules: squadRules})
+	if errors.Is(err, controllers.ErrServerBusy) {
+		w.Header().Set("Retry-After", retryAfterBusy)
+		http.Error(w, busyMessage, http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, controllers.ErrGamesClosed) {
+		http.Error(w, "O servidor está sendo desligado.", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, controllers.ErrInvalidGame) {
+		http.Error(w, "Partida inválida: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error recording game", "game_id", gameInfo.ID, "err", err)
+	}
+	recordAudit(r, sessionTeam(r), controllers.AuditBattle, "game:"+gameInfo.ID, map[string]any{"snake_ids": snakeIDs, "map": mapName, "fog": fog.Fog, "squads": squads})
+
+	templ.Handler(pages.Battle(gameInfo.ID, sessionAdmin(r) != nil)).ServeHTTP(w, r)
+}
+
+// battleGameSnakes resolves the given snake IDs into game snakes, starting
+// their servers when they are not running yet.
+func battleGameSnakes(ctx context.Context, snakeIDs []int64) ([]game.Snake, error) {
+	snakes := controllers.NewSnakeController(database.DB)
+	teams := controllers.NewTeamController(database.DB)
+
+	var gameSnakes []game.Snake
+
+	for _, snakeID := range snakeIDs {
+		snake, err := snakes.GetSnake(ctx, snakeID)
+		if err != nil {
+			return nil, &httpError{http.StatusNotFound, "snake not found"}
+		}
+		if snake == nil {
+			slog.WarnContext(ctx, "snake not found", "snake_id", snakeID)
+			return nil, &httpError{http.StatusNotFound, "snake not found"}
+		}
+
+		snakeTeam, err := teams.GetTeam(ctx, snake.TeamID)
+		if err != nil {
+			return nil, &httpError{http.StatusNotFound, "team not found"}
+		}
 		if snakeTeam == nil {
-			log.Println("team not found:", snake.TeamID)
-			http.Error(w, "team not found", http.StatusNotFound)
-			return
+			slog.WarnContext(ctx, "team not found", "team_id", snake.TeamID)
+			return nil, &httpError{http.StatusNotFound, "team not found"}
+		}
+
+		if controllers.GetServerManager().Runtime() == controllers.RuntimeStdio {
+			gameSnake, err := controllers.GetServerManager().StdioSnake(ctx, snake)
+			if err != nil {
+				slog.ErrorContext(ctx, "snake cannot play over stdio", "snake_id", snake.ID, "err", err)
+				return nil, &httpError{http.StatusInternalServerError, "snake server not created"}
+			}
+			gameSnake.Name = snakeTeam.Name
+			gameSnakes = append(gameSnakes, gameSnake)
+			continue
 		}
 
 		snakeServer := controllers.GetServerManager().GetServer(snake.ID)
 		if snakeServer == nil {
-			log.Println("snake server not found:", snake.ID)
+			slog.WarnContext(ctx, "snake server not found", "snake_id", snake.ID)
 
-			err := controllers.GetServerManager().ManageSnake(r.Context(), snake)
+			err := controllers.GetServerManager().ManageSnake(ctx, snake)
 			if err != nil {
-				log.Println("snake server not created:", snake.ID)
-				http.Error(w, "snake server not created", http.StatusInternalServerError)
-				return
+				slog.ErrorContext(ctx, "snake server not created", "snake_id", snake.ID)
+				return nil, &httpError{http.StatusInternalServerError, "snake server not created"}
 			}
 
 			snakeServer = controllers.GetServerManager().GetServer(snake.ID)
 			if snakeServer == nil {
-				log.Println("snake server not found after creation:", snake.ID)
-				http.Error(w, "snake server not found", http.StatusInternalServerError)
-				return
+				slog.WarnContext(ctx, "snake server not found after creation", "snake_id", snake.ID)
+				return nil, &httpError{http.StatusInternalServerError, "snake server not found"}
 			}
 		}
 
 		gameSnake := game.Snake{
-			Name: snakeTeam.Name,
-			URL:  snakeServer.Addr,
+			Name:    snakeTeam.Name,
+			URL:     snakeServer.Addr,
+			SnakeID: snake.ID,
 		}
 		gameSnakes = append(gameSnakes, gameSnake)
 	}
 
 	if len(gameSnakes) == 0 {
-		http.Error(w, "no snakes found", http.StatusNotFound)
-		return
+		return nil, &httpError{http.StatusNotFound, "no snakes found"}
 	}
 
-	log.Println("game snakes: ", len(gameSnakes))
-
-	gameController := controllers.NewGameController()
-	gameInfo := gameController.CreateGame(gameSnakes)
-
-	log.Println("game id: ", gameInfo.ID)
-
-	boardManager.lock.Lock()
-	defer boardManager.lock.Unlock()
-
-	boardManager.handlers[gameInfo.ID] = gameInfo.Server
-
-	templ.Handler(pages.Battle(gameInfo.ID)).ServeHTTP(w, r)
+	return gameSnakes, nil
 }
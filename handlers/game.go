@@ -1,30 +1,27 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
-	"sync"
 
 	"github.com/secomp2025/localsnake/controllers"
 	"github.com/secomp2025/localsnake/database"
 	"github.com/secomp2025/localsnake/game"
 )
 
-type globalBoardServerHandler struct {
-	lock     sync.Mutex
-	handlers map[string]*game.BoardServer
-}
-
-var boardManager globalBoardServerHandler
+// errNoSnakes is returned when none of the requested snakes can play.
+var errNoSnakes = errors.New("no snakes online")
 
-func init() {
-	boardManager = globalBoardServerHandler{
-		lock:     sync.Mutex{},
-		handlers: make(map[string]*game.BoardServer),
-	}
-}
+// Answer to controllers.ErrServerBusy, games are short so a few seconds
+// usually free a slot.
+const (
+	busyMessage    = "Servidor ocupado, muitas partidas em andamento. Tente novamente em instantes."
+	retryAfterBusy = "5"
+)
 
 func CreateTeamGameHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -42,8 +39,12 @@ func CreateTeamGameHandler(w http.ResponseWriter, r *http.Request) {
 	if ghost := r.URL.Query().Get("ghost"); ghost == "true" {
 		enableGhost = true
 	}
+	// in human games the team plays from the keyboard against its snake or
+	// the built-in bot
+	human := r.URL.Query().Get("human") == "true"
+	againstBot := r.URL.Query().Get("opponent") == "bot"
 
-	log.Println("creating game for team code: ", team_code)
+	slog.DebugContext(r.Context(), "game: creating game for team", "team_code", team_code)
 
 	codes := controllers.NewCodeController(database.DB)
 	code, err := codes.FindCode(r.Context(), team_code)
@@ -59,17 +60,64 @@ func CreateTeamGameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	snakes := controllers.NewSnakeController(database.DB)
-	team_snakes, err := snakes.ListTeamSnakes(r.Context(), team.ID)
-
+	var gameSnakes []game.Snake
+	if human && againstBot {
+		gameSnakes = []game.Snake{{Name: "Bot", URL: game.BotURL}}
+	} else {
+		gameSnakes, err = teamGameSnakes(r.Context(), team, enableGhost && !human)
+	}
 	if err != nil {
+		if errors.Is(err, errNoSnakes) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	if len(team_snakes) == 0 {
-		w.WriteHeader(http.StatusNoContent)
+	if human {
+		gameSnakes = append([]game.Snake{{Name: team.Name + " (você)", URL: game.HumanURL(teamPlayer(team))}}, gameSnakes...)
+	}
+
+	gameInfo, err := startGame(r.Context(), gameSnakes, controllers.GameOptions{})
+	if errors.Is(err, controllers.ErrServerBusy) {
+		w.Header().Set("Retry-After", retryAfterBusy)
+		http.Error(w, busyMessage, http.StatusServiceUnavailable)
 		return
 	}
+	if errors.Is(err, controllers.ErrGamesClosed) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, controllers.ErrInvalidGame) {
+		slog.ErrorContext(r.Context(), "error starting game", "err", err)
+		http.Error(w, "Não foi possível iniciar a partida.", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error recording game", "game_id", gameInfo.ID, "err", err)
+	}
+	payload := map[string]any{"ghost": enableGhost}
+	if human {
+		payload["human"] = true
+		payload["opponent"] = gameSnakes[1].Name
+	}
+	recordAudit(r, team, controllers.AuditGame, "game:"+gameInfo.ID, payload)
+
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprint(w, gameInfo.ID)
+}
+
+// teamGameSnakes builds the practice game for a team: its latest snake and,
+// with ghost enabled, the previous upload as an opponent.
+func teamGameSnakes(ctx context.Context, team *database.Team, enableGhost bool) ([]game.Snake, error) {
+	snakes := controllers.NewSnakeController(database.DB)
+	team_snakes, err := snakes.ListTeamSnakes(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(team_snakes) == 0 {
+		return nil, errNoSnakes
+	}
 
 	if !enableGhost {
 		if len(team_snakes) > 1 {
@@ -81,19 +129,29 @@ func CreateTeamGameHandler(w http.ResponseWriter, r *http.Request) {
 	var gameSnakes []game.Snake
 
 	for _, snake := range team_snakes {
+		if controllers.GetServerManager().Runtime() == controllers.RuntimeStdio {
+			gameSnake, err := controllers.GetServerManager().StdioSnake(ctx, &snake)
+			if err != nil {
+				slog.WarnContext(ctx, "snake cannot play over stdio", "snake_id", snake.ID, "err", err)
+				continue
+			}
+			gameSnake.Name = team.Name
+			gameSnakes = append(gameSnakes, gameSnake)
+			continue
+		}
 		snakeServer := controllers.GetServerManager().GetServer(snake.ID)
 		if snakeServer == nil {
 			continue
 		}
 		gameSnakes = append(gameSnakes, game.Snake{
-			Name: team.Name,
-			URL:  snakeServer.Addr,
+			Name:    team.Name,
+			URL:     snakeServer.Addr,
+			SnakeID: snake.ID,
 		})
 	}
 
 	if len(gameSnakes) == 0 {
-		w.WriteHeader(http.StatusNoContent)
-		return
+		return nil, errNoSnakes
 	}
 
 	if len(gameSnakes) > 1 {
@@ -102,19 +160,24 @@ func CreateTeamGameHandler(w http.ResponseWriter, r *http.Request) {
 		// gameSnakes[0].IsGhost = true
 	}
 
-	// games := controllers.NewGameController()
-	gameController := controllers.NewGameController()
-	gameInfo := gameController.CreateGame(gameSnakes)
-
-	log.Println("game id: ", gameInfo.ID)
+	return gameSnakes, nil
+}
 
-	boardManager.lock.Lock()
-	defer boardManager.lock.Unlock()
+// startGame creates the game, /game/<game_id> streams it while the game
+// controller keeps it in memory. The returned GameInfo is valid even when
+// the error is not nil, since only recording the game failed, unless the
+// error is controllers.ErrGamesClosed, controllers.ErrServerBusy or
+// controllers.ErrInvalidGame.
+func startGame(ctx context.Context, gameSnakes []game.Snake, opts controllers.GameOptions) (controllers.GameInfo, error) {
+	gameController := controllers.NewGameController(database.DB)
+	gameInfo, err := gameController.CreateGame(ctx, gameSnakes, opts)
+	if errors.Is(err, controllers.ErrGamesClosed) || errors.Is(err, controllers.ErrServerBusy) || errors.Is(err, controllers.ErrInvalidGame) {
+		return gameInfo, err
+	}
 
-	boardManager.handlers[gameInfo.ID] = gameInfo.Server
+	slog.DebugContext(ctx, "game: created", "game_id", gameInfo.ID)
 
-	w.WriteHeader(http.StatusCreated)
-	fmt.Fprint(w, gameInfo.ID)
+	return gameInfo, err
 }
 
 // Handle /game/<game_id> and /game/<game_id>/events
@@ -124,7 +187,7 @@ func GameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("handling game id: ", r.URL.Path)
+	slog.DebugContext(r.Context(), "game: showing game", "path", r.URL.Path)
 
 	splits := strings.Split(r.URL.Path, "/")
 	if len(splits) < 3 {
@@ -134,24 +197,65 @@ func GameHandler(w http.ResponseWriter, r *http.Request) {
 	gameID := splits[2]
 	is_event := len(splits) > 3 && splits[3] == "events"
 
-	boardManager.lock.Lock()
-	defer boardManager.lock.Unlock()
-
-	if game, ok := boardManager.handlers[gameID]; ok {
+	if liveGame, ok := controllers.NewGameController(database.DB).LiveGame(gameID); ok {
+		boardServer := liveGame.Server
 		if is_event {
-			game.HandleGame(w, r)
+			boardServer.HandleGame(w, r)
 			return
 		} else {
-			if r.Header.Get("Connection") != "Upgrade" || r.Header.Get("Upgrade") != "websocket" {
+			if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
 				w.WriteHeader(http.StatusMethodNotAllowed)
 				return
 			}
-			game.HandleWebsocket(w, r)
+			var control func(cmd game.ControlCommand) error
+			// the admins drive the game from the viewer, the teams steer
+			// their human snakes
+			if team := sessionTeam(r); team != nil {
+				admin := team.IsAdmin.Valid && team.IsAdmin.Bool
+				control = func(cmd game.ControlCommand) error {
+					switch {
+					case cmd.Command == game.ControlMove:
+						return liveGame.State.Steer(teamPlayer(team), cmd.Move)
+					case admin:
+						return controlGame(r, team, gameID, cmd, map[string]any{"websocket": true})
+					default:
+						return game.ErrControlForbidden
+					}
+				}
+			}
+			boardServer.HandleWebsocket(w, r, control)
+			return
 		}
-
-		// already have a handler for this game
-		w.WriteHeader(http.StatusNotFound)
-		return
 	}
 	w.WriteHeader(http.StatusNotFound)
 }
+
+// teamPlayer is the player of the human snakes of a team, see game.HumanURL.
+func teamPlayer(team *database.Team) string {
+	return fmt.Sprintf("team-%d", team.ID)
+}
+
+// controlAuditActions are the audit actions of the game control commands.
+var controlAuditActions = map[string]string{
+	game.ControlPause:  controllers.AuditGamePause,
+	game.ControlResume: controllers.AuditGameResume,
+	game.ControlStep:   controllers.AuditGameStep,
+	game.ControlDelay:  controllers.AuditGameDelay,
+}
+
+// controlGame applies a control command to a running game on behalf of an
+// admin and records it in the audit log along with payload, which may be
+// nil.
+func controlGame(r *http.Request, team *database.Team, gameID string, cmd game.ControlCommand, payload map[string]any) error {
+	if err := controllers.NewGameController(database.DB).ControlGame(gameID, cmd); err != nil {
+		return err
+	}
+	if cmd.Command == game.ControlDelay {
+		if payload == nil {
+			payload = map[string]any{}
+		}
+		payload["delay_ms"] = cmd.DelayMS
+	}
+	recordAudit(r, team, controlAuditActions[cmd.Command], "game:"+gameID, payload)
+	return nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/pages"
+)
+
+// LiveHandler lists the running games for spectators. With ?follow=1 the
+// page opens the newest game by itself, for a projector.
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	templ.Handler(pages.Live(runningGames(), r.URL.Query().Get("follow") == "1")).ServeHTTP(w, r)
+}
+
+// LiveWatchHandler shows a game on the board as it is played. With
+// ?follow=1 the page moves on to the next game once this one is over.
+func LiveWatchHandler(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+	if _, ok := controllers.NewGameController(database.DB).LiveGame(gameID); !ok {
+		http.Redirect(w, r, "/live", http.StatusSeeOther)
+		return
+	}
+
+	templ.Handler(pages.LiveWatch(gameID, r.URL.Query().Get("follow") == "1", sessionAdmin(r) != nil)).ServeHTTP(w, r)
+}
+
+// runningGames lists the running games, oldest first.
+func runningGames() []models.RunningGame {
+	var modelGames []models.RunningGame
+	for _, g := range controllers.NewGameController(database.DB).RunningGames() {
+		var names []string
+		for _, s := range g.Snakes {
+			names = append(names, s.Name)
+		}
+		modelGames = append(modelGames, models.RunningGame{
+			ID:        g.ID,
+			Snakes:    names,
+			StartedAt: g.StartedAt,
+			Turn:      g.State.Turn(),
+			Alive:     g.State.Alive(),
+			Viewers:   g.Server.Viewers(),
+			Paused:    g.State.Paused(),
+			Starting:  g.Phase() == controllers.GameCreated,
+		})
+	}
+	return modelGames
+}
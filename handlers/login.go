@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
@@ -45,7 +45,7 @@ func GetLogin(w http.ResponseWriter, r *http.Request) {
 	if c := GetCookieValue(r, "team_code"); c != "" {
 		code, err := codes.FindCode(r.Context(), c)
 		if err != nil {
-			log.Println("LoginHandler: error getting code:", err)
+			slog.ErrorContext(r.Context(), "LoginHandler: error getting code", "err", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			fmt.Fprint(w, "Internal server error")
 			return
@@ -58,7 +58,7 @@ func GetLogin(w http.ResponseWriter, r *http.Request) {
 
 		team, err := teams.GetTeamByCode(r.Context(), code.ID)
 		if err != nil {
-			log.Println("LoginHandler: error getting team:", err)
+			slog.ErrorContext(r.Context(), "LoginHandler: error getting team", "err", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			fmt.Fprint(w, "Internal server error")
 			return
@@ -95,20 +95,21 @@ func PostLogin(w http.ResponseWriter, r *http.Request) {
 
 	form_code = strings.ToUpper(form_code)
 
-	log.Println("LoginHandler: code =", form_code)
+	slog.DebugContext(r.Context(), "LoginHandler: code", "code", form_code)
 
 	codes := controllers.NewCodeController(database.DB)
 	teams := controllers.NewTeamController(database.DB)
 
 	code, err := codes.FindCode(r.Context(), form_code)
 	if err != nil {
-		log.Println("LoginHandler: error getting code:", err)
+		slog.ErrorContext(r.Context(), "LoginHandler: error getting code", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal server error")
 		return
 	}
 	if code == nil {
-		log.Println("LoginHandler: code not found")
+		slog.WarnContext(r.Context(), "LoginHandler: code not found")
+		recordAudit(r, nil, controllers.AuditLoginFailed, "", map[string]any{"code": form_code})
 		w.WriteHeader(http.StatusNotFound)
 		fmt.Fprint(w, "Código não encontrado")
 		return
@@ -116,13 +117,13 @@ func PostLogin(w http.ResponseWriter, r *http.Request) {
 
 	team, err := teams.GetTeamByCode(r.Context(), code.ID)
 	if err != nil {
-		log.Println("LoginHandler: error getting team:", err)
+		slog.ErrorContext(r.Context(), "LoginHandler: error getting team", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal server error")
 		return
 	}
 	if team == nil {
-		log.Println("LoginHandler: team not found")
+		slog.WarnContext(r.Context(), "LoginHandler: team not found")
 		// send to registration page
 		templ.Handler(pages.Register(form_code)).ServeHTTP(w, r)
 		return
@@ -134,6 +135,8 @@ func PostLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, team, controllers.AuditLogin, "", nil)
+
 	// Bound -> set cookie and redirect to home
 	http.SetCookie(w, &http.Cookie{
 		Name:     "team_code",
@@ -170,7 +173,7 @@ func PostLoginAdm(w http.ResponseWriter, r *http.Request) {
 	}
 	team_id_int, err := strconv.ParseInt(team_id, 10, 64)
 	if err != nil {
-		log.Println("LoginHandler: error parsing team ID:", err)
+		slog.ErrorContext(r.Context(), "LoginHandler: error parsing team ID", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal server error")
 		return
@@ -179,13 +182,13 @@ func PostLoginAdm(w http.ResponseWriter, r *http.Request) {
 	teams := controllers.NewTeamController(database.DB)
 	team, err := teams.GetTeamByCode(r.Context(), team_id_int)
 	if err != nil {
-		log.Println("LoginHandler: error getting team:", err)
+		slog.ErrorContext(r.Context(), "LoginHandler: error getting team", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal server error")
 		return
 	}
 	if team == nil {
-		log.Println("LoginHandler: team not found")
+		slog.WarnContext(r.Context(), "LoginHandler: team not found")
 		// send to registration page
 		templ.Handler(pages.Login()).ServeHTTP(w, r)
 		return
@@ -193,13 +196,16 @@ func PostLoginAdm(w http.ResponseWriter, r *http.Request) {
 
 	form_password := r.FormValue("password")
 	if form_password != ADMIN_PASSWD {
-		log.Println("LoginHandler: password not correct")
+		slog.WarnContext(r.Context(), "LoginHandler: password not correct")
+		recordAudit(r, nil, controllers.AuditLoginFailed, "team:"+team_id, map[string]any{"reason": "wrong password"})
 		// Basic validation: re-render login with a minimal message (could be enhanced later)
 		w.WriteHeader(http.StatusBadRequest)
 		templ.Handler(pages.PasswordLogin(team_id_int)).ServeHTTP(w, r)
 		return
 	}
 
+	recordAudit(r, team, controllers.AuditAdminLogin, "", nil)
+
 	// Bound -> set cookie and redirect to home
 	http.SetCookie(w, &http.Cookie{
 		Name:     "team_code",
@@ -3,6 +3,8 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/secomp2025/localsnake/controllers"
 )
 
 // Logout clears the team_code cookie and redirects to /login
@@ -12,6 +14,9 @@ func Logout(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, "Method not allowed")
 		return
 	}
+	if team := sessionTeam(r); team != nil {
+		recordAudit(r, team, controllers.AuditLogout, "", nil)
+	}
 	http.SetCookie(w, &http.Cookie{
 		Name:   "team_code",
 		Value:  "",
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/metrics"
+)
+
+// The gauges are read from the game and snake server managers on every
+// scrape, the counters and histograms are kept where things happen.
+var (
+	gamesGauge = metrics.NewGauge(
+		"localsnake_games",
+		"Games in memory, by phase: created, running or finished.",
+		"phase",
+	)
+	maxGamesGauge = metrics.NewGauge(
+		"localsnake_games_max_running",
+		"How many games may be created or running at the same time.",
+	)
+	viewersGauge = metrics.NewGauge(
+		"localsnake_websocket_viewers",
+		"Websocket viewers connected to the games in memory.",
+	)
+	processesGauge = metrics.NewGauge(
+		"localsnake_snake_processes",
+		"Managed snake server processes, by status: running, exited or stopped.",
+		"status",
+	)
+	portsGauge = metrics.NewGauge(
+		"localsnake_ports_in_use",
+		"Ports of the snake server pool that are reserved.",
+	)
+	busyPortsGauge = metrics.NewGauge(
+		"localsnake_ports_busy",
+		"Ports of the snake server pool bound by another process when last tried.",
+	)
+	maxPortsGauge = metrics.NewGauge(
+		"localsnake_ports_max",
+		"Size of the snake server port pool.",
+	)
+)
+
+// MetricsHandler serves the server metrics in the Prometheus text format.
+// Like /health it needs no session, so a local Prometheus can scrape it.
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	games := controllers.NewGameController(database.DB)
+	phases := map[controllers.GamePhase]int{}
+	viewers := 0
+	for _, g := range games.LiveGames() {
+		phases[g.Phase()]++
+		viewers += g.Server.Viewers()
+	}
+	for _, phase := range []controllers.GamePhase{controllers.GameCreated, controllers.GameRunning, controllers.GameFinished} {
+		gamesGauge.Set(float64(phases[phase]), string(phase))
+	}
+	maxGamesGauge.Set(float64(games.MaxRunningGames()))
+	viewersGauge.Set(float64(viewers))
+
+	processesGauge.Reset()
+	portsGauge.Set(0)
+	busyPortsGauge.Set(0)
+	if manager := controllers.GetServerManager(); manager != nil {
+		for status, n := range manager.ProcessStatuses() {
+			processesGauge.Set(float64(n), status)
+		}
+		usage := manager.PortUsage()
+		portsGauge.Set(float64(usage.Reserved))
+		busyPortsGauge.Set(float64(usage.Busy))
+	}
+	maxPortsGauge.Set(controllers.MAX_PORTS)
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := metrics.WriteText(w); err != nil {
+		slog.ErrorContext(r.Context(), "Error writing metrics", "err", err)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/components"
+)
+
+// MoveStatsHandler renders the move latency panel of the dashboard.
+func MoveStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	team := sessionTeam(r)
+	if team == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	stats, err := controllers.NewGameController(database.DB).TeamMoveStats(r.Context(), team.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "move stats: error listing stats", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var modelStats []models.MoveStats
+	for _, s := range stats {
+		modelStats = append(modelStats, newMoveStatsModel(&s))
+	}
+
+	templ.Handler(components.MoveStatsPanel(modelStats)).ServeHTTP(w, r)
+}
+
+func newMoveStatsModel(s *controllers.MoveStats) models.MoveStats {
+	return models.MoveStats{
+		SnakeID:      s.SnakeID,
+		Games:        s.Games,
+		Moves:        s.Moves,
+		Timeouts:     s.Timeouts,
+		StatusErrors: s.StatusErrors,
+		DecodeErrors: s.DecodeErrors,
+		P50:          s.P50(),
+		P95:          s.P95(),
+		P99:          s.P99(),
+		Budget:       s.Budget(),
+	}
+}
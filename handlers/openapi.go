@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// buildOpenAPI generates the OpenAPI 3 document of the JSON API from the
+// route table, deriving schemas from the request and response structs.
+func buildOpenAPI(routes []apiRoute) map[string]any {
+	gen := schemaGenerator{schemas: map[string]any{}}
+	paths := map[string]any{}
+
+	for _, route := range routes {
+		op := map[string]any{
+			"summary":     route.Summary,
+			"operationId": operationID(route),
+		}
+
+		var params []any
+		for _, p := range route.Params {
+			params = append(params, map[string]any{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.In == "path",
+				"description": p.Description,
+				"schema":      map[string]any{"type": p.Type},
+			})
+		}
+		if route.Paginated {
+			params = append(params,
+				map[string]any{"name": "page", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "default": 1}},
+				map[string]any{"name": "per_page", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "maximum": maxPerPage, "default": defaultPerPage}},
+			)
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if route.Upload != "" {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{"multipart/form-data": map[string]any{"schema": map[string]any{
+					"type":       "object",
+					"required":   []string{route.Upload},
+					"properties": map[string]any{route.Upload: map[string]any{"type": "string", "format": "binary"}},
+				}}},
+			}
+		}
+		if route.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": gen.schema(reflect.TypeOf(route.Request))}},
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		errorBody := map[string]any{
+			"description": "Error",
+			"content":     map[string]any{"application/json": map[string]any{"schema": gen.schema(reflect.TypeOf(ErrorResponse{}))}},
+		}
+		responses := map[string]any{
+			strconv.Itoa(status): map[string]any{
+				"description": http.StatusText(status),
+				"content":     map[string]any{"application/json": map[string]any{"schema": gen.schema(reflect.TypeOf(route.Response))}},
+			},
+			"default": errorBody,
+		}
+		op["responses"] = responses
+
+		var notes []string
+		if route.Access != accessPublic {
+			security := []any{map[string]any{"teamCode": []any{}}}
+			if route.Scope != "" {
+				security = append(security, map[string]any{"apiToken": []any{}})
+				notes = append(notes, "API tokens need the `"+route.Scope+"` scope.")
+			} else {
+				notes = append(notes, "Not available to API tokens, only to the session of the team.")
+			}
+			op["security"] = security
+		}
+		if route.Access == accessAdmin {
+			notes = append(notes, "Requires an admin team.")
+		}
+		if len(notes) > 0 {
+			op["description"] = strings.Join(notes, " ")
+		}
+
+		p := apiPrefix + route.Path
+		item, _ := paths[p].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[p] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Localsnake API",
+			"version": "1",
+			"description": "Teams call the API with their session cookie or an API token. Tokens only call the routes " +
+				"of their scopes: the admin routes that change games, snakes or maps take no scope and are " +
+				"only available to the session of an admin team.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]any{
+				"teamCode": map[string]any{"type": "apiKey", "in": "cookie", "name": "team_code"},
+				"apiToken": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func operationID(route apiRoute) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, part := range strings.Split(route.Path, "/") {
+		part = strings.Trim(part, "{}")
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+type schemaGenerator struct {
+	schemas map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns the JSON schema of t, registering named structs as
+// components and referencing them.
+func (gen *schemaGenerator) schema(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		s := gen.schema(t.Elem())
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": gen.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": gen.schema(t.Elem())}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, ok := gen.schemas[name]; !ok {
+			// reserve the name first so recursive types terminate
+			gen.schemas[name] = map[string]any{}
+			gen.schemas[name] = gen.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (gen *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = gen.schema(field.Type)
+			if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// schemaName names API types after themselves and prefixes types from other
+// packages with the package name, e.g. client.Game becomes ClientGame.
+func schemaName(t reflect.Type) string {
+	pkg := path.Base(t.PkgPath())
+	if pkg == "handlers" {
+		return t.Name()
+	}
+	runes := []rune(pkg)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes) + t.Name()
+}
@@ -3,8 +3,9 @@ package handlers
 import (
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/secomp2025/localsnake/controllers"
@@ -43,14 +44,14 @@ func Register(w http.ResponseWriter, r *http.Request) {
 	form_name := strings.Join(strings.Fields(rawName), " ")
 
 	if form_code == "" {
-		log.Printf("Register: missing code: code=%s, name=%s\n", form_code, form_name)
+		slog.WarnContext(r.Context(), "Register: missing code", "code", form_code, "name", form_name)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprint(w, "Código inválido")
 		return
 	}
 
 	if form_name == "" {
-		log.Printf("Register: missing name: code=%s, name=%s\n", form_code, form_name)
+		slog.WarnContext(r.Context(), "Register: missing name", "code", form_code, "name", form_name)
 		w.WriteHeader(http.StatusBadRequest)
 		fmt.Fprint(w, "Nome do time deve conter apenas caracteres ASCII e ter no máximo 40 caracteres")
 		return
@@ -68,7 +69,7 @@ func Register(w http.ResponseWriter, r *http.Request) {
 
 	c, err := codes.FindCode(r.Context(), form_code)
 	if err != nil {
-		log.Println("Register: error getting code:", err)
+		slog.ErrorContext(r.Context(), "Register: error getting code", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal server error")
 		return
@@ -82,22 +83,22 @@ func Register(w http.ResponseWriter, r *http.Request) {
 	// Check if there is already a team bound to this code
 	team, err := teams.GetTeamByCode(r.Context(), c.ID)
 	if err != nil {
-		log.Println("Register: error getting team by code:", err)
+		slog.ErrorContext(r.Context(), "Register: error getting team by code", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "Internal server error")
 		return
 	}
 	if team != nil {
 		// Already bound -> behave like a successful login
-		log.Println("Register: team already bound to code")
+		slog.WarnContext(r.Context(), "Register: team already bound to code")
 		setLoginCookieAndRedirect(w, r, form_code, "/")
 		return
 	}
 
 	// Create the team
-	_, err = teams.CreateTeam(r.Context(), form_name, c.ID)
+	team, err = teams.CreateTeam(r.Context(), form_name, c.ID)
 	if err != nil {
-		log.Println("Register: error creating the team:", err)
+		slog.ErrorContext(r.Context(), "Register: error creating the team", "err", err)
 
 		var sqlite_err *sqlite.Error
 		if errors.As(err, &sqlite_err) {
@@ -114,6 +115,8 @@ func Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, team, controllers.AuditRegister, "team:"+strconv.FormatInt(team.ID, 10), map[string]any{"name": form_name})
+
 	// Set cookie and redirect to home
 	setLoginCookieAndRedirect(w, r, form_code, "/")
 }
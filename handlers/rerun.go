@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/secomp2025/localsnake/controllers"
 	"github.com/secomp2025/localsnake/database"
 )
 
+// RerunRequest is the body accepted by /rerun and the API rerun endpoint.
+type RerunRequest struct {
+	SnakeID int64 `json:"snake_id"`
+}
+
 func RerunHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		w.WriteHeader(http.StatusNotFound)
@@ -21,7 +28,6 @@ func RerunHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	codes := controllers.NewCodeController(database.DB)
-	snakes := controllers.NewSnakeController(database.DB)
 
 	code, err := codes.FindCode(r.Context(), teamCode)
 	if err != nil {
@@ -34,41 +40,40 @@ func RerunHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// get data from request { "snake_id": "1" } json
-	var data map[string]interface{}
+	// get data from request { "snake_id": 1 } json
+	var data RerunRequest
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	snakeID := data["snake_id"]
-	if snakeID == nil {
+	if data.SnakeID == 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	snakeIDFloat, ok := snakeID.(float64)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
+	status := rerunSnake(r.Context(), data.SnakeID)
+	recordAudit(r, sessionTeam(r), controllers.AuditRerun, "snake:"+strconv.FormatInt(data.SnakeID, 10), map[string]any{"status": status})
+
+	w.WriteHeader(status)
+}
 
-	snakeModel, err := snakes.GetSnake(r.Context(), int64(snakeIDFloat))
+// rerunSnake restarts the server of a snake and returns the resulting HTTP status.
+func rerunSnake(ctx context.Context, snakeID int64) int {
+	snakes := controllers.NewSnakeController(database.DB)
+
+	snakeModel, err := snakes.GetSnake(ctx, snakeID)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
+		return http.StatusNotFound
 	}
 
 	if snakeModel == nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
+		return http.StatusNotFound
 	}
 
-	controllers.GetServerManager().StopAndRemoveSnake(snakeModel.ID)
-	if err := controllers.GetServerManager().ManageSnake(r.Context(), snakeModel); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	if err := controllers.GetServerManager().RestartSnake(ctx, snakeModel); err != nil {
+		return http.StatusInternalServerError
 	}
 
-	w.WriteHeader(http.StatusOK)
+	return http.StatusOK
 }
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/models"
+	"github.com/secomp2025/localsnake/templates/components"
+)
+
+// TokensHandler renders the API token panel of the dashboard on GET and
+// creates a token on POST.
+func TokensHandler(w http.ResponseWriter, r *http.Request) {
+	team := sessionTeam(r)
+	if team == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	tokens := controllers.NewTokenController(database.DB)
+
+	var created string
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		name := strings.TrimSpace(r.FormValue("name"))
+		if name == "" || len(name) > 64 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var token *database.ApiToken
+		var err error
+		created, token, err = tokens.CreateToken(r.Context(), team.ID, name, r.Form["scope"])
+		if err != nil {
+			if errors.Is(err, controllers.ErrInvalidScope) {
+				SetHeader(w, "HX-Trigger", `{"show-toast": {"message": "Escolha ao menos um escopo.", "type": "error"}}`)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			slog.ErrorContext(r.Context(), "tokens: error creating token", "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		recordAudit(r, team, controllers.AuditTokenCreate, "token:"+strconv.FormatInt(token.ID, 10), map[string]any{"name": name, "scopes": token.Scopes})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	renderTokenPanel(w, r, team, created)
+}
+
+// RevokeTokenHandler revokes one of the team's tokens.
+func RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	team := sessionTeam(r)
+	if team == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	tokens := controllers.NewTokenController(database.DB)
+	if err := tokens.RevokeToken(r.Context(), team.ID, id); err != nil {
+		slog.ErrorContext(r.Context(), "tokens: error revoking token", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, team, controllers.AuditTokenRevoke, "token:"+strconv.FormatInt(id, 10), nil)
+
+	renderTokenPanel(w, r, team, "")
+}
+
+func renderTokenPanel(w http.ResponseWriter, r *http.Request, team *database.Team, created string) {
+	tokens := controllers.NewTokenController(database.DB)
+	tokenList, err := tokens.ListTeamTokens(r.Context(), team.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "tokens: error listing tokens", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var modelTokens []models.ApiToken
+	for _, token := range tokenList {
+		modelTokens = append(modelTokens, models.ApiToken{
+			ID:         token.ID,
+			Name:       token.Name,
+			Prefix:     token.Prefix,
+			Scopes:     controllers.TokenScopes(&token),
+			CreatedAt:  token.CreatedAt.Time,
+			LastUsedAt: nullTime(token.LastUsedAt),
+			Revoked:    token.RevokedAt.Valid,
+		})
+	}
+
+	templ.Handler(components.TokenPanel(modelTokens, created)).ServeHTTP(w, r)
+}
+
+// sessionTeam returns the team logged in through the team_code cookie, or nil.
+func sessionTeam(r *http.Request) *database.Team {
+	team, err := teamFromCode(r.Context(), GetCookieValue(r, "team_code"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "session: error getting team", "err", err)
+		return nil
+	}
+	return team
+}
+
+// sessionAdmin returns the logged in team when it is the admin team, or nil.
+func sessionAdmin(r *http.Request) *database.Team {
+	team := sessionTeam(r)
+	if team == nil || !team.IsAdmin.Valid || !team.IsAdmin.Bool {
+		return nil
+	}
+	return team
+}
+
+// teamFromCode resolves the team bound to a login code. It returns nil when
+// the code is unknown or not bound to a team.
+func teamFromCode(ctx context.Context, teamCode string) (*database.Team, error) {
+	if teamCode == "" {
+		return nil, nil
+	}
+
+	codes := controllers.NewCodeController(database.DB)
+	code, err := codes.FindCode(ctx, teamCode)
+	if err != nil || code == nil {
+		return nil, err
+	}
+
+	teams := controllers.NewTeamController(database.DB)
+	return teams.GetTeamByCode(ctx, code.ID)
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"html"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -21,7 +23,7 @@ const uploadPath = "uploads"
 // It returns a small HTML fragment indicating the result.
 func UploadSnake(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		log.Printf("upload: method not allowed: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		slog.WarnContext(r.Context(), "upload: method not allowed", "method", r.Method)
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		fmt.Fprint(w, "Method not allowed")
 		return
@@ -33,7 +35,7 @@ func UploadSnake(w http.ResponseWriter, r *http.Request) {
 	// Validate session
 	teamCode := GetCookieValue(r, "team_code")
 	if teamCode == "" {
-		log.Printf("upload: missing team_code cookie from %s", r.RemoteAddr)
+		slog.WarnContext(r.Context(), "upload: missing team_code cookie")
 		w.WriteHeader(http.StatusUnauthorized)
 		fmt.Fprint(w, "Sessão inválida. Faça login novamente.")
 		return
@@ -43,7 +45,7 @@ func UploadSnake(w http.ResponseWriter, r *http.Request) {
 	codes := controllers.NewCodeController(database.DB)
 	code, err := codes.FindCode(r.Context(), teamCode)
 	if err != nil || code == nil {
-		log.Printf("upload: invalid code: team_code=%q err=%v", teamCode, err)
+		slog.WarnContext(r.Context(), "upload: invalid code", "team_code", teamCode, "err", err)
 		w.WriteHeader(http.StatusUnauthorized)
 		fmt.Fprint(w, "Código inválido.")
 		return
@@ -51,56 +53,132 @@ func UploadSnake(w http.ResponseWriter, r *http.Request) {
 	teams := controllers.NewTeamController(database.DB)
 	team, err := teams.GetTeamByCode(r.Context(), code.ID)
 	if err != nil || team == nil {
-		log.Printf("upload: team not found for code_id=%d team_code=%q err=%v", code.ID, teamCode, err)
+		slog.WarnContext(r.Context(), "upload: team not found", "code_id", code.ID, "team_code", teamCode, "err", err)
 		w.WriteHeader(http.StatusUnauthorized)
 		fmt.Fprint(w, "Time inválido.")
 		return
 	}
 
+	_, findings, err := acceptSnakeUpload(w, r, team, teamCode)
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+
+	if len(findings) > 0 {
+		SetHeader(w, "HX-Trigger", `{"show-toast": {"message": "Upload aceito com avisos.", "type": "info"}}`)
+		fmt.Fprint(w, "<div class=\"text-amber-700\">Arquivo salvo, mas com avisos:<ul class=\"list-disc ml-5\">")
+		for _, f := range findings {
+			fmt.Fprintf(w, "<li>%s</li>", html.EscapeString(f.String()))
+		}
+		fmt.Fprint(w, "</ul></div>")
+		return
+	}
+
+	// Success: send ASCII-only HX-Trigger header (Unicode escaped) to avoid mojibake in headers.
+	SetHeader(w, "HX-Trigger", `{"show-toast": {"message": "Upload conclu\u00EDdo com sucesso.", "type": "success"}}`)
+	fmt.Fprintf(w, "<div class=\"text-emerald-700\">Arquivo salvo</div>")
+}
+
+// snakeUpload is a parsed upload: a single source file or the files of a
+// project archive, with the snake language detected.
+type snakeUpload struct {
+	header     *multipart.FileHeader
+	archive    bool
+	lang       string
+	entryPoint string // relative path of the entry file, empty for C projects
+	files      []archiveEntry
+	size       int64 // total uncompressed size
+}
+
+// parseSnakeUpload reads the "snake" file of a multipart upload and checks
+// its size and extension. The returned file must be closed by the caller.
+func parseSnakeUpload(w http.ResponseWriter, r *http.Request) (multipart.File, *snakeUpload, error) {
 	// Limit request body to 2MB to avoid large uploads
 	const maxUpload = 2 << 20 // 2 MiB
 	r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
 	if err := r.ParseMultipartForm(maxUpload); err != nil {
-		log.Printf("upload: ParseMultipartForm failed: team_code=%q err=%v", teamCode, err)
-		w.WriteHeader(http.StatusRequestEntityTooLarge)
-		fmt.Fprint(w, "Arquivo excede o limite de 2MB")
-		return
+		slog.ErrorContext(r.Context(), "upload: ParseMultipartForm failed", "err", err)
+		return nil, nil, &httpError{status: http.StatusRequestEntityTooLarge, message: "Arquivo excede o limite de 2MB"}
 	}
 
 	file, header, err := r.FormFile("snake")
 	if err != nil {
-		log.Printf("upload: missing form file 'snake': team_code=%q err=%v", teamCode, err)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, "Arquivo não recebido")
-		return
+		slog.WarnContext(r.Context(), "upload: missing form file 'snake'", "err", err)
+		return nil, nil, &httpError{status: http.StatusBadRequest, message: "Arquivo não recebido"}
 	}
-	defer file.Close()
 
 	// Validate extension
 	validExt := map[string]bool{".py": true, ".js": true, ".c": true}
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	if !validExt[ext] {
-		log.Printf("upload: invalid extension: filename=%q ext=%q team_code=%q", header.Filename, ext, teamCode)
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprint(w, "Formato inválido. Envie um arquivo .py, .js ou .c")
-		return
+	ext := archiveExt(header.Filename)
+	if ext == "" {
+		ext = strings.ToLower(filepath.Ext(header.Filename))
 	}
 
-	dstPath, shouldReturn := saveSnakeFile(teamCode, teamCode, w, ext, file, header)
-	if shouldReturn {
-		return
+	var upload *snakeUpload
+	switch {
+	case ext == ".zip" || ext == ".tar.gz":
+		upload, err = readSnakeArchive(ext, file, header)
+	case validExt[ext]:
+		upload = &snakeUpload{
+			header:     header,
+			lang:       ext,
+			entryPoint: "snake" + ext,
+			files: []archiveEntry{{
+				name: header.Filename,
+				open: func() (io.ReadCloser, error) { return io.NopCloser(io.NewSectionReader(file, 0, header.Size)), nil },
+				size: header.Size,
+			}},
+			size: header.Size,
+		}
+	default:
+		slog.WarnContext(r.Context(), "upload: invalid extension", "filename", header.Filename, "ext", ext)
+		err = &httpError{status: http.StatusBadRequest, message: "Formato inválido. Envie um arquivo .py, .js, .c, .zip ou .tar.gz"}
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, upload, nil
+}
+
+// storeSnakeUpload saves a single source file or extracts a project archive
+// and returns the snake path.
+func storeSnakeUpload(teamCode string, upload *snakeUpload) (string, error) {
+	if upload.archive {
+		return saveSnakeArchive(teamCode, upload)
+	}
+	src, err := upload.files[0].open()
+	if err != nil {
+		return "", err
 	}
+	defer src.Close()
+	return saveSnakeFile(teamCode, upload.lang, src, upload.header)
+}
 
+// writeUploadError renders an upload failure as the plain fragment the
+// dashboard form expects.
+func writeUploadError(w http.ResponseWriter, err error) {
+	var httpErr *httpError
+	if !errors.As(err, &httpErr) {
+		httpErr = &httpError{status: http.StatusInternalServerError, message: "Falha ao salvar snake"}
+	}
+	w.WriteHeader(httpErr.status)
+	fmt.Fprint(w, httpErr.message)
+}
+
+// installTeamSnake records an uploaded file as the team's snake and hands it
+// to the snake server manager.
+func installTeamSnake(ctx context.Context, team *database.Team, dstPath string, lang string) (*database.Snake, error) {
 	// Update snake in database
-	log.Println("upload: creating controller")
+	slog.DebugContext(ctx, "upload: creating controller")
 	snakes := controllers.NewSnakeController(database.DB)
-	log.Println("upload: listing team snakes")
-	team_snakes, err := snakes.ListTeamSnakes(r.Context(), team.ID)
+	slog.DebugContext(ctx, "upload: listing team snakes")
+	team_snakes, err := snakes.ListTeamSnakes(ctx, team.ID)
 	if err != nil {
-		log.Printf("upload: failed to list snakes: team_code=%q err=%v", teamCode, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Falha ao listar snakes")
-		return
+		slog.ErrorContext(ctx, "upload: failed to list snakes", "team_id", team.ID, "err", err)
+		return nil, &httpError{status: http.StatusInternalServerError, message: "Falha ao listar snakes"}
 	}
 
 	snake_server_manager := controllers.GetServerManager()
@@ -111,44 +189,38 @@ func UploadSnake(w http.ResponseWriter, r *http.Request) {
 
 		original := team_snakes[0]
 		original.Path = dstPath
-		original.Lang = ext
-		if snake_model, err = snakes.UpdateSnake(r.Context(), &original); err != nil {
-			log.Printf("upload: failed to update snake: team_code=%q err=%v", teamCode, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprint(w, "Falha ao atualizar snake")
-			return
+		original.Lang = lang
+		if snake_model, err = snakes.UpdateSnake(ctx, &original); err != nil {
+			slog.ErrorContext(ctx, "upload: failed to update snake", "team_id", team.ID, "err", err)
+			return nil, &httpError{status: http.StatusInternalServerError, message: "Falha ao atualizar snake"}
 		}
 	} else {
-		log.Println("upload: creating snake")
-		snake_model, err = snakes.CreateSnake(r.Context(), &database.Snake{
+		slog.DebugContext(ctx, "upload: creating snake")
+		snake_model, err = snakes.CreateSnake(ctx, &database.Snake{
 			TeamID: team.ID,
 			Path:   dstPath,
-			Lang:   ext,
+			Lang:   lang,
 		})
 		if err != nil {
-			log.Printf("upload: failed to create snake: team_code=%q err=%v", teamCode, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			fmt.Fprint(w, "Falha ao criar snake")
-			return
+			slog.ErrorContext(ctx, "upload: failed to create snake", "team_id", team.ID, "err", err)
+			return nil, &httpError{status: http.StatusInternalServerError, message: "Falha ao criar snake"}
 		}
 	}
-	log.Println("upload: managing snake")
-	snake_server_manager.ManageSnake(r.Context(), snake_model)
+	slog.DebugContext(ctx, "upload: managing snake")
+	snake_server_manager.ManageSnake(ctx, snake_model)
 
-	// Success: send ASCII-only HX-Trigger header (Unicode escaped) to avoid mojibake in headers.
-	SetHeader(w, "HX-Trigger", `{"show-toast": {"message": "Upload conclu\u00EDdo com sucesso.", "type": "success"}}`)
-	fmt.Fprintf(w, "<div class=\"text-emerald-700\">Arquivo salvo</div>")
+	return snake_model, nil
 }
 
-func saveSnakeFile(teamCod string, teamCode string, w http.ResponseWriter, ext string, file multipart.File, header *multipart.FileHeader) (string, bool) {
-	dir := filepath.Join(uploadPath, teamCod)
+// saveSnakeFile stores the uploaded file as uploads/<teamCode>/snake<ext>,
+// keeping the previous version as snake_prev<ext>. Failures are returned as
+// *httpError carrying the message shown to the team.
+func saveSnakeFile(teamCode string, ext string, file io.Reader, header *multipart.FileHeader) (string, error) {
+	dir := filepath.Join(uploadPath, teamCode)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		log.Printf("upload: MkdirAll failed: dir=%q team_code=%q err=%v", dir, teamCode, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Não foi possível criar diretório de uploads")
-		return "", true
+		slog.Error("upload: MkdirAll failed", "dir", dir, "team_code", teamCode, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Não foi possível criar diretório de uploads"}
 	}
-
 	// Destination path is snake.<ext>
 	dstPath := filepath.Join(dir, "snake"+ext)
 
@@ -160,12 +232,12 @@ func saveSnakeFile(teamCod string, teamCode string, w http.ResponseWriter, ext s
 			prev := filepath.Join(dir, "snake_prev"+e)
 			// Remove existing previous if present, then move current to previous
 			if remErr := os.Remove(prev); remErr == nil {
-				log.Printf("upload: removed existing previous: %s", prev)
+				slog.Info("upload: removed existing previous", "path", prev)
 			}
 			if renErr := os.Rename(old, prev); renErr != nil {
-				log.Printf("upload: failed to move current to previous: old=%s prev=%s err=%v", old, prev, renErr)
+				slog.Error("upload: failed to move current to previous", "old", old, "prev", prev, "err", renErr)
 			} else {
-				log.Printf("upload: moved current to previous: %s -> %s", old, prev)
+				slog.Info("upload: moved current to previous", "old", old, "prev", prev)
 			}
 		}
 	}
@@ -173,10 +245,8 @@ func saveSnakeFile(teamCod string, teamCode string, w http.ResponseWriter, ext s
 	// Save file atomically: write to temp, then rename
 	tmpFile, err := os.CreateTemp(dir, "snake-*.tmp")
 	if err != nil {
-		log.Printf("upload: CreateTemp failed: dir=%q err=%v", dir, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Falha ao preparar arquivo temporário")
-		return "", true
+		slog.Error("upload: CreateTemp failed", "dir", dir, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao preparar arquivo temporário"}
 	}
 	tmpName := tmpFile.Name()
 	defer func() {
@@ -184,35 +254,27 @@ func saveSnakeFile(teamCod string, teamCode string, w http.ResponseWriter, ext s
 		// Best-effort cleanup on error
 		if _, statErr := os.Stat(dstPath); errors.Is(statErr, os.ErrNotExist) {
 			if rmErr := os.Remove(tmpName); rmErr == nil {
-				log.Printf("upload: cleaned temp file: %s", tmpName)
+				slog.Debug("upload: cleaned temp file", "tmp", tmpName)
 			}
 		}
 	}()
 
 	if _, err := io.Copy(tmpFile, file); err != nil {
-		log.Printf("upload: io.Copy failed: tmp=%q filename=%q team_code=%q err=%v", tmpName, header.Filename, teamCode, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Falha ao salvar arquivo")
-		return "", true
+		slog.Error("upload: io.Copy failed", "tmp", tmpName, "filename", header.Filename, "team_code", teamCode, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao salvar arquivo"}
 	}
 	if err := tmpFile.Sync(); err != nil {
-		log.Printf("upload: tmpFile.Sync failed: tmp=%q err=%v", tmpName, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Falha ao sincronizar arquivo")
-		return "", true
+		slog.Error("upload: tmpFile.Sync failed", "tmp", tmpName, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao sincronizar arquivo"}
 	}
 	if err := tmpFile.Close(); err != nil {
-		log.Printf("upload: tmpFile.Close failed: tmp=%q err=%v", tmpName, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Falha ao finalizar arquivo")
-		return "", true
+		slog.Error("upload: tmpFile.Close failed", "tmp", tmpName, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao finalizar arquivo"}
 	}
 	if err := os.Rename(tmpName, dstPath); err != nil {
-		log.Printf("upload: rename failed: tmp=%q dst=%q err=%v", tmpName, dstPath, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprint(w, "Falha ao mover arquivo para destino")
-		return "", true
+		slog.Error("upload: rename failed", "tmp", tmpName, "dst", dstPath, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao mover arquivo para destino"}
 	}
 
-	return dstPath, false
+	return dstPath, nil
 }
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/secomp2025/localsnake/controllers"
+)
+
+// Limits applied to multi-file snake projects after decompression.
+const (
+	maxArchiveFiles     = 100
+	maxArchiveFileBytes = 2 << 20  // 2 MiB
+	maxArchiveBytes     = 10 << 20 // 10 MiB
+)
+
+// keptProjectVersions is how many extracted project versions are kept per
+// team: the current one and the previous one.
+const keptProjectVersions = 2
+
+// archiveExt returns the archive kind of an uploaded file name, or "" when it
+// is not an archive.
+func archiveExt(filename string) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ".zip"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ".tar.gz"
+	}
+	return ""
+}
+
+// archiveEntry is a regular file of an upload. For archives the name is
+// relative to the project root.
+type archiveEntry struct {
+	name string
+	open func() (io.ReadCloser, error)
+	size int64
+}
+
+// readSnakeArchive lists the files of a zip or tar.gz project and detects its
+// language from its entry point: snake.py for Python, snake.js for
+// JavaScript, and any .c sources for C, which are all compiled together. A
+// single top-level directory wrapping the project is stripped.
+func readSnakeArchive(ext string, file multipart.File, header *multipart.FileHeader) (*snakeUpload, error) {
+	var entries []archiveEntry
+	var err error
+	switch ext {
+	case ".zip":
+		entries, err = zipEntries(file, header.Size)
+	case ".tar.gz":
+		entries, err = tarGzEntries(file)
+	default:
+		err = fmt.Errorf("unknown archive type %q", ext)
+	}
+	if err != nil {
+		slog.Warn("upload: invalid archive", "filename", header.Filename, "err", err)
+		return nil, archiveError(err)
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.name
+	}
+	root := commonRoot(names)
+
+	upload := &snakeUpload{header: header, archive: true}
+	for _, entry := range entries {
+		entry.name = strings.TrimPrefix(path.Clean(entry.name), root)
+		upload.files = append(upload.files, entry)
+		upload.size += entry.size
+	}
+
+	upload.lang, upload.entryPoint, err = projectEntryPoint(upload.files)
+	if err != nil {
+		return nil, archiveError(err)
+	}
+	return upload, nil
+}
+
+// saveSnakeArchive extracts a project into a new version directory
+// uploads/<teamCode>/v<N> and returns the snake path. For C the path is the
+// project directory.
+func saveSnakeArchive(teamCode string, upload *snakeUpload) (string, error) {
+	dir := filepath.Join(uploadPath, teamCode)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		slog.Error("upload: MkdirAll failed", "dir", dir, "team_code", teamCode, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Não foi possível criar diretório de uploads"}
+	}
+
+	// Extract into a temporary directory first so a failed upload never
+	// leaves a half written version behind.
+	tmpDir, err := os.MkdirTemp(dir, "project-*.tmp")
+	if err != nil {
+		slog.Error("upload: MkdirTemp failed", "dir", dir, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao preparar diretório temporário"}
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, entry := range upload.files {
+		if err := extractEntry(tmpDir, entry); err != nil {
+			slog.Error("upload: extract failed", "entry", entry.name, "team_code", teamCode, "err", err)
+			return "", archiveError(err)
+		}
+	}
+
+	version, err := nextProjectVersion(dir)
+	if err != nil {
+		slog.Error("upload: listing versions failed", "dir", dir, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao versionar projeto"}
+	}
+	versionDir := filepath.Join(dir, "v"+strconv.Itoa(version))
+	if err := os.Rename(tmpDir, versionDir); err != nil {
+		slog.Error("upload: rename failed", "tmp", tmpDir, "dst", versionDir, "err", err)
+		return "", &httpError{status: http.StatusInternalServerError, message: "Falha ao mover projeto para destino"}
+	}
+	pruneProjectVersions(dir, versionInUse)
+
+	if upload.entryPoint == "" {
+		return versionDir, nil
+	}
+	return filepath.Join(versionDir, filepath.FromSlash(upload.entryPoint)), nil
+}
+
+func archiveError(err error) error {
+	return &httpError{status: http.StatusBadRequest, message: "Arquivo compactado inválido: " + err.Error()}
+}
+
+func zipEntries(r io.ReaderAt, size int64) ([]archiveEntry, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []archiveEntry
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s: apenas arquivos regulares são permitidos", f.Name)
+		}
+		size := int64(f.UncompressedSize64)
+		if err := checkArchiveLimits(f.Name, len(entries)+1, size, &total); err != nil {
+			return nil, err
+		}
+		entries = append(entries, archiveEntry{name: f.Name, open: f.Open, size: size})
+	}
+	return entries, nil
+}
+
+// tarGzEntries reads the whole tarball since tar entries can only be read
+// in order. The upload size limit keeps this bounded.
+func tarGzEntries(r io.Reader) ([]archiveEntry, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var entries []archiveEntry
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("%s: apenas arquivos regulares são permitidos", hdr.Name)
+		}
+		if err := checkArchiveLimits(hdr.Name, len(entries)+1, hdr.Size, &total); err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxArchiveFileBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) != hdr.Size {
+			return nil, fmt.Errorf("%s: tamanho divergente", hdr.Name)
+		}
+		entries = append(entries, archiveEntry{
+			name: hdr.Name,
+			open: func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil },
+			size: hdr.Size,
+		})
+	}
+	return entries, nil
+}
+
+func checkArchiveLimits(name string, count int, size int64, total *int64) error {
+	if count > maxArchiveFiles {
+		return fmt.Errorf("mais de %d arquivos", maxArchiveFiles)
+	}
+	if size > maxArchiveFileBytes {
+		return fmt.Errorf("%s excede %d MB", name, maxArchiveFileBytes>>20)
+	}
+	*total += size
+	if *total > maxArchiveBytes {
+		return fmt.Errorf("projeto excede %d MB descompactado", maxArchiveBytes>>20)
+	}
+	if !safeArchivePath(name) {
+		return fmt.Errorf("%s: caminho inválido", name)
+	}
+	return nil
+}
+
+// safeArchivePath rejects absolute names, parent directory references and
+// Windows separators so that extraction cannot escape its directory.
+func safeArchivePath(name string) bool {
+	if name == "" || strings.Contains(name, `\`) || strings.HasPrefix(name, "/") {
+		return false
+	}
+	return filepath.IsLocal(filepath.FromSlash(name))
+}
+
+// commonRoot returns the single top-level directory shared by every name,
+// with its trailing slash, or "" if there is none.
+func commonRoot(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	first, _, ok := strings.Cut(path.Clean(names[0]), "/")
+	if !ok {
+		return ""
+	}
+	for _, name := range names[1:] {
+		dir, _, ok := strings.Cut(path.Clean(name), "/")
+		if !ok || dir != first {
+			return ""
+		}
+	}
+	return first + "/"
+}
+
+// projectEntryPoint detects the language of a project from the files at its
+// root and returns it with the entry point, which is empty for C projects.
+func projectEntryPoint(files []archiveEntry) (string, string, error) {
+	for _, ext := range []string{".py", ".js"} {
+		for _, f := range files {
+			if f.name == "snake"+ext {
+				return ext, f.name, nil
+			}
+		}
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.name, ".c") && !strings.Contains(f.name, "/") {
+			return ".c", "", nil
+		}
+	}
+	return "", "", errors.New("envie snake.py, snake.js ou arquivos .c na raiz do projeto")
+}
+
+func extractEntry(dstDir string, entry archiveEntry) error {
+	dst := filepath.Join(dstDir, filepath.FromSlash(entry.name))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	src, err := entry.open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	// Never trust the declared size, zip headers can lie.
+	n, err := io.Copy(out, io.LimitReader(src, entry.size+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n != entry.size {
+		return fmt.Errorf("%s: tamanho divergente", entry.name)
+	}
+	return nil
+}
+
+// projectVersions returns the version numbers found in dir, in ascending order.
+func projectVersions(dir string) ([]int, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, e := range dirEntries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), "v") {
+			continue
+		}
+		if v, err := strconv.Atoi(e.Name()[1:]); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+func nextProjectVersion(dir string) (int, error) {
+	versions, err := projectVersions(dir)
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 1, nil
+	}
+	return versions[len(versions)-1] + 1, nil
+}
+
+// versionInUse reports whether a snake server or game uses the files at
+// path, see controllers.SnakeServerController.UsesPath.
+func versionInUse(path string) bool {
+	manager := controllers.GetServerManager()
+	return manager != nil && manager.UsesPath(path)
+}
+
+// pruneProjectVersions removes all but the newest keptProjectVersions. The
+// versions still in use are left to a later upload.
+func pruneProjectVersions(dir string, inUse func(path string) bool) {
+	versions, err := projectVersions(dir)
+	if err != nil || len(versions) <= keptProjectVersions {
+		return
+	}
+	for _, v := range versions[:len(versions)-keptProjectVersions] {
+		old := filepath.Join(dir, "v"+strconv.Itoa(v))
+		if inUse(old) {
+			slog.Info("upload: keeping old version in use", "path", old)
+			continue
+		}
+		if err := os.RemoveAll(old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("upload: failed to remove old version", "path", old, "err", err)
+		} else {
+			slog.Info("upload: removed old version", "path", old)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestSafeArchivePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"snake.py", true},
+		{"lib/util.py", true},
+		{"./snake.py", true},
+		{"lib/../snake.py", true},
+		{"", false},
+		{"../snake.py", false},
+		{"lib/../../snake.py", false},
+		{"/etc/passwd", false},
+		{`..\snake.py`, false},
+		{`C:\snake.py`, false},
+	}
+	for _, test := range tests {
+		if got := safeArchivePath(test.name); got != test.want {
+			t.Errorf("safeArchivePath(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCommonRoot(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"snake.py"}, ""},
+		{[]string{"project/snake.py", "project/lib/util.py"}, "project/"},
+		{[]string{"./project/snake.py", "project/util.py"}, "project/"},
+		{[]string{"project/snake.py", "other/util.py"}, ""},
+		{[]string{"project/snake.py", "util.py"}, ""},
+	}
+	for _, test := range tests {
+		if got := commonRoot(test.names); got != test.want {
+			t.Errorf("commonRoot(%q) = %q, want %q", test.names, got, test.want)
+		}
+	}
+}
+
+// archiveFile is an entry of a test archive: a regular file holding body,
+// or size zero bytes when set, unless it links to link.
+type archiveFile struct {
+	name     string
+	body     string
+	size     int
+	link     string
+	hardlink bool
+}
+
+func (f archiveFile) data() []byte {
+	if f.size > 0 {
+		return make([]byte, f.size)
+	}
+	return []byte(f.body)
+}
+
+func zipArchive(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		header := &zip.FileHeader{Name: f.name, Method: zip.Deflate}
+		header.SetMode(0o644)
+		data := f.data()
+		if f.link != "" {
+			// zip has no hard links, both are symbolic
+			header.SetMode(fs.ModeSymlink | 0o777)
+			data = []byte(f.link)
+		}
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, f := range files {
+		data := f.data()
+		header := &tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if f.link != "" {
+			header.Typeflag, header.Linkname, header.Size, data = tar.TypeSymlink, f.link, 0, nil
+			if f.hardlink {
+				header.Typeflag = tar.TypeLink
+			}
+		}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var archiveKinds = []struct {
+	ext   string
+	build func(*testing.T, []archiveFile) []byte
+}{
+	{".zip", zipArchive},
+	{".tar.gz", tarGzArchive},
+}
+
+// memoryFile is an uploaded file held in memory.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+// uploadArchive reads and saves an archive as the upload handler does, for
+// the team TEAM, and returns the snake path.
+func uploadArchive(ext string, data []byte) (string, error) {
+	header := &multipart.FileHeader{Filename: "project" + ext, Size: int64(len(data))}
+	upload, err := readSnakeArchive(ext, memoryFile{bytes.NewReader(data)}, header)
+	if err != nil {
+		return "", err
+	}
+	return saveSnakeArchive("TEAM", upload)
+}
+
+func TestSnakeArchives(t *testing.T) {
+	snake := archiveFile{name: "snake.py", body: "print('snake')"}
+	many := []archiveFile{snake}
+	for i := range maxArchiveFiles {
+		many = append(many, archiveFile{name: "lib/" + strconv.Itoa(i) + ".py"})
+	}
+	var tooBig []archiveFile
+	for i := range maxArchiveBytes/maxArchiveFileBytes + 1 {
+		tooBig = append(tooBig, archiveFile{name: "data/" + strconv.Itoa(i), size: maxArchiveFileBytes})
+	}
+
+	tests := []struct {
+		name  string
+		files []archiveFile
+		// want is the path of the snake, relative to the version directory,
+		// when the upload is accepted
+		want    string
+		tarOnly bool
+	}{
+		{name: "project", files: []archiveFile{snake, {name: "lib/util.py"}}, want: "snake.py"},
+		{name: "wrapped in a directory", files: []archiveFile{{name: "project/snake.py"}, {name: "project/lib/util.py"}}, want: "snake.py"},
+		{name: "C project", files: []archiveFile{{name: "main.c"}, {name: "lib/util.c"}}, want: "."},
+		{name: "C files out of the root", files: []archiveFile{{name: "src/main.c"}, {name: "README"}}},
+		{name: "largest file", files: []archiveFile{snake, {name: "data", size: maxArchiveFileBytes}}, want: "snake.py"},
+		{name: "parent directory", files: []archiveFile{snake, {name: "../evil.py"}}},
+		{name: "parent directory nested", files: []archiveFile{snake, {name: "lib/../../evil.py"}}},
+		{name: "absolute", files: []archiveFile{snake, {name: "/tmp/evil.py"}}},
+		{name: "windows separators", files: []archiveFile{snake, {name: `..\evil.py`}}},
+		{name: "symbolic link", files: []archiveFile{snake, {name: "passwd", link: "/etc/passwd"}}},
+		{name: "hard link", files: []archiveFile{snake, {name: "passwd", link: "/etc/passwd", hardlink: true}}, tarOnly: true},
+		{name: "duplicate", files: []archiveFile{snake, snake}},
+		{name: "duplicate once cleaned", files: []archiveFile{snake, {name: "./snake.py"}}},
+		{name: "file too big", files: []archiveFile{snake, {name: "data", size: maxArchiveFileBytes + 1}}},
+		{name: "project too big", files: append([]archiveFile{snake}, tooBig...)},
+		{name: "too many files", files: many},
+	}
+	for _, kind := range archiveKinds {
+		for _, test := range tests {
+			if test.tarOnly && kind.ext != ".tar.gz" {
+				continue
+			}
+			t.Run(kind.ext+"/"+test.name, func(t *testing.T) {
+				t.Chdir(t.TempDir())
+				got, err := uploadArchive(kind.ext, kind.build(t, test.files))
+				if test.want != "" {
+					if want := filepath.Join(uploadPath, "TEAM", "v1", test.want); err != nil || got != want {
+						t.Fatalf("got %q and error %v, want %q", got, err, want)
+					}
+					if _, err := os.Stat(got); err != nil {
+						t.Errorf("the snake was not extracted: %v", err)
+					}
+					return
+				}
+
+				var httpErr *httpError
+				if !errors.As(err, &httpErr) || httpErr.status != http.StatusBadRequest {
+					t.Fatalf("got %q and error %v, want the archive refused", got, err)
+				}
+				// nothing is left behind, in the team directory or out of it
+				entries, _ := os.ReadDir(filepath.Join(uploadPath, "TEAM"))
+				if len(entries) > 0 {
+					t.Errorf("the refused archive left %v", entries)
+				}
+				if _, err := os.Stat("evil.py"); err == nil {
+					t.Error("the refused archive escaped its directory")
+				}
+			})
+		}
+	}
+}
+
+func TestZipEntryLongerThanDeclared(t *testing.T) {
+	body := []byte("print('a longer snake than declared')")
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	// a stored entry whose header declares fewer bytes than it holds
+	raw, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "snake.py",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(body),
+		CompressedSize64:   uint64(len(body)),
+		UncompressedSize64: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw.Write(body)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(t.TempDir())
+	if got, err := uploadArchive(".zip", buf.Bytes()); err == nil {
+		t.Errorf("got %q, want the entry longer than declared refused", got)
+	}
+}
+
+func TestTarGzEntryShorterThanDeclared(t *testing.T) {
+	var tarball bytes.Buffer
+	w := tar.NewWriter(&tarball)
+	if err := w.WriteHeader(&tar.Header{Name: "snake.py", Mode: 0o644, Size: 1024, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("print('cut short')"))
+	// no Close, the entry is cut short
+	w.Flush()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(tarball.Bytes())
+	gz.Close()
+
+	t.Chdir(t.TempDir())
+	if got, err := uploadArchive(".tar.gz", buf.Bytes()); err == nil {
+		t.Errorf("got %q, want the entry shorter than declared refused", got)
+	}
+}
+
+func TestExtractEntryChecksSize(t *testing.T) {
+	dir := t.TempDir()
+	entry := func(name, body string, size int64) archiveEntry {
+		return archiveEntry{name: name, size: size, open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader([]byte(body))), nil
+		}}
+	}
+	if err := extractEntry(dir, entry("snake.py", "print()", 7)); err != nil {
+		t.Fatalf("extractEntry: %v", err)
+	}
+	if err := extractEntry(dir, entry("snake.py", "print()", 7)); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("got %v extracting an entry twice, want fs.ErrExist", err)
+	}
+	if err := extractEntry(dir, entry("long.py", "print('longer')", 7)); err == nil {
+		t.Error("an entry longer than declared was extracted")
+	}
+	if err := extractEntry(dir, entry("short.py", "print", 7)); err == nil {
+		t.Error("an entry shorter than declared was extracted")
+	}
+}
+
+func TestPruneProjectVersions(t *testing.T) {
+	dir := t.TempDir()
+	for v := 1; v <= 4; v++ {
+		if err := os.Mkdir(filepath.Join(dir, "v"+strconv.Itoa(v)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a game still plays the first version
+	inUse := filepath.Join(dir, "v1")
+	pruneProjectVersions(dir, func(path string) bool { return path == inUse })
+
+	versions, err := projectVersions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 3, 4}; !slices.Equal(versions, want) {
+		t.Errorf("got versions %v, want %v", versions, want)
+	}
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+)
+
+// Upload acceptance policy.
+const (
+	uploadsPerMinute = 5
+	teamQuotaBytes   = 20 << 20 // 20 MiB
+	maxLineLength    = 2000
+)
+
+// Actions of an import rule.
+const (
+	policyFlag  = "flag"
+	policyBlock = "block"
+)
+
+// uploadFinding is a problem found while screening an upload. Blocking
+// findings reject the upload; flagged ones are accepted and shown to admins.
+type uploadFinding struct {
+	File   string
+	Rule   string
+	Action string
+}
+
+func (f uploadFinding) String() string {
+	return f.File + ": " + f.Rule
+}
+
+type importRule struct {
+	module  string
+	pattern *regexp.Regexp
+	// match, when set, is used instead of pattern
+	match  func(data []byte) bool
+	action string
+}
+
+func (rule importRule) matches(data []byte) bool {
+	if rule.match != nil {
+		return rule.match(data)
+	}
+	return rule.pattern.Match(data)
+}
+
+func pyImport(module string, action string) importRule {
+	m := regexp.QuoteMeta(module)
+	return importRule{
+		module:  module,
+		pattern: regexp.MustCompile(`(?m)^\s*(?:from|import)\s+(?:[\w.]+\s*,\s*)*` + m + `\b|(?:__import__|import_module)\(\s*['"]` + m + `\b`),
+		action:  action,
+	}
+}
+
+// pyCall matches the use of functions of a Python module however they are
+// reached: module.f, from module import f or *, an alias of the module,
+// __import__ and getattr. funcs is a regular expression of their names, name
+// the one of the rule.
+func pyCall(name string, module string, funcs string, action string) importRule {
+	m := regexp.QuoteMeta(module)
+	direct := regexp.MustCompile(`\b` + m + `\s*\.\s*(?:` + funcs + `)\b` +
+		`|(?m)^\s*from\s+` + m + `\s+import\s*\(?[\w\s,\\]*?(?:\b(?:` + funcs + `)\b|\*)` +
+		`|(?:__import__|import_module)\(\s*['"]` + m + `['"]\s*\)\s*\.\s*(?:` + funcs + `)\b` +
+		`|\bgetattr\(\s*[\w.]+\s*,\s*['"](?:` + funcs + `)['"]`)
+	alias := regexp.MustCompile(`\bimport\s+(?:[\w.]+(?:\s+as\s+\w+)?\s*,\s*)*` + m + `\s+as\s+(\w+)`)
+	return importRule{
+		module: name,
+		match: func(data []byte) bool {
+			if direct.Match(data) {
+				return true
+			}
+			for _, found := range alias.FindAllSubmatch(data, -1) {
+				call := regexp.MustCompile(`\b` + regexp.QuoteMeta(string(found[1])) + `\s*\.\s*(?:` + funcs + `)\b`)
+				if call.Match(data) {
+					return true
+				}
+			}
+			return false
+		},
+		action: action,
+	}
+}
+
+func jsRequire(module string, action string) importRule {
+	m := regexp.QuoteMeta(module)
+	return importRule{
+		module:  module,
+		pattern: regexp.MustCompile(`(?:require\(\s*|import\(\s*|from\s+|import\s+)['"](?:node:)?` + m + `['"]`),
+		action:  action,
+	}
+}
+
+var cRules = []importRule{
+	{module: "sys/socket.h", pattern: regexp.MustCompile(`#\s*include\s*[<"](?:sys/socket|netinet/\w+|arpa/inet)\.h[>"]`), action: policyBlock},
+	{module: "system/popen/exec", pattern: regexp.MustCompile(`\b(?:system|popen|fork|vfork|execl|execlp|execle|execv|execvp|execve)\s*\(`), action: policyBlock},
+}
+
+// osProcessFuncs are the functions of the Python os module that start
+// processes.
+const osProcessFuncs = `system|popen|fork\w*|exec\w*|spawn\w*|posix_spawn\w*`
+
+// importPolicies lists, per source file extension, the modules a snake may
+// not use. The screen is advisory: it catches the usual ways of importing
+// them, not code built to hide it (eval, names put together at run time),
+// and does not replace the resource limits of the snake processes.
+var importPolicies = map[string][]importRule{
+	".py": {
+		pyImport("subprocess", policyBlock),
+		pyImport("socket", policyBlock),
+		pyImport("ctypes", policyBlock),
+		pyImport("pty", policyBlock),
+		pyImport("posix", policyBlock),
+		pyCall("os.system", "os", osProcessFuncs, policyBlock),
+		pyImport("multiprocessing", policyFlag),
+		pyImport("requests", policyFlag),
+		pyImport("urllib", policyFlag),
+	},
+	".js": {
+		jsRequire("child_process", policyBlock),
+		jsRequire("net", policyBlock),
+		jsRequire("dgram", policyBlock),
+		jsRequire("worker_threads", policyFlag),
+		jsRequire("http", policyFlag),
+		jsRequire("https", policyFlag),
+	},
+	".c": cRules,
+	".h": cRules,
+}
+
+// encodedBlob matches long base64 or escaped hex runs, typical of payloads
+// hidden from review.
+var encodedBlob = regexp.MustCompile(`[A-Za-z0-9+/=]{512,}|(?:\\x[0-9a-fA-F]{2}){64,}`)
+
+// acceptSnakeUpload runs an upload through the acceptance pipeline: rate
+// limit, parsing, disk quota, content screening, storage and installation.
+// Every attempt is recorded in the upload audit log. It returns the flagged
+// findings of an accepted upload.
+func acceptSnakeUpload(w http.ResponseWriter, r *http.Request, team *database.Team, teamCode string) (*database.Snake, []uploadFinding, error) {
+	record := database.CreateUploadParams{
+		TeamID:     team.ID,
+		Size:       max(r.ContentLength, 0),
+		Status:     controllers.UploadRejected,
+		RemoteAddr: sql.NullString{String: r.RemoteAddr, Valid: r.RemoteAddr != ""},
+	}
+
+	snake, findings, err := runUploadPipeline(w, r, team, teamCode, &record)
+
+	var notes []string
+	for _, f := range findings {
+		notes = append(notes, f.Action+" "+f.String())
+	}
+	if err != nil {
+		notes = append(notes, err.Error())
+	} else {
+		record.Status = controllers.UploadAccepted
+		if len(findings) > 0 {
+			record.Status = controllers.UploadFlagged
+		}
+		record.SnakeID = sql.NullInt64{Int64: snake.ID, Valid: true}
+	}
+	record.Findings = sql.NullString{String: strings.Join(notes, "\n"), Valid: len(notes) > 0}
+
+	uploads := controllers.NewUploadController(database.DB)
+	if _, recErr := uploads.RecordUpload(context.WithoutCancel(r.Context()), record); recErr != nil {
+		slog.ErrorContext(r.Context(), "upload: failed to record upload", "team_id", team.ID, "err", recErr)
+	}
+
+	var target string
+	if record.SnakeID.Valid {
+		target = "snake:" + strconv.FormatInt(record.SnakeID.Int64, 10)
+	}
+	recordAudit(r, team, controllers.AuditUpload, target, map[string]any{
+		"filename": record.Filename,
+		"size":     record.Size,
+		"status":   record.Status,
+		"findings": len(notes),
+	})
+
+	return snake, findings, err
+}
+
+func runUploadPipeline(w http.ResponseWriter, r *http.Request, team *database.Team, teamCode string, record *database.CreateUploadParams) (*database.Snake, []uploadFinding, error) {
+	if !uploadLimiter.Allow(team.ID, time.Now()) {
+		return nil, nil, &httpError{status: http.StatusTooManyRequests, message: fmt.Sprintf("Limite de %d envios por minuto atingido. Aguarde um pouco.", uploadsPerMinute)}
+	}
+
+	file, upload, err := parseSnakeUpload(w, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	record.Filename = upload.header.Filename
+	record.Size = upload.size
+	record.Lang = sql.NullString{String: upload.lang, Valid: true}
+
+	dir := filepath.Join(uploadPath, teamCode)
+	if usage := projectedUsage(dir, upload); usage > teamQuotaBytes {
+		return nil, nil, &httpError{
+			status:  http.StatusRequestEntityTooLarge,
+			message: fmt.Sprintf("Cota de disco do time excedida (%.1f de %d MB)", float64(usage)/(1<<20), teamQuotaBytes>>20),
+		}
+	}
+
+	findings, err := screenUpload(upload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var blocked, flagged []uploadFinding
+	for _, f := range findings {
+		if f.Action == policyBlock {
+			blocked = append(blocked, f)
+		} else {
+			flagged = append(flagged, f)
+		}
+	}
+	if len(blocked) > 0 {
+		reasons := make([]string, len(blocked))
+		for i, f := range blocked {
+			reasons[i] = f.String()
+		}
+		slog.WarnContext(r.Context(), "upload: blocked", "team_id", team.ID, "findings", reasons)
+		return nil, findings, &httpError{status: http.StatusBadRequest, message: "Upload rejeitado: " + strings.Join(reasons, "; ")}
+	}
+
+	dstPath, err := storeSnakeUpload(teamCode, upload)
+	if err != nil {
+		return nil, flagged, err
+	}
+
+	snake, err := installTeamSnake(r.Context(), team, dstPath, upload.lang)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "upload: failed to install snake", "team_code", teamCode, "err", err)
+		return nil, flagged, err
+	}
+
+	return snake, flagged, nil
+}
+
+// screenUpload looks for binary or obfuscated content and disallowed
+// imports in every file of an upload.
+func screenUpload(upload *snakeUpload) ([]uploadFinding, error) {
+	var findings []uploadFinding
+	for _, f := range upload.files {
+		src, err := f.open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(src, f.size))
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, screenFile(f.name, data)...)
+	}
+	return findings, nil
+}
+
+func screenFile(name string, data []byte) []uploadFinding {
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return []uploadFinding{{File: name, Rule: "conteúdo binário não é permitido", Action: policyBlock}}
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if ext != ".py" && ext != ".js" && ext != ".c" && ext != ".h" {
+		return nil
+	}
+
+	var findings []uploadFinding
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > maxLineLength {
+			findings = append(findings, uploadFinding{
+				File:   name,
+				Rule:   "linha " + strconv.Itoa(i+1) + " com mais de " + strconv.Itoa(maxLineLength) + " caracteres (código ofuscado ou minificado)",
+				Action: policyBlock,
+			})
+			break
+		}
+	}
+	if encodedBlob.Match(data) {
+		findings = append(findings, uploadFinding{File: name, Rule: "bloco codificado longo (código ofuscado)", Action: policyBlock})
+	}
+
+	for _, rule := range importPolicies[ext] {
+		if !rule.matches(data) {
+			continue
+		}
+		verb := "uso de " + rule.module + " não é permitido"
+		if rule.action == policyFlag {
+			verb = "uso de " + rule.module + " sinalizado para revisão"
+		}
+		findings = append(findings, uploadFinding{File: name, Rule: verb, Action: rule.action})
+	}
+	return findings
+}
+
+// projectedUsage estimates the disk used by the team once the upload is
+// stored and the versions it replaces are removed.
+func projectedUsage(dir string, upload *snakeUpload) int64 {
+	usage := dirSize(dir) + upload.size
+	if upload.archive {
+		versions, err := projectVersions(dir)
+		if err == nil && len(versions) >= keptProjectVersions {
+			for _, v := range versions[:len(versions)-keptProjectVersions+1] {
+				usage -= dirSize(filepath.Join(dir, "v"+strconv.Itoa(v)))
+			}
+		}
+	} else {
+		prev, _ := filepath.Glob(filepath.Join(dir, "snake_prev.*"))
+		for _, p := range prev {
+			usage -= dirSize(p)
+		}
+	}
+	return usage
+}
+
+// dirSize returns the total size of the regular files under root.
+func dirSize(root string) int64 {
+	var size int64
+	filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// rateLimiter allows at most limit events per key within a sliding window.
+type rateLimiter struct {
+	lock   sync.Mutex
+	limit  int
+	window time.Duration
+	events map[int64][]time.Time
+}
+
+var uploadLimiter = newRateLimiter(uploadsPerMinute, time.Minute)
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, events: make(map[int64][]time.Time)}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+func (l *rateLimiter) Allow(key int64, now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	recent := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.events[key] = recent
+		return false
+	}
+	l.events[key] = append(recent, now)
+	return true
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScreenFile(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		// want is the action of each finding, in order
+		want []string
+	}{
+		{"snake.py", "import json\nimport random\nprint(json.dumps({}))\n", nil},
+		{"snake.py", "import os\nos.path.join('a', 'b')\n", nil},
+		{"snake.py", "import subprocess\n", []string{policyBlock}},
+		{"snake.py", "import json, socket\n", []string{policyBlock}},
+		{"snake.py", "from ctypes import CDLL\n", []string{policyBlock}},
+		{"snake.py", "m = __import__('subprocess')\n", []string{policyBlock}},
+		{"snake.py", "import os\nos.system('ls')\n", []string{policyBlock}},
+		{"snake.py", "from os import system\nsystem('ls')\n", []string{policyBlock}},
+		{"snake.py", "from os import path, popen as run\n", []string{policyBlock}},
+		{"snake.py", "from os import (\n    path,\n    execv,\n)\n", []string{policyBlock}},
+		{"snake.py", "from os import *\n", []string{policyBlock}},
+		{"snake.py", "import os as o\no.fork()\n", []string{policyBlock}},
+		{"snake.py", "import json, os as o\nrun = o.spawnv\n", []string{policyBlock}},
+		{"snake.py", "import os as o\no.getcwd()\n", nil},
+		{"snake.py", "__import__('os').system('ls')\n", []string{policyBlock}},
+		{"snake.py", "import os\ngetattr(os, 'system')('ls')\n", []string{policyBlock}},
+		{"snake.py", "import posix\n", []string{policyBlock}},
+		{"snake.py", "import requests\n", []string{policyFlag}},
+		{"snake.js", "const cp = require('child_process')\n", []string{policyBlock}},
+		{"snake.js", "import net from \"node:net\"\n", []string{policyBlock}},
+		{"snake.js", "const http = require('http')\n", []string{policyFlag}},
+		{"snake.js", "const fs = require('fs')\n", nil},
+		{"main.c", "#include <sys/socket.h>\n", []string{policyBlock}},
+		{"main.c", "int main() { system(\"ls\"); }\n", []string{policyBlock}},
+		{"util.h", "#include <stdio.h>\n", nil},
+		{"snake.py", "x = 1\n" + strings.Repeat("y = 1; ", maxLineLength/7+1) + "\n", []string{policyBlock}},
+		{"snake.py", "blob = '" + strings.Repeat("QUJD", 200) + "'\n", []string{policyBlock}},
+		{"snake.py", "print('\x00')\n", []string{policyBlock}},
+		{"README.md", "import subprocess\n", nil},
+	}
+	for _, test := range tests {
+		findings := screenFile(test.name, []byte(test.code))
+		var got []string
+		for _, f := range findings {
+			got = append(got, f.Action)
+		}
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("screenFile(%q, %q) found %v, want %v", test.name, test.code, findings, test.want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2, time.Minute)
+	start := time.Now()
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	steps := []struct {
+		key  int64
+		at   time.Time
+		want bool
+	}{
+		{1, at(0), true},
+		{1, at(10 * time.Second), true},
+		{1, at(20 * time.Second), false},
+		// another team has its own window
+		{2, at(20 * time.Second), true},
+		// the first upload left the window, the refused one never counted
+		{1, at(time.Minute), true},
+		{1, at(time.Minute + time.Second), false},
+		{1, at(time.Minute + 10*time.Second), true},
+	}
+	for i, step := range steps {
+		if got := limiter.Allow(step.key, step.at); got != step.want {
+			t.Errorf("step %d: Allow(%d) = %v, want %v", i, step.key, got, step.want)
+		}
+	}
+}
+
+func TestProjectedUsage(t *testing.T) {
+	write := func(path string, size int) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	if got := projectedUsage(filepath.Join(dir, "none"), &snakeUpload{size: 100}); got != 100 {
+		t.Errorf("first upload: got %d bytes, want the 100 of the upload", got)
+	}
+
+	// a single file replaces the previous one, the current one is kept
+	write(filepath.Join(dir, "snake.py"), 1000)
+	write(filepath.Join(dir, "snake_prev.py"), 400)
+	if got := projectedUsage(dir, &snakeUpload{size: 100}); got != 1100 {
+		t.Errorf("file upload: got %d bytes, want 1100", got)
+	}
+
+	// a project replaces the oldest of the versions kept
+	write(filepath.Join(dir, "v1", "snake.py"), 2000)
+	write(filepath.Join(dir, "v2", "snake.py"), 3000)
+	write(filepath.Join(dir, "v2", "lib", "util.py"), 500)
+	if got := projectedUsage(dir, &snakeUpload{size: 100, archive: true}); got != 1000+400+3500+100 {
+		t.Errorf("project upload: got %d bytes, want %d", got, 1000+400+3500+100)
+	}
+}
@@ -0,0 +1,80 @@
+// Package logging sets up the structured logger shared by the web server, the
+// game engine and the command line tools.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Output formats of Setup.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// minLevel is the least level of the logger made by Setup.
+var minLevel = new(slog.LevelVar)
+
+// Setup makes slog.Default write the records of at least the given level, one
+// of debug, info (the default), warn or error, to w as text or JSON. The
+// standard log package writes through it too, at Info.
+func Setup(w io.Writer, level string, format string) error {
+	l := slog.LevelInfo
+	if err := l.UnmarshalText([]byte(level)); level != "" && err != nil {
+		return fmt.Errorf("unknown log level %q, valid levels are debug, info, warn and error", level)
+	}
+
+	minLevel.Set(l)
+	opts := &slog.HandlerOptions{Level: minLevel}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q, valid formats are %q and %q", format, FormatText, FormatJSON)
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// SetLevel changes the least level of the logger made by Setup.
+func SetLevel(l slog.Level) {
+	minLevel.Set(l)
+}
+
+type attrsKey struct{}
+
+// With returns a copy of ctx whose log records also carry the given
+// attributes, as key and value pairs like slog.Logger.With takes. They show
+// up in the records logged with the *Context functions of slog.
+func With(ctx context.Context, args ...any) context.Context {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	attrs = append(attrs[:len(attrs):len(attrs)], slog.Group("", args...).Value.Group()...)
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// contextHandler adds the attributes stored by With to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
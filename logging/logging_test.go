@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestWithAddsAttrs(t *testing.T) {
+	var out bytes.Buffer
+	if err := Setup(&out, "warn", FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	defer slog.SetDefault(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+
+	ctx := With(context.Background(), "request_id", "r1")
+	gameCtx := With(ctx, "game_id", "g1")
+	slog.InfoContext(gameCtx, "dropped below the level")
+	slog.WarnContext(gameCtx, "slow move", "snake_id", "s1")
+	slog.WarnContext(ctx, "request only")
+
+	dec := json.NewDecoder(&out)
+	var first, second map[string]any
+	if err := dec.Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first["msg"] != "slow move" || first["request_id"] != "r1" || first["game_id"] != "g1" || first["snake_id"] != "s1" {
+		t.Errorf("first record is %v", first)
+	}
+	if second["msg"] != "request only" || second["game_id"] != nil {
+		t.Errorf("second record is %v", second)
+	}
+	if dec.More() {
+		t.Error("the Info record was logged at level warn")
+	}
+}
+
+func TestSetupRejectsUnknownNames(t *testing.T) {
+	if err := Setup(&bytes.Buffer{}, "loud", FormatText); err == nil {
+		t.Error(`Setup accepted level "loud"`)
+	}
+	if err := Setup(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error(`Setup accepted format "xml"`)
+	}
+}
@@ -0,0 +1,247 @@
+// Package metrics keeps the counters, gauges and histograms of the server and
+// writes them in the Prometheus text exposition format. It has no
+// dependencies, so any Prometheus server or plain curl can scrape it.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the output of WriteText.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is a family of series sharing a name.
+type metric interface {
+	write(w io.Writer)
+}
+
+var registry struct {
+	lock    sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+func register(name string, m metric) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	if registry.names == nil {
+		registry.names = map[string]bool{}
+	}
+	if registry.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	registry.names[name] = true
+	registry.metrics = append(registry.metrics, m)
+}
+
+// WriteText writes every metric, in the order they were created.
+func WriteText(w io.Writer) error {
+	registry.lock.Lock()
+	metrics := slices.Clone(registry.metrics)
+	registry.lock.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// family holds what the metric types have in common: the name, the help text
+// and the label names. Series are keyed by their joined label values.
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	lock   sync.Mutex
+}
+
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (f *family) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escape(f.help, false), f.name, f.kind)
+}
+
+// labelPairs formats the labels of the series with the given key, followed by
+// the extra name and value pairs.
+func (f *family) labelPairs(key string, extra ...string) string {
+	var pairs []string
+	if len(f.labels) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			pairs = append(pairs, f.labels[i]+`="`+escape(value, true)+`"`)
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escape(extra[i+1], true)+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escape(s string, quoted bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quoted {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Counter is a value that only goes up, like the number of finished games.
+type Counter struct {
+	family
+	values map[string]float64
+}
+
+// NewCounter creates and registers a counter with the given label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{family: family{name: name, help: help, kind: "counter", labels: labels}, values: map[string]float64{}}
+	if len(labels) == 0 {
+		c.values[""] = 0
+	}
+	register(name, c)
+	return c
+}
+
+// Inc adds one to the series with the given label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the series with the given label
+// values.
+func (c *Counter) Add(v float64, values ...string) {
+	key := c.key(values)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.values[key] += v
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.header(w)
+	for _, key := range slices.Sorted(maps.Keys(c.values)) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(key), formatFloat(c.values[key]))
+	}
+}
+
+// Gauge is a value that goes up and down, like the number of running games.
+type Gauge struct {
+	family
+	values map[string]float64
+}
+
+// NewGauge creates and registers a gauge with the given label names.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{family: family{name: name, help: help, kind: "gauge", labels: labels}, values: map[string]float64{}}
+	if len(labels) == 0 {
+		g.values[""] = 0
+	}
+	register(name, g)
+	return g
+}
+
+// Set sets the series with the given label values to v.
+func (g *Gauge) Set(v float64, values ...string) {
+	key := g.key(values)
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.values[key] = v
+}
+
+// Reset drops every labelled series, so that series which are gone are not
+// reported anymore.
+func (g *Gauge) Reset() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if len(g.labels) > 0 {
+		clear(g.values)
+	}
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.header(w)
+	for _, key := range slices.Sorted(maps.Keys(g.values)) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, g.labelPairs(key), formatFloat(g.values[key]))
+	}
+}
+
+// Histogram counts observations, like request durations, in buckets.
+type Histogram struct {
+	family
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates and registers a histogram with the given upper bucket
+// bounds, in increasing order, and label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{family: family{name: name, help: help, kind: "histogram", labels: labels}, buckets: buckets, series: map[string]*histogramSeries{}}
+	if len(labels) == 0 {
+		h.series[""] = &histogramSeries{counts: make([]uint64, len(buckets))}
+	}
+	register(name, h)
+	return h
+}
+
+// Observe counts v in the series with the given label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	key := h.key(values)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.header(w)
+	for _, key := range slices.Sorted(maps.Keys(h.series)) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(key, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(key), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(key), s.count)
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	games := NewCounter("test_games_total", "Games played.", "outcome")
+	games.Inc("win")
+	games.Inc("win")
+	games.Inc(`a "draw"`)
+	viewers := NewGauge("test_viewers", "Connected viewers.")
+	viewers.Set(3)
+	latency := NewHistogram("test_latency_seconds", "Latency.", []float64{0.1, 0.5})
+	latency.Observe(0.05)
+	latency.Observe(0.1)
+	latency.Observe(2)
+
+	var out strings.Builder
+	if err := WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_games_total Games played.
+# TYPE test_games_total counter
+test_games_total{outcome="a \"draw\""} 1
+test_games_total{outcome="win"} 2
+# HELP test_viewers Connected viewers.
+# TYPE test_viewers gauge
+test_viewers 3
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{le="0.1"} 2
+test_latency_seconds_bucket{le="0.5"} 2
+test_latency_seconds_bucket{le="+Inf"} 3
+test_latency_seconds_sum 2.15
+test_latency_seconds_count 3
+`
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("WriteText wrote\n%s\nwant it to contain\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type AuditEvent struct {
+	ID         int64
+	Actor      string
+	Action     string
+	Target     string
+	RemoteAddr string
+	Payload    string
+	CreatedAt  time.Time
+}
+
+// AuditFilter is the state of the audit page filter form. Dates are
+// formatted as 2006-01-02.
+type AuditFilter struct {
+	Action string
+	TeamID int64
+	Since  string
+	Until  string
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type BatchJob struct {
+	ID          int64
+	Snakes      []string
+	Games       int
+	Parallelism int
+	Seed        int64
+	Finished    int
+	Running     bool
+	StartedAt   time.Time
+	Duration    time.Duration
+	Error       string
+
+	// Set once the job is over.
+	Draws        int
+	Errors       int
+	AverageTurns float64
+	SnakeStats   []BatchSnakeStats
+}
+
+type BatchSnakeStats struct {
+	Name          string
+	Wins          int
+	WinRate       float64
+	WinRateLow    float64
+	WinRateHigh   float64
+	AverageLength float64
+	DeathCauses   string
+	LatencyP50    time.Duration
+	LatencyP90    time.Duration
+	LatencyP99    time.Duration
+	Timeouts      int
+}
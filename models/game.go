@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RunningGame is a game being played, as listed on the live and admin games
+// pages.
+type RunningGame struct {
+	ID        string
+	Snakes    []string
+	StartedAt time.Time
+	Turn      int
+	Alive     int
+	Viewers   int
+	Paused    bool
+	// Starting is set while the snakes have not answered /start yet.
+	Starting bool
+}
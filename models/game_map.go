@@ -0,0 +1,12 @@
+package models
+
+// GameMap is a map games can be played on, as listed on the admin pages.
+type GameMap struct {
+	ID          string
+	Name        string
+	Author      string
+	Description string
+	Players     string
+	Sizes       string
+	Custom      bool
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// MoveStats sums up the /move requests of a snake version, as shown on the
+// dashboard and the admin page.
+type MoveStats struct {
+	SnakeID      int64
+	Games        int
+	Moves        int
+	Timeouts     int
+	StatusErrors int
+	DecodeErrors int
+	P50          time.Duration
+	P95          time.Duration
+	P99          time.Duration
+	// Budget is the /move timeout the latencies compare to.
+	Budget time.Duration
+}
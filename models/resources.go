@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ResourceUsage is the recent resource usage of the processes of a snake, as
+// shown on the admin page.
+type ResourceUsage struct {
+	// Samples are the last samples, oldest first.
+	Samples  []ResourceSample
+	Warnings int
+	Kills    int
+	// LastEvent tells the last time a process went over its limits, empty
+	// if none did.
+	LastEvent   string
+	LastEventAt time.Time
+}
+
+type ResourceSample struct {
+	At         time.Time
+	CPUPercent float64
+	RSSMB      float64
+	Threads    int
+	OpenFiles  int
+}
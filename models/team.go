@@ -15,6 +15,10 @@ type Snake struct {
 	Lang      string
 	UpdatedAt time.Time
 	Status    string
+	// MoveStats is nil until the snake plays a recorded game.
+	MoveStats *MoveStats
+	// Resources is nil until a process of the snake is sampled.
+	Resources *ResourceUsage
 }
 
 type Code struct {
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type ApiToken struct {
+	ID         int64
+	Name       string
+	Prefix     string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	Revoked    bool
+}
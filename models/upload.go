@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Upload struct {
+	ID         int64
+	TeamName   string
+	Filename   string
+	Size       int64
+	Lang       string
+	Status     string
+	Findings   []string
+	RemoteAddr string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,205 @@
+// Package server runs the localsnake web application.
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/secomp2025/localsnake/controllers"
+	"github.com/secomp2025/localsnake/database"
+	"github.com/secomp2025/localsnake/game"
+	"github.com/secomp2025/localsnake/handlers"
+	"github.com/secomp2025/localsnake/static"
+)
+
+const (
+	shutdownPeriod      = 15 * time.Second
+	shutdownHardPeriod  = 3 * time.Second
+	readinessDrainDelay = 5 * time.Second
+)
+
+// Config holds the settings of the web server.
+type Config struct {
+	Addr   string
+	DBPath string
+	// DevMode serves ./static from disk and skips the readiness drain on
+	// shutdown.
+	DevMode bool
+	// MaxGames is how many games may run at the same time, zero keeps
+	// controllers.DefaultMaxRunningGames.
+	MaxGames int
+	// Timeouts configures how games treat snakes that answer /move late.
+	Timeouts game.TimeoutOptions
+	// MapsDir holds the custom maps, uploaded maps are stored there too.
+	// Empty keeps controllers.DefaultMapsDir.
+	MapsDir string
+	// SnakeRuntime is how the uploaded snakes are played, empty keeps
+	// controllers.RuntimeHTTP.
+	SnakeRuntime controllers.SnakeRuntime
+	// ResourceLimits are the limits of each snake process, nil keeps
+	// controllers.DefaultResourceLimits.
+	ResourceLimits *controllers.ResourceLimits
+}
+
+// Run serves the web application until ctx is cancelled, then shuts down
+// gracefully.
+func Run(ctx context.Context, cfg Config) error {
+	var isShuttingDown atomic.Bool
+
+	// Initialize database connection
+	initCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := database.Init(initCtx, cfg.DBPath); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if cfg.MaxGames > 0 {
+		controllers.SetMaxRunningGames(cfg.MaxGames)
+	}
+	controllers.SetTimeoutOptions(cfg.Timeouts)
+
+	mapsDir := cfg.MapsDir
+	if mapsDir == "" {
+		mapsDir = controllers.DefaultMapsDir
+	}
+	customMaps, err := controllers.LoadMaps(mapsDir)
+	if err != nil {
+		slog.Warn("Some custom maps were not loaded", "dir", mapsDir, "err", err)
+	}
+	slog.Info("Loaded custom maps", "dir", mapsDir, "maps", len(customMaps))
+
+	mux := http.NewServeMux()
+
+	var staticFS fs.FS
+	if cfg.DevMode {
+		staticFS = os.DirFS("static")
+		mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
+	} else {
+		staticFS = static.FS
+		mux.Handle(
+			"/static/",
+			http.StripPrefix("/static/",
+				http.FileServer(http.FS(staticFS))),
+		)
+	}
+
+	var destroyOnce sync.Once
+	if cfg.ResourceLimits != nil {
+		controllers.SetResourceLimits(*cfg.ResourceLimits)
+	}
+	runtime := cfg.SnakeRuntime
+	if runtime == "" {
+		runtime = controllers.RuntimeHTTP
+	}
+	controllers.InitSnakeServerManager(staticFS, runtime)
+	defer destroyOnce.Do(controllers.DestroySnakeServerManager)
+
+	// Routes
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if isShuttingDown.Load() {
+			http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintln(w, "Ok")
+	})
+
+	mux.HandleFunc("/metrics", handlers.MetricsHandler)
+
+	mux.HandleFunc("/", handlers.HomePage)
+	mux.HandleFunc("/login", handlers.LoginHandler)
+	mux.HandleFunc("/register", handlers.Register)
+	mux.HandleFunc("/upload-snake", handlers.UploadSnake)
+	mux.HandleFunc("/tokens", handlers.TokensHandler)
+	mux.HandleFunc("/tokens/revoke", handlers.RevokeTokenHandler)
+	mux.HandleFunc("/move-stats", handlers.MoveStatsHandler)
+	mux.HandleFunc("/logout", handlers.Logout)
+	mux.HandleFunc("/status", handlers.StatusHandler)
+
+	mux.HandleFunc("/create-game", handlers.CreateTeamGameHandler)
+	// handle /game/<game_id>
+	mux.HandleFunc("/game/", handlers.GameHandler)
+	mux.HandleFunc("/live", handlers.LiveHandler)
+	mux.HandleFunc("GET /live/{id}", handlers.LiveWatchHandler)
+
+	mux.HandleFunc("/login-adm", handlers.PostLoginAdm)
+	mux.HandleFunc("/adm", handlers.AdminHandler)
+	mux.HandleFunc("/adm/uploads", handlers.AdminUploadsHandler)
+	mux.HandleFunc("/adm/audit", handlers.AdminAuditHandler)
+	mux.HandleFunc("/adm/audit/export", handlers.AdminAuditExportHandler)
+	mux.HandleFunc("/adm/games", handlers.AdminGamesHandler)
+	mux.HandleFunc("/adm/games/action", handlers.AdminGameActionHandler)
+	mux.HandleFunc("/adm/batch", handlers.AdminBatchHandler)
+	mux.HandleFunc("/adm/batch/cancel", handlers.AdminBatchCancelHandler)
+	mux.HandleFunc("/adm/maps", handlers.AdminMapsHandler)
+
+	mux.HandleFunc("/battle", handlers.HandleBattle)
+	mux.HandleFunc("/rerun", handlers.RerunHandler)
+
+	handlers.RegisterAPIRoutes(mux)
+
+	ongoingCtx, stopOngoingGracefully := context.WithCancel(context.Background())
+	defer stopOngoingGracefully()
+	server := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: withRequestID(mux),
+		BaseContext: func(_ net.Listener) context.Context {
+			return ongoingCtx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		return err
+	}
+
+	isShuttingDown.Store(true)
+	slog.Info("Shutting down")
+
+	// games need their snakes, let them end before stopping the snake servers
+	gamesPeriod := shutdownPeriod
+	if cfg.DevMode {
+		gamesPeriod = 0
+	}
+	gamesCtx, cancelGames := context.WithTimeout(context.Background(), gamesPeriod)
+	slog.Info("Waiting for running games to finish")
+	controllers.ShutdownGames(gamesCtx)
+	cancelGames()
+
+	destroyOnce.Do(controllers.DestroySnakeServerManager)
+
+	if !cfg.DevMode {
+		time.Sleep(readinessDrainDelay)
+		slog.Info("Waiting for ongoing requests to finish")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownPeriod)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		stopOngoingGracefully()
+		if err != nil {
+			slog.Warn("Failed to wait for ongoing requests to finish", "err", err)
+			time.Sleep(shutdownHardPeriod)
+		}
+	}
+	slog.Info("Server shut down")
+	return nil
+}
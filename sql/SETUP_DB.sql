@@ -27,4 +27,92 @@ CREATE TABLE IF NOT EXISTS snakes (
     team_id INTEGER NOT NULL,
     FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE
 );
+--
+--
+CREATE TABLE IF NOT EXISTS games (
+    id TEXT PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT 'running',
+    seed INTEGER NOT NULL,
+    turns INTEGER NOT NULL DEFAULT 0,
+    winner TEXT,
+    is_draw BOOLEAN DEFAULT FALSE,
+    replay_path TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    finished_at DATETIME,
+    abort_reason TEXT,
+    timeout_policy TEXT,
+    map TEXT,
+    fog TEXT,
+    vision_radius INTEGER,
+    vision_overlay BOOLEAN,
+    squad_rules TEXT,
+    winner_squad TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_games_created_at ON games(created_at);
+--
+--
+CREATE TABLE IF NOT EXISTS game_snakes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_id TEXT NOT NULL,
+    snake_id INTEGER,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    is_winner BOOLEAN DEFAULT FALSE,
+    timeouts INTEGER NOT NULL DEFAULT 0,
+    moves INTEGER NOT NULL DEFAULT 0,
+    status_errors INTEGER NOT NULL DEFAULT 0,
+    decode_errors INTEGER NOT NULL DEFAULT 0,
+    latency_histogram TEXT,
+    squad TEXT,
+    FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (snake_id) REFERENCES snakes(id) ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_game_snakes_game_id ON game_snakes(game_id);
+--
+--
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_api_tokens_team_id ON api_tokens(team_id);
+--
+--
+CREATE TABLE IF NOT EXISTS uploads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER NOT NULL,
+    snake_id INTEGER,
+    filename TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    lang TEXT,
+    status TEXT NOT NULL,
+    findings TEXT,
+    remote_addr TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (snake_id) REFERENCES snakes(id) ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_uploads_created_at ON uploads(created_at);
+--
+--
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INTEGER,
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    target TEXT,
+    remote_addr TEXT,
+    payload TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action);
 COMMIT;
\ No newline at end of file
@@ -49,6 +49,10 @@ ORDER BY created_at ASC;
 INSERT INTO teams (name, code_id)
 VALUES (?, ?)
 RETURNING *;
+-- name: CreateAdminTeam :one
+INSERT INTO teams (name, code_id, is_admin)
+VALUES (?, ?, TRUE)
+RETURNING *;
 -- name: UpdateTeam :exec
 UPDATE teams
 SET name = ?,
@@ -86,4 +90,176 @@ WHERE id = ?;
 SELECT *
 FROM snakes
 WHERE team_id = ?
-ORDER BY updated_at ASC;
\ No newline at end of file
+ORDER BY updated_at ASC;
+-------- GAME --------
+-- name: GetGame :one
+SELECT *
+FROM games
+WHERE id = ?
+LIMIT 1;
+-- name: ListGames :many
+SELECT *
+FROM games
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?;
+-- name: CountGames :one
+SELECT COUNT(*)
+FROM games;
+-- name: CreateGame :one
+INSERT INTO games (
+        id,
+        seed,
+        replay_path,
+        map,
+        fog,
+        vision_radius,
+        vision_overlay,
+        squad_rules
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+-- name: FinishGame :exec
+UPDATE games
+SET status = ?,
+    turns = ?,
+    winner = ?,
+    is_draw = ?,
+    abort_reason = ?,
+    timeout_policy = ?,
+    winner_squad = ?,
+    finished_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+-- name: CreateGameSnake :one
+INSERT INTO game_snakes (game_id, snake_id, name, url, squad)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+-- name: ListGameSnakes :many
+SELECT *
+FROM game_snakes
+WHERE game_id = ?
+ORDER BY id ASC;
+-- name: SetGameWinner :exec
+UPDATE game_snakes
+SET is_winner = TRUE
+WHERE game_id = ?
+    AND id = ?;
+-- name: SetSquadWinner :exec
+UPDATE game_snakes
+SET is_winner = TRUE
+WHERE game_id = ?
+    AND squad = ?;
+-- name: SetGameSnakeStats :exec
+UPDATE game_snakes
+SET moves = ?,
+    timeouts = ?,
+    status_errors = ?,
+    decode_errors = ?,
+    latency_histogram = ?
+WHERE game_id = ?
+    AND id = ?;
+-- name: ListSnakeMoveStats :many
+SELECT gs.snake_id,
+    s.team_id,
+    gs.moves,
+    gs.timeouts,
+    gs.status_errors,
+    gs.decode_errors,
+    gs.latency_histogram
+FROM game_snakes gs
+    INNER JOIN snakes s ON s.id = gs.snake_id
+WHERE gs.moves > 0;
+-- name: ListTeamSnakeMoveStats :many
+SELECT gs.snake_id,
+    s.team_id,
+    gs.moves,
+    gs.timeouts,
+    gs.status_errors,
+    gs.decode_errors,
+    gs.latency_histogram
+FROM game_snakes gs
+    INNER JOIN snakes s ON s.id = gs.snake_id
+WHERE s.team_id = ?
+    AND gs.moves > 0;
+-------- API TOKEN --------
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (team_id, name, prefix, token_hash, scopes)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+-- name: FindApiToken :one
+SELECT *
+FROM api_tokens
+WHERE token_hash = ?
+    AND revoked_at IS NULL
+LIMIT 1;
+-- name: ListTeamApiTokens :many
+SELECT *
+FROM api_tokens
+WHERE team_id = ?
+ORDER BY created_at DESC;
+-- name: RevokeApiToken :exec
+UPDATE api_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ?
+    AND team_id = ?
+    AND revoked_at IS NULL;
+-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+-------- UPLOAD --------
+-- name: CreateUpload :one
+INSERT INTO uploads (
+        team_id,
+        snake_id,
+        filename,
+        size,
+        lang,
+        status,
+        findings,
+        remote_addr
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+-- name: ListUploads :many
+SELECT u.*,
+    t.name AS team_name
+FROM uploads u
+    INNER JOIN teams t ON t.id = u.team_id
+ORDER BY u.id DESC
+LIMIT ? OFFSET ?;
+-- name: CountUploads :one
+SELECT COUNT(*)
+FROM uploads;
+-------- AUDIT EVENT --------
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+        team_id,
+        actor,
+        action,
+        target,
+        remote_addr,
+        payload
+    )
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+-- name: ListAuditEvents :many
+SELECT *
+FROM audit_events
+WHERE (
+        sqlc.narg('action') IS NULL
+        OR action = sqlc.narg('action')
+    )
+    AND (
+        sqlc.narg('team_id') IS NULL
+        OR team_id = sqlc.narg('team_id')
+    )
+    AND (
+        sqlc.narg('since') IS NULL
+        OR created_at >= sqlc.narg('since')
+    )
+    AND (
+        sqlc.narg('until') IS NULL
+        OR created_at < sqlc.narg('until')
+    )
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
\ No newline at end of file
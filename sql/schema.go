@@ -0,0 +1,10 @@
+// Package sql embeds the database schema, so binaries can create or upgrade
+// a database without the source tree.
+package sql
+
+import _ "embed"
+
+// Schema creates every table and index. It is idempotent.
+//
+//go:embed SETUP_DB.sql
+var Schema string
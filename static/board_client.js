@@ -91,6 +91,21 @@ function svgCalcCellRect(params, p) {
   return { x: topLeft.x, y: topLeft.y, width: params.cellSize, height: params.cellSize };
 }
 
+function escapeHtml(text) {
+  return text.replace(/[&<>"']/g, c => ({ "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" })[c]);
+}
+
+// snakeColor returns the color of a snake as #RRGGBB, the snakes choose it
+// and it goes into the SVG markup: anything else is the default gray
+function snakeColor(color) {
+  const match = /^#?([0-9a-f]{3}|[0-9a-f]{6})$/i.exec(color || "");
+  if (match == null) {
+    return "#888888";
+  }
+  const hex = match[1].length === 3 ? Array.from(match[1], c => c + c).join("") : match[1];
+  return `#${hex}`;
+}
+
 function svgCalcCellCircle(params, p) {
   const center = svgCalcCellCenter(params, p);
   return { cx: center.x, cy: center.y };
@@ -154,6 +169,8 @@ class SvgRenderer {
   constructor(svgCanvas) {
     this.svgCanvas = svgCanvas;
     this.gameInfo = null;
+    // snake whose vision is shown, in fog of war games
+    this.visionSnakeID = null;
   }
 
   setGameInfo(gameInfo) {
@@ -184,8 +201,10 @@ class SvgRenderer {
     this.svgCanvas.innerHTML = "";
 
     this.renderGrid();
+    this.renderHazards(frame.Data.Hazards || []);
 
     for (let snake of frame.Data.Snakes) {
+      snake.Color = snakeColor(snake.Color);
       if (snake.Name.includes("(Ghost)")) {
         snake.Color = ghostColor(snake.Color);
       }
@@ -212,6 +231,67 @@ class SvgRenderer {
     for (let i = 0; i < frame.Data.Food.length; i++) {
       await this.renderFood(frame.Data.Food[i], i);
     }
+
+    const visionSnake = frame.Data.Snakes.find(snake => snake.ID == this.visionSnakeID);
+    if (visionSnake && visionSnake.Vision) {
+      this.renderVision(visionSnake.Vision);
+    }
+
+    for (let snake of frame.Data.Snakes) {
+      if (snake.Death == null && snake.Shout) {
+        this.renderShout(snake);
+      }
+    }
+  }
+
+  // renderShout draws the shout of a snake in a speech bubble over its head,
+  // long shouts are cut and shown whole on hover
+  renderShout(snake) {
+    const maxChars = 32;
+    const fontSize = 8;
+    const charWidth = fontSize * 0.6;
+    const padding = 3;
+
+    const chars = Array.from(snake.Shout);
+    const text = chars.length > maxChars ? chars.slice(0, maxChars - 1).join("") + "…" : snake.Shout;
+    const width = Math.min(chars.length, maxChars) * charWidth + 2 * padding;
+    const height = fontSize + 2 * padding;
+
+    const head = svgCalcCellCenter(this.calcParams, snake.Body[0]);
+    const x = Math.max(0, Math.min(head.x - width / 2, this.calcParams.width - width));
+    // above the head, or below it on the top row
+    let y = head.y - this.calcParams.cellSizeHalf - height - 4;
+    const below = y < 0;
+    if (below) {
+      y = head.y + this.calcParams.cellSizeHalf + 4;
+    }
+    const tipY = below ? y : y + height;
+    const tipDir = below ? -4 : 4;
+
+    this.svgCanvas.innerHTML += `<g class="shout">
+      <title>${escapeHtml(snake.Name)}: ${escapeHtml(snake.Shout)}</title>
+      <path d="M ${head.x - 3} ${tipY} L ${head.x} ${tipY + tipDir} L ${head.x + 3} ${tipY} Z" fill="white" stroke="${snake.Color}" stroke-width="1" />
+      <rect x="${x}" y="${y}" width="${width}" height="${height}" rx="4" fill="white" stroke="${snake.Color}" stroke-width="1" />
+      <text x="${x + padding}" y="${y + padding + fontSize * 0.85}" font-size="${fontSize}" font-family="monospace" fill="#111827">${escapeHtml(text)}</text>
+    </g>`;
+  }
+
+  // renderVision darkens the cells a snake does not see
+  renderVision(vision) {
+    const visible = new Set(vision.map(p => `${p.X},${p.Y}`));
+    let rawSvg = `<g class="vision">`;
+    for (let i = 0; i < this.gameInfo.Width; i++) {
+      for (let j = 0; j < this.gameInfo.Height; j++) {
+        if (visible.has(`${i},${j}`)) {
+          continue;
+        }
+        const rectParams = svgCalcCellRect(this.calcParams, new Point(i, j));
+        rawSvg += `<rect class="fog" fill="#111827" fill-opacity="0.6"
+         x="${rectParams.x}" y="${rectParams.y}" width="${rectParams.width}" height="${rectParams.height}" />`;
+      }
+    }
+    rawSvg += `</g>`;
+    this.svgCanvas.innerHTML += rawSvg;
   }
 
   renderGrid() {
@@ -227,6 +307,17 @@ class SvgRenderer {
     this.svgCanvas.innerHTML += rawSvg;
   }
 
+  renderHazards(hazards) {
+    let rawSvg = `<g class="hazards">`;
+    for (let hazard of hazards) {
+      const rectParams = svgCalcCellRect(this.calcParams, hazard);
+      rawSvg += `<rect class="hazard" fill="#374151" fill-opacity="0.45"
+       x="${rectParams.x}" y="${rectParams.y}" width="${rectParams.width}" height="${rectParams.height}" />`;
+    }
+    rawSvg += `</g>`;
+    this.svgCanvas.innerHTML += rawSvg;
+  }
+
   async renderFood(food, key) {
     let rawSvg = `<g id="food-${key}" class="food fill-rose-500">`;
     const circleProps = svgCalcCellCircle(this.calcParams, food);
@@ -606,6 +697,8 @@ export default function initGameClient({
   autoPlay = false,
   clearStorage = false,
   onRenderFrame = () => { },
+  onControl = () => { },
+  onControlError = () => { },
 }) {
   const svgCanvas = document.getElementById(SVG_CANVAS_ID);
   if (!svgCanvas) {
@@ -626,6 +719,7 @@ export default function initGameClient({
   let playbackIndex = 0;
   let frameTimer = null;
   let renderer = new SvgRenderer(svgCanvas);
+  let currentFrame = null;
 
   if (frames.length > 0 && gameInfo) {
     renderer.setGameInfo(gameInfo);
@@ -712,6 +806,14 @@ export default function initGameClient({
         const frame = JSON.parse(ev.data);
         console.log("[WS] received frame:", frame);
 
+        if (frame.Type === "control") {
+          onControl(frame.Data);
+          return;
+        }
+        if (frame.Type === "control_error") {
+          onControlError(frame.Data);
+          return;
+        }
         if (frame.Type !== "frame") {
           // game end
           return;
@@ -744,6 +846,7 @@ export default function initGameClient({
   // ---- Rendering ----
   async function renderFrame(frame) {
     console.log("[Render] frame:", frame);
+    currentFrame = frame;
     await renderer.renderFrame(frame);
     onRenderFrame(frame);
   }
@@ -885,12 +988,33 @@ export default function initGameClient({
     setInterval(resumePlayback, 100);
   }
 
+  // showVision shows what the snake with the given ID sees in fog of war
+  // games, or no vision when id is null
+  async function showVision(id) {
+    renderer.visionSnakeID = id;
+    if (currentFrame) {
+      await renderFrame(currentFrame);
+    }
+  }
+
+  // sendCommand drives the game on the server, see game.ControlCommand. It
+  // returns false when the viewer is not connected.
+  function sendCommand(command) {
+    if (!isConnected()) {
+      return false;
+    }
+    ws.send(JSON.stringify(command));
+    return true;
+  }
+
   // ---- Public API ----
   return {
     connect,
+    sendCommand,
     pausePlayback,
     resumePlayback,
     clearStorage,
+    showVision,
     get frames() {
       return frames;
     },
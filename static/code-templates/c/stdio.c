@@ -0,0 +1,320 @@
+#define _GNU_SOURCE
+#include "battlesnake.h"
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <unistd.h>
+
+// Plays a snake over standard input and output, one JSON line per request.
+// Linked with the sources of the snake, without jansson or microhttpd.
+
+SnakeInfo info(void);
+void gameStart(GameState *state);
+MoveResult move(GameState *state);
+void gameEnd(GameState *state);
+
+// -------------------- Minimal JSON reader --------------------
+
+typedef enum { J_NULL, J_BOOL, J_NUMBER, J_STRING, J_ARRAY, J_OBJECT } JsonType;
+
+typedef struct Json {
+    JsonType type;
+    double number;
+    char *string;        // J_STRING
+    char **keys;         // J_OBJECT
+    struct Json **items; // J_ARRAY and J_OBJECT
+    size_t count;
+} Json;
+
+typedef struct {
+    const char *s;
+    int failed;
+} Parser;
+
+static Json *parse_value(Parser *p);
+
+static void skip_space(Parser *p) {
+    while (*p->s == ' ' || *p->s == '\t' || *p->s == '\r' || *p->s == '\n') p->s++;
+}
+
+static Json *new_json(JsonType type) {
+    Json *j = calloc(1, sizeof(Json));
+    j->type = type;
+    return j;
+}
+
+static char *parse_string(Parser *p) {
+    // the caller checked the opening quote
+    p->s++;
+    size_t cap = 16, len = 0;
+    char *out = malloc(cap);
+    while (*p->s && *p->s != '"') {
+        char c = *p->s++;
+        if (c == '\\') {
+            c = *p->s++;
+            switch (c) {
+            case 'n': c = '\n'; break;
+            case 't': c = '\t'; break;
+            case 'r': c = '\r'; break;
+            case 'b': c = '\b'; break;
+            case 'f': c = '\f'; break;
+            case 'u':
+                // names and colors are ASCII, other characters become '?'
+                for (int i = 0; i < 4 && *p->s; i++) p->s++;
+                c = '?';
+                break;
+            case '\0': p->failed = 1; p->s--; break;
+            }
+        }
+        if (len + 1 >= cap) out = realloc(out, cap *= 2);
+        out[len++] = c;
+    }
+    if (*p->s != '"') p->failed = 1;
+    else p->s++;
+    out[len] = '\0';
+    return out;
+}
+
+static void append_item(Json *j, Json *item, char *key) {
+    j->items = realloc(j->items, (j->count + 1) * sizeof(Json *));
+    j->items[j->count] = item;
+    if (j->type == J_OBJECT) {
+        j->keys = realloc(j->keys, (j->count + 1) * sizeof(char *));
+        j->keys[j->count] = key;
+    }
+    j->count++;
+}
+
+static Json *parse_value(Parser *p) {
+    skip_space(p);
+    Json *j;
+    char c = *p->s;
+    if (c == '{' || c == '[') {
+        char close = c == '{' ? '}' : ']';
+        j = new_json(c == '{' ? J_OBJECT : J_ARRAY);
+        p->s++;
+        skip_space(p);
+        if (*p->s == close) {
+            p->s++;
+            return j;
+        }
+        while (!p->failed) {
+            char *key = NULL;
+            if (j->type == J_OBJECT) {
+                skip_space(p);
+                if (*p->s != '"') {
+                    p->failed = 1;
+                    break;
+                }
+                key = parse_string(p);
+                skip_space(p);
+                if (*p->s != ':') {
+                    free(key);
+                    p->failed = 1;
+                    break;
+                }
+                p->s++;
+            }
+            append_item(j, parse_value(p), key);
+            skip_space(p);
+            if (*p->s == ',') {
+                p->s++;
+            } else if (*p->s == close) {
+                p->s++;
+                break;
+            } else {
+                p->failed = 1;
+            }
+        }
+    } else if (c == '"') {
+        j = new_json(J_STRING);
+        j->string = parse_string(p);
+    } else if (strncmp(p->s, "true", 4) == 0 || strncmp(p->s, "false", 5) == 0) {
+        j = new_json(J_BOOL);
+        j->number = c == 't';
+        p->s += c == 't' ? 4 : 5;
+    } else if (strncmp(p->s, "null", 4) == 0) {
+        j = new_json(J_NULL);
+        p->s += 4;
+    } else {
+        char *end;
+        j = new_json(J_NUMBER);
+        j->number = strtod(p->s, &end);
+        if (end == p->s) p->failed = 1;
+        p->s = end;
+    }
+    return j;
+}
+
+static void json_free(Json *j) {
+    if (!j) return;
+    for (size_t i = 0; i < j->count; i++) {
+        json_free(j->items[i]);
+        if (j->keys) free(j->keys[i]);
+    }
+    free(j->items);
+    free(j->keys);
+    free(j->string);
+    free(j);
+}
+
+static Json *json_get(Json *j, const char *key) {
+    if (!j || j->type != J_OBJECT) return NULL;
+    for (size_t i = 0; i < j->count; i++)
+        if (strcmp(j->keys[i], key) == 0) return j->items[i];
+    return NULL;
+}
+
+static int json_int(Json *j) {
+    return j && j->type == J_NUMBER ? (int)j->number : 0;
+}
+
+static size_t json_size(Json *j) {
+    return j && j->type == J_ARRAY ? j->count : 0;
+}
+
+static char *dup_json_string(Json *obj, const char *key) {
+    Json *val = json_get(obj, key);
+    return val && val->type == J_STRING ? strdup(val->string) : NULL;
+}
+
+// writes s as a JSON string
+static void write_string(FILE *out, const char *s) {
+    fputc('"', out);
+    for (; s && *s; s++) {
+        if (*s == '"' || *s == '\\') fprintf(out, "\\%c", *s);
+        else if ((unsigned char)*s < 0x20) fprintf(out, "\\u%04x", *s);
+        else fputc(*s, out);
+    }
+    fputc('"', out);
+}
+
+// -------------------- Game state --------------------
+
+static Coord parse_coord(Json *obj) {
+    Coord c = {0};
+    c.x = json_int(json_get(obj, "x"));
+    c.y = json_int(json_get(obj, "y"));
+    return c;
+}
+
+static Coord *parse_coords(Json *arr, size_t *count) {
+    *count = json_size(arr);
+    Coord *coords = calloc(*count ? *count : 1, sizeof(Coord));
+    for (size_t i = 0; i < *count; i++)
+        coords[i] = parse_coord(arr->items[i]);
+    return coords;
+}
+
+static Snake parse_snake(Json *snake_json) {
+    Snake s = {0};
+
+    s.id = dup_json_string(snake_json, "id");
+    s.name = dup_json_string(snake_json, "name");
+    s.health = json_int(json_get(snake_json, "health"));
+    s.head = parse_coord(json_get(snake_json, "head"));
+    s.body = parse_coords(json_get(snake_json, "body"), &s.length);
+
+    Json *cust = json_get(snake_json, "customizations");
+    if (cust) {
+        s.color = dup_json_string(cust, "color");
+        s.head_type = dup_json_string(cust, "head");
+        s.tail_type = dup_json_string(cust, "tail");
+    }
+
+    return s;
+}
+
+static GameState parse_game_state(Json *root) {
+    GameState gs = {0};
+    gs.turn = json_int(json_get(root, "turn"));
+
+    Json *board = json_get(root, "board");
+    gs.width = json_int(json_get(board, "width"));
+    gs.height = json_int(json_get(board, "height"));
+    gs.food = parse_coords(json_get(board, "food"), &gs.food_count);
+    gs.hazards = parse_coords(json_get(board, "hazards"), &gs.hazard_count);
+
+    Json *snakes = json_get(board, "snakes");
+    gs.snake_count = json_size(snakes);
+    gs.snakes = calloc(gs.snake_count ? gs.snake_count : 1, sizeof(Snake));
+    for (size_t i = 0; i < gs.snake_count; i++)
+        gs.snakes[i] = parse_snake(snakes->items[i]);
+
+    gs.you = parse_snake(json_get(root, "you"));
+
+    return gs;
+}
+
+static void free_snake(Snake *s) {
+    free(s->id);
+    free(s->name);
+    free(s->body);
+    free(s->color);
+    free(s->head_type);
+    free(s->tail_type);
+}
+
+static void free_game_state(GameState *gs) {
+    for (size_t i = 0; i < gs->snake_count; i++)
+        free_snake(&gs->snakes[i]);
+    free(gs->snakes);
+    free(gs->food);
+    free(gs->hazards);
+    free_snake(&gs->you);
+}
+
+// -------------------- Main loop --------------------
+
+int main(void) {
+    // standard output carries the answers, what the snake prints goes to
+    // standard error
+    FILE *out = fdopen(dup(STDOUT_FILENO), "w");
+    dup2(STDERR_FILENO, STDOUT_FILENO);
+    setvbuf(stdout, NULL, _IOLBF, 0);
+    setvbuf(stderr, NULL, _IOLBF, 0);
+
+    char *line = NULL;
+    size_t cap = 0;
+    while (getline(&line, &cap, stdin) > 0) {
+        Parser p = {line, 0};
+        Json *message = parse_value(&p);
+        Json *type = json_get(message, "type");
+        Json *request = json_get(message, "request");
+
+        if (p.failed || !type || type->type != J_STRING) {
+            fprintf(out, "{\"error\":\"invalid json\"}\n");
+        } else if (strcmp(type->string, "info") == 0) {
+            SnakeInfo info_struct = info();
+            fprintf(out, "{\"apiversion\":\"1\",\"author\":\"IFSP\",\"color\":");
+            write_string(out, info_struct.color);
+            fprintf(out, ",\"head\":");
+            write_string(out, info_struct.head);
+            fprintf(out, ",\"tail\":");
+            write_string(out, info_struct.tail);
+            fprintf(out, "}\n");
+        } else {
+            GameState gs = parse_game_state(request);
+            if (strcmp(type->string, "move") == 0) {
+                MoveResult res = move(&gs);
+                fprintf(out, "{\"move\":");
+                write_string(out, res.move);
+                if (res.taunt) {
+                    fprintf(out, ",\"shout\":");
+                    write_string(out, res.taunt);
+                }
+                fprintf(out, "}\n");
+            } else {
+                if (strcmp(type->string, "start") == 0) gameStart(&gs);
+                else if (strcmp(type->string, "end") == 0) gameEnd(&gs);
+                fprintf(out, "{}\n");
+            }
+            free_game_state(&gs);
+        }
+        fflush(out);
+        json_free(message);
+    }
+
+    free(line);
+    return 0;
+}
@@ -0,0 +1,65 @@
+#!/usr/bin/env node
+// battlesnake_stdio.js
+// Plays a snake over standard input and output, one JSON line per request
+
+const path = require("path");
+const readline = require("readline");
+
+// ---- Utility: Dynamic import of a JS module ----
+function importDynamic(modulePath) {
+    const absPath = path.resolve(modulePath);
+    delete require.cache[absPath]; // ensure reload
+    return require(absPath);
+}
+
+// ---- Main loop: each line is a request, answered by one line ----
+function runStdio(handlers, write) {
+    const lines = readline.createInterface({ input: process.stdin, terminal: false });
+
+    lines.on("line", line => {
+        let answer = {};
+        try {
+            const message = JSON.parse(line);
+            if (message.type === "info") {
+                answer = handlers.info();
+                answer.author = "IFSP";
+                answer.apiversion = "1";
+            } else if (message.type === "move") {
+                answer = handlers.move(message.request);
+            } else {
+                handlers[message.type](message.request);
+            }
+        } catch (err) {
+            console.error("Error handling request:", err);
+            answer = { error: String(err) };
+        }
+        write(JSON.stringify(answer) + "\n");
+    });
+}
+
+// ---- Entry point ----
+if (require.main === module) {
+    const [, , snakePath] = process.argv;
+    if (!snakePath) {
+        console.error("Usage: node battlesnake_stdio.js <snake.js>");
+        process.exit(1);
+    }
+
+    // standard output carries the answers, what the snake logs goes to
+    // standard error
+    const write = process.stdout.write.bind(process.stdout);
+    console.log = console.error;
+    console.info = console.error;
+
+    const snake = importDynamic(snakePath);
+
+    runStdio(
+        {
+            info: snake.info,
+            start: snake.start,
+            move: snake.move,
+            end: snake.end,
+        },
+        write
+    );
+}
@@ -0,0 +1,57 @@
+import importlib.util
+import json
+import os
+import sys
+import traceback
+
+
+def run_stdio(handlers, out):
+    # each line is a request of the engine, answered by one line
+    for line in sys.stdin:
+        try:
+            message = json.loads(line)
+            kind = message["type"]
+            if kind == "info":
+                answer = handlers["info"]()
+                answer["author"] = "IFSP"
+                answer["apiversion"] = "1"
+            elif kind == "move":
+                answer = handlers["move"](message["request"])
+            else:
+                handlers[kind](message["request"])
+                answer = {}
+        except Exception as err:
+            traceback.print_exc()
+            answer = {"error": str(err)}
+        out.write(json.dumps(answer) + "\n")
+        out.flush()
+
+
+def import_dynamic(name, module_path):
+    spec = importlib.util.spec_from_file_location(name, module_path)
+    module = importlib.util.module_from_spec(spec)
+    spec.loader.exec_module(module)
+    return module
+
+
+if __name__ == "__main__":
+    snake_path = sys.argv[1]
+
+    # standard output carries the answers, what the snake prints goes to
+    # standard error
+    out = sys.stdout
+    sys.stdout = sys.stderr
+
+    # let multi-file projects import their sibling modules
+    sys.path.insert(0, os.path.dirname(os.path.abspath(snake_path)))
+    snake = import_dynamic("snake", snake_path)
+
+    run_stdio(
+        {
+            "info": snake.info,
+            "start": snake.start,
+            "move": snake.move,
+            "end": snake.end,
+        },
+        out,
+    )
@@ -0,0 +1,49 @@
+// Presenter controls: they pause, step and pace the game on the server, for
+// every viewer. Only the admins get them, the server refuses the commands of
+// the other viewers.
+
+// sendCommand sends a game control command, { command, delay_ms }, on the
+// game websocket and returns false when the viewer is not connected.
+export default function initGameControls({ sendCommand = () => false } = {}) {
+    const controls = document.getElementById("game-controls");
+    if (!controls) {
+        return { update() { }, showError() { } };
+    }
+
+    const btnPause = document.getElementById("ctl-pause");
+    const btnResume = document.getElementById("ctl-resume");
+    const btnStep = document.getElementById("ctl-step");
+    const selectDelay = document.getElementById("ctl-delay");
+    const status = document.getElementById("ctl-status");
+
+    function send(command, delayMs) {
+        if (!sendCommand({ command, delay_ms: delayMs })) {
+            showToast({ message: "Sem conexão com a partida", type: "error" });
+        }
+    }
+
+    btnPause.addEventListener("click", () => send("pause"));
+    btnResume.addEventListener("click", () => send("resume"));
+    btnStep.addEventListener("click", () => send("step"));
+    selectDelay.addEventListener("change", () => send("delay", Number(selectDelay.value)));
+
+    return {
+        // update shows the state of the controls sent by the server
+        update(controlStatus) {
+            console.log("[Controls] status:", controlStatus);
+            btnPause.style.display = controlStatus.Paused ? "none" : "inline";
+            btnResume.style.display = controlStatus.Paused ? "inline" : "none";
+            btnStep.disabled = !controlStatus.Paused;
+            status.textContent = controlStatus.Paused ? "pausada" : "rodando";
+
+            const delay = String(controlStatus.TurnDelay);
+            if (![...selectDelay.options].some((option) => option.value === delay)) {
+                selectDelay.add(new Option(`${delay} ms`, delay));
+            }
+            selectDelay.value = delay;
+        },
+        showError(controlError) {
+            showToast({ message: `Comando ${controlError.Command} recusado: ${controlError.Error}`, type: "error" });
+        },
+    };
+}
@@ -7,6 +7,21 @@ function snakeIdToName(frame, id) {
     return "DESCONHECIDO";
 }
 
+function escapeHtml(text) {
+    return text.replace(/[&<>"']/g, c => ({ "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" })[c]);
+}
+
+// snakeColor returns the color of a snake as #RRGGBB, the snakes choose it
+// and it goes into the markup: anything else is the default gray
+function snakeColor(color) {
+    const match = /^#?([0-9a-f]{3}|[0-9a-f]{6})$/i.exec(color || "");
+    if (match == null) {
+        return "#888888";
+    }
+    const hex = match[1].length === 3 ? Array.from(match[1], c => c + c).join("") : match[1];
+    return `#${hex}`;
+}
+
 function eliminationToString(frame, elimination) {
     // Ver https://github.com/BattlesnakeOfficial/rules/blob/master/standard.go
     switch (elimination.Cause) {
@@ -22,12 +37,30 @@ function eliminationToString(frame, elimination) {
             return `Perdeu um jogo-de-cabeça com ${snakeIdToName(elimination.EliminatedBy)} no Turno ${elimination.Turn}`;
         case "wall-collision":
             return `Saiu dos limites no Turno ${elimination.Turn}`;
+        case "map-wall":
+            return `Bateu em uma parede do mapa no Turno ${elimination.Turn}`;
+        case "squad-eliminated":
+            return `Eliminada junto com seu squad no Turno ${elimination.Turn}`;
         default:
             return elimination.Cause;
     }
 }
 
-export default function initScoreboard() {
+// onSelectSnake is called with the ID of the snake clicked on the scoreboard,
+// or null when the selected snake is clicked again.
+export default function initScoreboard({ onSelectSnake = () => { } } = {}) {
+    let selectedSnakeID = null;
+
+    const scoreboardSnakes = document.getElementById("scoreboard-snakes");
+    scoreboardSnakes.addEventListener("click", (event) => {
+        const row = event.target.closest("[data-snake-id]");
+        if (!row) {
+            return;
+        }
+        selectedSnakeID = row.dataset.snakeId == selectedSnakeID ? null : row.dataset.snakeId;
+        onSelectSnake(selectedSnakeID);
+    });
+
     return {
         updateScoreboard(frame) {
             console.log("[Scoreboard] updating scoreboard");
@@ -35,7 +68,6 @@ export default function initScoreboard() {
             scoreboardTurn.textContent = frame.Data.Turn;
 
             const sortedSnakes = frame.Data.Snakes.sort((s1, s2) => s1.Name.localeCompare(s2.Name));
-            const scoreboardSnakes = document.getElementById("scoreboard-snakes");
             scoreboardSnakes.innerHTML = "";
 
             for (let snake of sortedSnakes) {
@@ -43,23 +75,24 @@ export default function initScoreboard() {
 
                 const snakeHealthHtml = snake.Death ? `<p>${eliminationToString(frame, snake.Death)}</p>` : `
                 <div class="text-outline w-full h-full rounded-full bg-neutral-200 mt-2">
-                    <div class="transition-all h-full rounded-full text-white ps-2" style="background: ${snake.Color}; width: ${snake.Health}%">
+                    <div class="transition-all h-full rounded-full text-white ps-2" style="background: ${snakeColor(snake.Color)}; width: ${snake.Health}%">
                         ${snake.Health}
                     </div>
                 </div>`;
 
                 let snakeHtml = `
-                <div class="p-2 cursor-pointer rounded-lg border-solid border-2 border-transparent hover:border-gray-300 hover:bg-gray-200" ${snake.Death ? "class='eliminated'" : ""}
-                    role="presentation">
+                <div class="p-2 cursor-pointer rounded-lg border-solid border-2 ${snake.ID == selectedSnakeID ? "border-gray-400" : "border-transparent"} hover:border-gray-300 hover:bg-gray-200" ${snake.Death ? "class='eliminated'" : ""}
+                    role="presentation" data-snake-id="${snake.ID}" ${snake.Vision ? `title="Clique para ver o que ela enxerga"` : ""}>
                     <div class="flex flex-row font-bold">
                         <p class="grow truncate">${snake.Name}</p>
                         <p class="ps-4 text-right">${snake.Body.length}</p>
                     </div>
                     <div class="flex flex-row text-xs">
-                        <p class="grow"></p>
+                        <p class="grow truncate">${snake.Squad ? `Squad ${escapeHtml(snake.Squad)}` : ""}</p>
                         <p class="text-right">${snake.Latency ? `${snake.Latency}ms` : ""}</p>
                     </div>
                     ${snakeHealthHtml}
+                    ${snake.Shout && !snake.Death ? `<p class="mt-1 text-xs italic text-gray-600 truncate" title="${escapeHtml(snake.Shout)}">“${escapeHtml(snake.Shout)}”</p>` : ""}
                 </div>`;
 
                 scoreboardSnakes.innerHTML += snakeHtml;
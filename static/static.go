@@ -0,0 +1,8 @@
+// Package static embeds the assets served under /static/ and the snake
+// server templates, so the binary runs without the source tree.
+package static
+
+import "embed"
+
+//go:embed *.css *.svg *.js code-templates
+var FS embed.FS
@@ -29,7 +29,10 @@ type SvgCalcParams struct {
 	Width        float64
 }
 
-func Gameboard(w, h int, boardData BoardData, gameID string) templ.Component {
+// Gameboard shows the game with the given ID, or the team's practice games
+// when gameID is empty. With controls, the viewer gets the presenter controls
+// driving the game on the server.
+func Gameboard(w, h int, boardData BoardData, gameID string, controls bool) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -68,25 +71,35 @@ func Gameboard(w, h int, boardData BoardData, gameID string) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		if gameID == "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"flex w-full \"><div class=\"flex py-2 h-min items-center gap-2\"><div class=\"h-10 w-10 rounded-xl bg-pink-100 text-pink-700 grid place-items-center text-xl\">🔃</div><h2 class=\"text-xl font-bold text-gray-900\">Simular</h2></div><div class=\" flex flex-col gap-3 items-end flex-grow\"><button id=\"btn-refresh-game\" type=\"submit\" class=\"w-48 px-4 py-2 bg-pink-600 text-white font-semibold rounded-lg shadow hover:bg-pink-700 transition\">Atualizar</button> <label for=\"ghost-checkbox\" class=\"flex items-center gap-2 cursor-pointer\" role=\"checkbox\" aria-checked=\"false\" aria-label=\"Simular com upload anterior\" title=\"Simular com upload anterior\">Upload anterior <input type=\"checkbox\" name=\"ghost\" id=\"ghost-checkbox\" class=\"hidden peer\"><div class=\"w-4 h-4 rounded-full bg-gray-200 peer-checked:bg-gradient-to-r from-pink-600 to-pink-800 shadow\"></div></label></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"flex w-full \"><div class=\"flex py-2 h-min items-center gap-2\"><div class=\"h-10 w-10 rounded-xl bg-pink-100 text-pink-700 grid place-items-center text-xl\">🔃</div><h2 class=\"text-xl font-bold text-gray-900\">Simular</h2></div><div class=\" flex flex-col gap-3 items-end flex-grow\"><button id=\"btn-refresh-game\" type=\"submit\" class=\"w-48 px-4 py-2 bg-pink-600 text-white font-semibold rounded-lg shadow hover:bg-pink-700 transition\">Atualizar</button><div class=\"flex items-center gap-2\"><select id=\"human-opponent\" class=\"rounded-lg border border-gray-300 bg-white px-2 py-2 text-sm\" aria-label=\"Adversário\"><option value=\"snake\">contra sua Snake</option> <option value=\"bot\">contra o bot</option></select> <button id=\"btn-human-game\" type=\"button\" class=\"w-48 px-4 py-2 bg-white text-pink-700 font-semibold rounded-lg border border-pink-200 shadow-sm hover:bg-pink-50 transition\" title=\"Controle uma snake com as setas do teclado\">Jogar você mesmo</button></div><label for=\"ghost-checkbox\" class=\"flex items-center gap-2 cursor-pointer\" role=\"checkbox\" aria-checked=\"false\" aria-label=\"Simular com upload anterior\" title=\"Simular com upload anterior\">Upload anterior <input type=\"checkbox\" name=\"ghost\" id=\"ghost-checkbox\" class=\"hidden peer\"><div class=\"w-4 h-4 rounded-full bg-gray-200 peer-checked:bg-gradient-to-r from-pink-600 to-pink-800 shadow\"></div></label></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div class=\"flex gap-4 flex-row\"><div class=\"flex-shrik w-full\"><div class=\"w-full flex justify-center\"><svg role=\"button\" id=\"btn-first-frame\" class=\"playback-btn cursor-pointer mx-1\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"first\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Primeiro Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"18,7 18,21 10,14\" fill=\"#6B7280\"></polygon> <rect x=\"8\" y=\"7\" width=\"2\" height=\"14\" rx=\"1\" fill=\"#6B7280\"></rect></svg> <svg class=\"playback-btn cursor-pointer mx-1\" role=\"button\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"prev\" id=\"btn-prev-frame\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Voltar um Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"17,7 17,21 11,14\" fill=\"#6B7280\"></polygon></svg> <svg id=\"btn-play\" class=\"playback-btn cursor-pointer mx-1\" role=\"button\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"play\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\" style=\"display: inline;\"><title>Play</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"11,8 11,20 19,14\" fill=\"#6B7280\"></polygon></svg> <svg id=\"btn-pause\" role=\"button\" class=\"playback-btn cursor-pointer mx-1\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"pause\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\" style=\"display: none;\"><title>Pausar</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <rect x=\"9\" y=\"8\" width=\"3\" height=\"12\" rx=\"1\" fill=\"#6B7280\"></rect> <rect x=\"16\" y=\"8\" width=\"3\" height=\"12\" rx=\"1\" fill=\"#6B7280\"></rect></svg> <svg id=\"btn-next-frame\" role=\"button\" class=\"playback-btn cursor-pointer mx-1\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"next\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Avançar um Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"11,7 11,21 17,14\" fill=\"#6B7280\"></polygon></svg> <svg id=\"btn-last-frame\" class=\"playback-btn cursor-pointer mx-1\" role=\"button\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"last\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Último Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"10,7 10,21 18,14\" fill=\"#6B7280\"></polygon> <rect x=\"18\" y=\"7\" width=\"2\" height=\"14\" rx=\"1\" fill=\"#6B7280\"></rect></svg></div><svg id=\"gameboard\" class=\"gameboard\" viewBox=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<div class=\"flex gap-4 flex-row\"><div class=\"flex-shrik w-full\"><div class=\"w-full flex justify-center\"><svg role=\"button\" id=\"btn-first-frame\" class=\"playback-btn cursor-pointer mx-1\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"first\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Primeiro Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"18,7 18,21 10,14\" fill=\"#6B7280\"></polygon> <rect x=\"8\" y=\"7\" width=\"2\" height=\"14\" rx=\"1\" fill=\"#6B7280\"></rect></svg> <svg class=\"playback-btn cursor-pointer mx-1\" role=\"button\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"prev\" id=\"btn-prev-frame\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Voltar um Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"17,7 17,21 11,14\" fill=\"#6B7280\"></polygon></svg> <svg id=\"btn-play\" class=\"playback-btn cursor-pointer mx-1\" role=\"button\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"play\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\" style=\"display: inline;\"><title>Play</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"11,8 11,20 19,14\" fill=\"#6B7280\"></polygon></svg> <svg id=\"btn-pause\" role=\"button\" class=\"playback-btn cursor-pointer mx-1\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"pause\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\" style=\"display: none;\"><title>Pausar</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <rect x=\"9\" y=\"8\" width=\"3\" height=\"12\" rx=\"1\" fill=\"#6B7280\"></rect> <rect x=\"16\" y=\"8\" width=\"3\" height=\"12\" rx=\"1\" fill=\"#6B7280\"></rect></svg> <svg id=\"btn-next-frame\" role=\"button\" class=\"playback-btn cursor-pointer mx-1\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"next\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Avançar um Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"11,7 11,21 17,14\" fill=\"#6B7280\"></polygon></svg> <svg id=\"btn-last-frame\" class=\"playback-btn cursor-pointer mx-1\" role=\"button\" width=\"28\" height=\"28\" viewBox=\"0 0 28 28\" data-action=\"last\" fill=\"none\" xmlns=\"http://www.w3.org/2000/svg\"><title>Último Frame</title><rect width=\"28\" height=\"28\" rx=\"6\" fill=\"#F3F4F6\"></rect> <polygon points=\"10,7 10,21 18,14\" fill=\"#6B7280\"></polygon> <rect x=\"18\" y=\"7\" width=\"2\" height=\"14\" rx=\"1\" fill=\"#6B7280\"></rect></svg></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if controls && gameID != "" {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div id=\"game-controls\" class=\"mt-3 flex flex-wrap items-center justify-center gap-2 text-sm\" title=\"Controla a partida no servidor, para todos os espectadores\"><span class=\"font-semibold text-gray-700\">Partida <span id=\"ctl-status\" class=\"font-normal text-gray-500\">rodando</span></span> <button id=\"ctl-pause\" type=\"button\" class=\"px-3 py-1 rounded-lg font-semibold bg-white text-indigo-700 border border-indigo-200 shadow-sm hover:bg-indigo-50 transition disabled:opacity-50 disabled:cursor-not-allowed\">Pausar</button> <button id=\"ctl-resume\" type=\"button\" class=\"px-3 py-1 rounded-lg font-semibold bg-white text-indigo-700 border border-indigo-200 shadow-sm hover:bg-indigo-50 transition disabled:opacity-50 disabled:cursor-not-allowed\" style=\"display: none;\">Retomar</button> <button id=\"ctl-step\" type=\"button\" class=\"px-3 py-1 rounded-lg font-semibold bg-white text-indigo-700 border border-indigo-200 shadow-sm hover:bg-indigo-50 transition disabled:opacity-50 disabled:cursor-not-allowed\" disabled>Avançar turno</button> <label for=\"ctl-delay\" class=\"flex items-center gap-2 text-gray-700\">Intervalo <select id=\"ctl-delay\" class=\"rounded-lg border border-gray-300 bg-white px-2 py-1\"><option value=\"0\">sem intervalo</option> <option value=\"250\">250 ms</option> <option value=\"500\">500 ms</option> <option value=\"1000\">1 s</option> <option value=\"2000\">2 s</option> <option value=\"5000\">5 s</option></select></label></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<svg id=\"gameboard\" class=\"gameboard\" viewBox=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(svgViewBox)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/components/gameboard.templ`, Line: 155, Col: 62}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/components/gameboard.templ`, Line: 184, Col: 62}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -94,34 +107,44 @@ func Gameboard(w, h int, boardData BoardData, gameID string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</svg></div><aside id=\"scoreboard\" class=\"max-w-sm w-full flex flex-col\"><h3 class=\"text-lg font-semibold text-gray-900 mb-2\">Turno <span id=\"scoreboard-turn\">0</span></h3><div id=\"scoreboard-snakes\"></div></aside></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "</svg></div><aside id=\"scoreboard\" class=\"max-w-sm w-full flex flex-col\"><h3 class=\"text-lg font-semibold text-gray-900 mb-2\">Turno <span id=\"scoreboard-turn\">0</span></h3><div id=\"scoreboard-snakes\"></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if gameID == "" {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<p id=\"human-hint\" class=\"mt-3 text-sm text-gray-600\" style=\"display: none;\">Use as setas do teclado (ou W, A, S, D) para controlar a sua snake.</p>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</aside></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		if gameID == "" {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<script type=\"module\">\n\t\t\t\timport initGameClient from \"/static/board_client.js\";\n\t\t\t\timport initScoreboard from \"/static/scoreboard.js\";\n\n\t\t\t\tlet scoreboard = initScoreboard();\n\n\t\t\t\tlet gameClient = initGameClient({\n\t\t\t\t\tonRenderFrame: scoreboard.updateScoreboard,\n\t\t\t\t});\n\n\t\t\t\tconst btnRefreshGame = document.getElementById(\"btn-refresh-game\");\n\t\t\t\tif (btnRefreshGame) {\n\t\t\t\t\tbtnRefreshGame.addEventListener(\"click\", async () => {\n\t\t\t\t\t\tconst ghost = document.getElementById(\"ghost-checkbox\").checked;\n\t\t\t\t\t\tfetch(\"/create-game?ghost=\" + ghost, {\n\t\t\t\t\t\t\tmethod: \"POST\"\n\t\t\t\t\t\t}).then((res) => {\n\t\t\t\t\t\t\t// if no content\n\t\t\t\t\t\t\tif (res.status == 204) {\n\t\t\t\t\t\t\t\tthrow new Error(\"Nenhuma Snake online\");\n\t\t\t\t\t\t\t}\n\n\t\t\t\t\t\t\tif (res.status != 201) {\n\t\t\t\t\t\t\t\tthrow new Error(\"Erro ao criar jogo\");\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\treturn res.text();\n\t\t\t\t\t\t}).then(async (gameId) => {\n\t\t\t\t\t\t\tawait gameClient.connect(`/game/${gameId}`).catch((err) => {\n\t\t\t\t\t\t\t\tconsole.error(\"[Refresh] Error connecting to game:\", err);\n\t\t\t\t\t\t\t});\n\t\t\t\t\t\t}).catch((err) => {\n\t\t\t\t\t\t\tshowToast({message: err.message, type: \"error\"});\n\t\t\t\t\t\t});\n\t\t\t\t\t});\n\t\t\t\t}\n\t\t\t</script>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<script type=\"module\">\n\t\t\t\timport initGameClient from \"/static/board_client.js\";\n\t\t\t\timport initScoreboard from \"/static/scoreboard.js\";\n\n\t\t\t\tlet gameClient;\n\t\t\t\tlet scoreboard = initScoreboard({\n\t\t\t\t\tonSelectSnake: (id) => gameClient.showVision(id),\n\t\t\t\t});\n\n\t\t\t\tgameClient = initGameClient({\n\t\t\t\t\tonRenderFrame: scoreboard.updateScoreboard,\n\t\t\t\t});\n\n\t\t\t\t// in human games the arrow keys steer the snake of the team\n\t\t\t\tconst moveKeys = {\n\t\t\t\t\tArrowUp: \"up\", ArrowDown: \"down\", ArrowLeft: \"left\", ArrowRight: \"right\",\n\t\t\t\t\tw: \"up\", s: \"down\", a: \"left\", d: \"right\",\n\t\t\t\t};\n\t\t\t\tlet humanGame = false;\n\t\t\t\tdocument.addEventListener(\"keydown\", (event) => {\n\t\t\t\t\tconst move = moveKeys[event.key];\n\t\t\t\t\tif (!humanGame || !move || event.target.closest(\"input, select, textarea\")) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tevent.preventDefault();\n\t\t\t\t\tgameClient.sendCommand({ command: \"move\", move });\n\t\t\t\t});\n\n\t\t\t\tfunction createGame(params, human) {\n\t\t\t\t\tfetch(\"/create-game?\" + params, {\n\t\t\t\t\t\tmethod: \"POST\"\n\t\t\t\t\t}).then((res) => {\n\t\t\t\t\t\t// if no content\n\t\t\t\t\t\tif (res.status == 204) {\n\t\t\t\t\t\t\tthrow new Error(\"Nenhuma Snake online\");\n\t\t\t\t\t\t}\n\n\t\t\t\t\t\tif (res.status == 503) {\n\t\t\t\t\t\t\treturn res.text().then((message) => {\n\t\t\t\t\t\t\t\tthrow new Error(message || \"Servidor ocupado\");\n\t\t\t\t\t\t\t});\n\t\t\t\t\t\t}\n\n\t\t\t\t\t\tif (res.status != 201) {\n\t\t\t\t\t\t\tthrow new Error(\"Erro ao criar jogo\");\n\t\t\t\t\t\t}\n\t\t\t\t\t\treturn res.text();\n\t\t\t\t\t}).then(async (gameId) => {\n\t\t\t\t\t\thumanGame = human;\n\t\t\t\t\t\tdocument.getElementById(\"human-hint\").style.display = human ? \"block\" : \"none\";\n\t\t\t\t\t\tawait gameClient.connect(`/game/${gameId}`).catch((err) => {\n\t\t\t\t\t\t\tconsole.error(\"[Refresh] Error connecting to game:\", err);\n\t\t\t\t\t\t});\n\t\t\t\t\t\tif (human) {\n\t\t\t\t\t\t\t// the player needs to see each turn as soon as it is played\n\t\t\t\t\t\t\tgameClient.resumePlayback(100);\n\t\t\t\t\t\t}\n\t\t\t\t\t}).catch((err) => {\n\t\t\t\t\t\tshowToast({message: err.message, type: \"error\"});\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\tconst btnRefreshGame = document.getElementById(\"btn-refresh-game\");\n\t\t\t\tif (btnRefreshGame) {\n\t\t\t\t\tbtnRefreshGame.addEventListener(\"click\", () => {\n\t\t\t\t\t\tconst ghost = document.getElementById(\"ghost-checkbox\").checked;\n\t\t\t\t\t\tcreateGame(\"ghost=\" + ghost, false);\n\t\t\t\t\t});\n\t\t\t\t}\n\t\t\t\tconst btnHumanGame = document.getElementById(\"btn-human-game\");\n\t\t\t\tif (btnHumanGame) {\n\t\t\t\t\tbtnHumanGame.addEventListener(\"click\", () => {\n\t\t\t\t\t\tconst opponent = document.getElementById(\"human-opponent\").value;\n\t\t\t\t\t\tcreateGame(\"human=true&opponent=\" + opponent, true);\n\t\t\t\t\t});\n\t\t\t\t}\n\t\t\t</script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		} else {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<script type=\"module\">\n\t\t\t\tlet gameid = ")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "<script type=\"module\">\n\t\t\t\tlet gameid = ")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			templ_7745c5c3_Var3, templ_7745c5c3_Err := templruntime.ScriptContentOutsideStringLiteral(gameID)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/components/gameboard.templ`, Line: 203, Col: 26}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/components/gameboard.templ`, Line: 276, Col: 26}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var3)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, ";\n\t\t\t\timport initGameClient from \"/static/board_client.js\";\n\t\t\t\timport initScoreboard from \"/static/scoreboard.js\";\n\n\t\t\t\tlet scoreboard = initScoreboard();\n\n\t\t\t\tlet gameClient = initGameClient({\n\t\t\t\t\tonRenderFrame: scoreboard.updateScoreboard,\n\t\t\t\t\tclearStorage: true\n\t\t\t\t});\n\n\t\t\t\tgameClient.connect(`/game/${gameid}`).catch((err) => {\n\t\t\t\t\tconsole.error(\"[Refresh] Error connecting to game:\", err);\n\t\t\t\t});\n\t\t\t</script>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, ";\n\t\t\t\timport initGameClient from \"/static/board_client.js\";\n\t\t\t\timport initScoreboard from \"/static/scoreboard.js\";\n\t\t\t\timport initGameControls from \"/static/game_controls.js\";\n\n\t\t\t\tlet gameClient;\n\t\t\t\tlet scoreboard = initScoreboard({\n\t\t\t\t\tonSelectSnake: (id) => gameClient.showVision(id),\n\t\t\t\t});\n\t\t\t\tlet controls = initGameControls({\n\t\t\t\t\tsendCommand: (command) => gameClient.sendCommand(command),\n\t\t\t\t});\n\n\t\t\t\tgameClient = initGameClient({\n\t\t\t\t\tonRenderFrame: scoreboard.updateScoreboard,\n\t\t\t\t\tonControl: controls.update,\n\t\t\t\t\tonControlError: controls.showError,\n\t\t\t\t\tclearStorage: true,\n\t\t\t\t\tautoPlay: new URLSearchParams(location.search).has(\"autoplay\"),\n\t\t\t\t});\n\n\t\t\t\tgameClient.connect(`/game/${gameid}`).catch((err) => {\n\t\t\t\t\tconsole.error(\"[Refresh] Error connecting to game:\", err);\n\t\t\t\t});\n\t\t\t</script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "</section>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -74,7 +74,7 @@ func Dashboard(teamName string, isAdmin bool) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</h1><p class=\"text-sm text-gray-600\">Envie a sua Snake e acompanhe o status em tempo real.</p></div><div class=\"flex items-center gap-3\"></div></div><div class=\"mt-4 p-5 rounded-2xl bg-gray-100 border border-gray-200 shadow\"><p class=\"text-sm text-gray-700\">Faça o download de um projeto inicial para começar.</p><div class=\"flex flex-wrap gap-3 mt-3\"><a href=\"/static/code-templates/c/starter.zip\" class=\"px-5 py-2 rounded-full text-sm font-bold text-gray-100 bg-slate-600\" style=\"box-shadow: 6px 6px 12px rgba(0,0,0,0.08), -6px -6px 12px rgba(255,255,255,0.9);\">C</a> <a href=\"/static/code-templates/js/starter.zip\" class=\"px-5 py-2 rounded-full text-sm font-bold text-gray-100 bg-yellow-600\" style=\"box-shadow: 6px 6px 12px rgba(0,0,0,0.08), -6px -6px 12px rgba(255,255,255,0.9);\">JavaScript</a> <a href=\"/static/code-templates/py/starter.zip\" class=\"px-5 py-2 rounded-full text-sm font-bold text-gray-100 bg-emerald-600\" style=\"box-shadow: 6px 6px 12px rgba(0,0,0,0.08), -6px -6px 12px rgba(255,255,255,0.9);\">Python</a></div></div></section><!-- Main grid: upload card --><div class=\"grid grid-cols-1 gap-8\"><section class=\"rounded-3xl bg-white border border-gray-200 shadow-sm p-8\"><div class=\"flex items-start gap-4 mb-6\"><div class=\"h-10 w-10 rounded-xl bg-pink-100 text-pink-700 grid place-items-center text-xl\">⬆️</div><div><h2 class=\"text-xl font-bold text-gray-900\">Enviar sua Snake</h2><p class=\"text-sm text-gray-600\">Solte o arquivo em qualquer lugar da tela ou use o botão abaixo.</p></div><div class=\"ml-auto flex items-center gap-3 rounded-full px-4 py-2\" style=\"background:#f3f4f6; box-shadow: 8px 8px 16px rgba(0,0,0,0.08), -8px -8px 16px rgba(255,255,255,0.8);\"><span class=\"text-xs text-gray-600\">Status</span> <span id=\"snake-status\" hx-get=\"/status\" hx-trigger=\"load, every 1.5s\" hx-swap=\"innerHTML\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</h1><p class=\"text-sm text-gray-600\">Envie a sua Snake e acompanhe o status em tempo real.</p></div><div class=\"flex items-center gap-3\"></div></div><div class=\"mt-4 p-5 rounded-2xl bg-gray-100 border border-gray-200 shadow\"><p class=\"text-sm text-gray-700\">Faça o download de um projeto inicial para começar.</p><div class=\"flex flex-wrap gap-3 mt-3\"><a href=\"/static/code-templates/c/starter.zip\" class=\"px-5 py-2 rounded-full text-sm font-bold text-gray-100 bg-slate-600\" style=\"box-shadow: 6px 6px 12px rgba(0,0,0,0.08), -6px -6px 12px rgba(255,255,255,0.9);\">C</a> <a href=\"/static/code-templates/js/starter.zip\" class=\"px-5 py-2 rounded-full text-sm font-bold text-gray-100 bg-yellow-600\" style=\"box-shadow: 6px 6px 12px rgba(0,0,0,0.08), -6px -6px 12px rgba(255,255,255,0.9);\">JavaScript</a> <a href=\"/static/code-templates/py/starter.zip\" class=\"px-5 py-2 rounded-full text-sm font-bold text-gray-100 bg-emerald-600\" style=\"box-shadow: 6px 6px 12px rgba(0,0,0,0.08), -6px -6px 12px rgba(255,255,255,0.9);\">Python</a></div></div></section><!-- Main grid: upload card --><div class=\"grid grid-cols-1 gap-8\"><section class=\"rounded-3xl bg-white border border-gray-200 shadow-sm p-8\"><div class=\"flex items-start gap-4 mb-6\"><div class=\"h-10 w-10 rounded-xl bg-pink-100 text-pink-700 grid place-items-center text-xl\">⬆️</div><div><h2 class=\"text-xl font-bold text-gray-900\">Enviar sua Snake</h2><p class=\"text-sm text-gray-600\">Solte o arquivo em qualquer lugar da tela ou use o botão abaixo.</p><p class=\"text-xs text-gray-500\">Projetos com vários arquivos: envie um .zip ou .tar.gz com snake.py, snake.js ou os arquivos .c na raiz.</p></div><div class=\"ml-auto flex items-center gap-3 rounded-full px-4 py-2\" style=\"background:#f3f4f6; box-shadow: 8px 8px 16px rgba(0,0,0,0.08), -8px -8px 16px rgba(255,255,255,0.8);\"><span class=\"text-xs text-gray-600\">Status</span> <span id=\"snake-status\" hx-get=\"/status\" hx-trigger=\"load, every 1.5s\" hx-swap=\"innerHTML\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -82,7 +82,7 @@ func Dashboard(teamName string, isAdmin bool) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</span></div></div><form id=\"upload-form\" action=\"/upload-snake\" method=\"POST\" enctype=\"multipart/form-data\" hx-post=\"/upload-snake\" hx-encoding=\"multipart/form-data\" hx-target=\"#upload-result\" hx-swap=\"innerHTML\" class=\"space-y-5\"><!-- Pretty drop zone --><div id=\"drop-zone\" class=\"w-full rounded-2xl border-2 border-dashed border-gray-300 hover:border-pink-400 transition p-8 text-center bg-gray-50 cursor-pointer\"><div class=\"mx-auto mb-3 h-14 w-14 grid place-items-center rounded-2xl bg-white text-pink-600 shadow-sm\">📁</div><p class=\"text-sm text-gray-600\">Arraste e solte seu arquivo aqui</p><p class=\"text-xs text-gray-500\">ou clique para selecionar do seu computador</p><input id=\"snake-file\" type=\"file\" name=\"snake\" accept=\".py,.js,.c\" class=\"sr-only\"></div><div class=\"flex flex-wrap items-center gap-3\"><button id=\"upload-submit\" type=\"submit\" class=\"px-5 py-2.5 bg-pink-600 text-white font-semibold rounded-lg shadow hover:bg-pink-700 transition opacity-50 cursor-not-allowed\" disabled>Enviar</button> <span class=\"text-sm\">Selecionado: <span id=\"selected-file\" class=\"font-medium text-gray-900\">Nenhum arquivo</span></span> <span class=\"ml-auto text-xs text-gray-500\">.py · .js · .c • Máx 2MB</span></div></form><div id=\"upload-result\" class=\"mt-4 text-sm text-gray-700\"></div></section><!-- Board preview -->")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</span></div></div><form id=\"upload-form\" action=\"/upload-snake\" method=\"POST\" enctype=\"multipart/form-data\" hx-post=\"/upload-snake\" hx-encoding=\"multipart/form-data\" hx-target=\"#upload-result\" hx-swap=\"innerHTML\" class=\"space-y-5\"><!-- Pretty drop zone --><div id=\"drop-zone\" class=\"w-full rounded-2xl border-2 border-dashed border-gray-300 hover:border-pink-400 transition p-8 text-center bg-gray-50 cursor-pointer\"><div class=\"mx-auto mb-3 h-14 w-14 grid place-items-center rounded-2xl bg-white text-pink-600 shadow-sm\">📁</div><p class=\"text-sm text-gray-600\">Arraste e solte seu arquivo aqui</p><p class=\"text-xs text-gray-500\">ou clique para selecionar do seu computador</p><input id=\"snake-file\" type=\"file\" name=\"snake\" accept=\".py,.js,.c,.zip,.tar.gz,.tgz\" class=\"sr-only\"></div><div class=\"flex flex-wrap items-center gap-3\"><button id=\"upload-submit\" type=\"submit\" class=\"px-5 py-2.5 bg-pink-600 text-white font-semibold rounded-lg shadow hover:bg-pink-700 transition opacity-50 cursor-not-allowed\" disabled>Enviar</button> <span class=\"text-sm\">Selecionado: <span id=\"selected-file\" class=\"font-medium text-gray-900\">Nenhum arquivo</span></span> <span class=\"ml-auto text-xs text-gray-500\">.py · .js · .c · .zip · .tar.gz • Máx 2MB</span></div></form><div id=\"upload-result\" class=\"mt-4 text-sm text-gray-700\"></div></section><!-- Move latency --><div hx-get=\"/move-stats\" hx-trigger=\"load\" hx-swap=\"outerHTML\"></div><!-- API tokens --><div hx-get=\"/tokens\" hx-trigger=\"load\" hx-swap=\"outerHTML\"></div><!-- Board preview -->")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -91,11 +91,11 @@ func Dashboard(teamName string, isAdmin bool) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = components.Gameboard(11, 11, boardData, "").Render(ctx, templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = components.Gameboard(11, 11, boardData, "", false).Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</section></div><!-- Global drag/drop overlay (hidden by default) --><div id=\"drop-overlay\" class=\"hidden fixed inset-0 z-40 bg-black/60 backdrop-blur-sm items-center justify-center\"><div class=\"rounded-2xl border-4 border-dashed border-white/70 text-white px-10 py-8 text-center\"><p class=\"text-lg font-semibold\">Solte o arquivo em qualquer lugar para enviar</p><p class=\"text-sm opacity-90 mt-1\">Formatos: .py, .js, .c • Máx 2MB</p></div></div><!-- Behavior --><script>\n                    (function(){\n                        var ALLOWED = ['.py','.js','.c'];\n                        function hasAllowedExt(name){\n                            var idx = name.lastIndexOf('.');\n                            if (idx < 0) return false;\n                            var ext = name.slice(idx).toLowerCase();\n                            return ALLOWED.indexOf(ext) !== -1;\n                        }\n\n                        function setup(){\n                            var input = document.getElementById('snake-file');\n                            var btn = document.getElementById('upload-submit');\n                            var nameEl = document.getElementById('selected-file');\n                            var drop = document.getElementById('drop-zone');\n                            var overlay = document.getElementById('drop-overlay');\n                            var form = document.getElementById('upload-form');\n                            if (!input || !btn || !nameEl || !drop) return;\n\n                            function update(){\n                                var f = input.files && input.files[0];\n                                if (f){\n                                    btn.disabled = false;\n                                    btn.classList.remove('opacity-50','cursor-not-allowed');\n                                    nameEl.textContent = f.name;\n                                } else {\n                                    btn.disabled = true;\n                                    btn.classList.add('opacity-50','cursor-not-allowed');\n                                    nameEl.textContent = 'Nenhum arquivo';\n                                }\n                            }\n\n                            function showDrop(){ drop.classList.add('border-pink-400','bg-pink-50'); }\n                            function hideDrop(){ drop.classList.remove('border-pink-400','bg-pink-50'); }\n\n                            drop.addEventListener('click', function(){ input.click(); });\n                            drop.addEventListener('dragover', function(e){ e.preventDefault(); e.stopPropagation(); showDrop(); });\n                            drop.addEventListener('dragenter', function(e){ e.preventDefault(); e.stopPropagation(); showDrop(); });\n                            drop.addEventListener('dragleave', function(e){ e.preventDefault(); e.stopPropagation(); hideDrop(); });\n                            drop.addEventListener('drop', function(e){\n                                e.preventDefault(); e.stopPropagation(); hideDrop();\n                                var files = e.dataTransfer && e.dataTransfer.files;\n                                if (!files || !files.length) return;\n                                var f = files[0];\n                                if (!hasAllowedExt(f.name)){\n                                    try { document.body.dispatchEvent(new CustomEvent('show-toast', { detail: 'Formato inválido. Use .py, .js ou .c' })); } catch(_){ }\n                                    return;\n                                }\n                                // Assign the dropped file to the input\n                                try {\n                                    var dt = new DataTransfer();\n                                    dt.items.add(f);\n                                    input.files = dt.files;\n                                    update();\n                                } catch(_) {\n                                    // Fallback: just show name and enable button\n                                    nameEl.textContent = f.name;\n                                    btn.disabled = false;\n                                    btn.classList.remove('opacity-50','cursor-not-allowed');\n                                }\n                            });\n\n                            // Global overlay drag-n-drop for entire screen\n                            var overCounter = 0; // handle nested dragenter/leaves\n                            function showOverlay(){ if (overlay){ overlay.classList.remove('hidden'); overlay.classList.add('flex'); } }\n                            function hideOverlay(){ if (overlay){ overlay.classList.add('hidden'); overlay.classList.remove('flex'); } }\n\n                            document.addEventListener('dragenter', function(e){ e.preventDefault(); e.stopPropagation(); overCounter++; showOverlay(); });\n                            document.addEventListener('dragover', function(e){ e.preventDefault(); e.stopPropagation(); });\n                            document.addEventListener('dragleave', function(e){ e.preventDefault(); e.stopPropagation(); overCounter = Math.max(0, overCounter-1); if (overCounter === 0) hideOverlay(); });\n                            document.addEventListener('drop', function(e){\n                                e.preventDefault(); e.stopPropagation();\n                                overCounter = 0; hideOverlay();\n                                if (!e.dataTransfer || !e.dataTransfer.files || !e.dataTransfer.files.length) return;\n                                var f = e.dataTransfer.files[0];\n                                if (!hasAllowedExt(f.name)){\n                                    try { document.body.dispatchEvent(new CustomEvent('show-toast', { detail: 'Formato inválido. Use .py, .js ou .c' })); } catch(_){ }\n                                    return;\n                                }\n                                // If dropped outside the form, still capture the file\n                                try {\n                                    var dt = new DataTransfer();\n                                    dt.items.add(f);\n                                    input.files = dt.files;\n                                    update();\n                                } catch(_) {\n                                    nameEl.textContent = f.name;\n                                    btn.disabled = false;\n                                    btn.classList.remove('opacity-50','cursor-not-allowed');\n                                }\n                            });\n\n                            input.addEventListener('change', function(){\n                                var f = input.files && input.files[0];\n                                if (f && !hasAllowedExt(f.name)){\n                                    try { document.body.dispatchEvent(new CustomEvent('show-toast', { detail: 'Formato inválido. Use .py, .js ou .c' })); } catch(_){ }\n                                    input.value = '';\n                                }\n                                update();\n                            });\n\n                            // After HTMX upload completes, clear selection\n                            if (form) {\n                                form.addEventListener('htmx:afterOnLoad', function(){\n                                    try { input.value = ''; } catch(_){ }\n                                    update();\n                                });\n                            }\n\n                            update();\n                        }\n                        if (document.readyState === 'loading') { document.addEventListener('DOMContentLoaded', setup); } else { setup(); }\n                    })();\n\t\t\t\t</script></main></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</section></div><!-- Global drag/drop overlay (hidden by default) --><div id=\"drop-overlay\" class=\"hidden fixed inset-0 z-40 bg-black/60 backdrop-blur-sm items-center justify-center\"><div class=\"rounded-2xl border-4 border-dashed border-white/70 text-white px-10 py-8 text-center\"><p class=\"text-lg font-semibold\">Solte o arquivo em qualquer lugar para enviar</p><p class=\"text-sm opacity-90 mt-1\">Formatos: .py, .js, .c, .zip, .tar.gz • Máx 2MB</p></div></div><!-- Behavior --><script>\n                    (function(){\n                        var ALLOWED = ['.py','.js','.c','.zip','.gz','.tgz'];\n                        function hasAllowedExt(name){\n                            var idx = name.lastIndexOf('.');\n                            if (idx < 0) return false;\n                            var ext = name.slice(idx).toLowerCase();\n                            return ALLOWED.indexOf(ext) !== -1;\n                        }\n\n                        function setup(){\n                            var input = document.getElementById('snake-file');\n                            var btn = document.getElementById('upload-submit');\n                            var nameEl = document.getElementById('selected-file');\n                            var drop = document.getElementById('drop-zone');\n                            var overlay = document.getElementById('drop-overlay');\n                            var form = document.getElementById('upload-form');\n                            if (!input || !btn || !nameEl || !drop) return;\n\n                            function update(){\n                                var f = input.files && input.files[0];\n                                if (f){\n                                    btn.disabled = false;\n                                    btn.classList.remove('opacity-50','cursor-not-allowed');\n                                    nameEl.textContent = f.name;\n                                } else {\n                                    btn.disabled = true;\n                                    btn.classList.add('opacity-50','cursor-not-allowed');\n                                    nameEl.textContent = 'Nenhum arquivo';\n                                }\n                            }\n\n                            function showDrop(){ drop.classList.add('border-pink-400','bg-pink-50'); }\n                            function hideDrop(){ drop.classList.remove('border-pink-400','bg-pink-50'); }\n\n                            drop.addEventListener('click', function(){ input.click(); });\n                            drop.addEventListener('dragover', function(e){ e.preventDefault(); e.stopPropagation(); showDrop(); });\n                            drop.addEventListener('dragenter', function(e){ e.preventDefault(); e.stopPropagation(); showDrop(); });\n                            drop.addEventListener('dragleave', function(e){ e.preventDefault(); e.stopPropagation(); hideDrop(); });\n                            drop.addEventListener('drop', function(e){\n                                e.preventDefault(); e.stopPropagation(); hideDrop();\n                                var files = e.dataTransfer && e.dataTransfer.files;\n                                if (!files || !files.length) return;\n                                var f = files[0];\n                                if (!hasAllowedExt(f.name)){\n                                    try { document.body.dispatchEvent(new CustomEvent('show-toast', { detail: 'Formato inválido. Use .py, .js, .c, .zip ou .tar.gz' })); } catch(_){ }\n                                    return;\n                                }\n                                // Assign the dropped file to the input\n                                try {\n                                    var dt = new DataTransfer();\n                                    dt.items.add(f);\n                                    input.files = dt.files;\n                                    update();\n                                } catch(_) {\n                                    // Fallback: just show name and enable button\n                                    nameEl.textContent = f.name;\n                                    btn.disabled = false;\n                                    btn.classList.remove('opacity-50','cursor-not-allowed');\n                                }\n                            });\n\n                            // Global overlay drag-n-drop for entire screen\n                            var overCounter = 0; // handle nested dragenter/leaves\n                            function showOverlay(){ if (overlay){ overlay.classList.remove('hidden'); overlay.classList.add('flex'); } }\n                            function hideOverlay(){ if (overlay){ overlay.classList.add('hidden'); overlay.classList.remove('flex'); } }\n\n                            document.addEventListener('dragenter', function(e){ e.preventDefault(); e.stopPropagation(); overCounter++; showOverlay(); });\n                            document.addEventListener('dragover', function(e){ e.preventDefault(); e.stopPropagation(); });\n                            document.addEventListener('dragleave', function(e){ e.preventDefault(); e.stopPropagation(); overCounter = Math.max(0, overCounter-1); if (overCounter === 0) hideOverlay(); });\n                            document.addEventListener('drop', function(e){\n                                e.preventDefault(); e.stopPropagation();\n                                overCounter = 0; hideOverlay();\n                                if (!e.dataTransfer || !e.dataTransfer.files || !e.dataTransfer.files.length) return;\n                                var f = e.dataTransfer.files[0];\n                                if (!hasAllowedExt(f.name)){\n                                    try { document.body.dispatchEvent(new CustomEvent('show-toast', { detail: 'Formato inválido. Use .py, .js, .c, .zip ou .tar.gz' })); } catch(_){ }\n                                    return;\n                                }\n                                // If dropped outside the form, still capture the file\n                                try {\n                                    var dt = new DataTransfer();\n                                    dt.items.add(f);\n                                    input.files = dt.files;\n                                    update();\n                                } catch(_) {\n                                    nameEl.textContent = f.name;\n                                    btn.disabled = false;\n                                    btn.classList.remove('opacity-50','cursor-not-allowed');\n                                }\n                            });\n\n                            input.addEventListener('change', function(){\n                                var f = input.files && input.files[0];\n                                if (f && !hasAllowedExt(f.name)){\n                                    try { document.body.dispatchEvent(new CustomEvent('show-toast', { detail: 'Formato inválido. Use .py, .js, .c, .zip ou .tar.gz' })); } catch(_){ }\n                                    input.value = '';\n                                }\n                                update();\n                            });\n\n                            // After HTMX upload completes, clear selection\n                            if (form) {\n                                form.addEventListener('htmx:afterOnLoad', function(){\n                                    try { input.value = ''; } catch(_){ }\n                                    update();\n                                });\n                            }\n\n                            update();\n                        }\n                        if (document.readyState === 'loading') { document.addEventListener('DOMContentLoaded', setup); } else { setup(); }\n                    })();\n\t\t\t\t</script></main></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -64,7 +64,7 @@ func Hello(name string) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "!</h1><p class=\"mt-2 text-sm text-gray-600\">Prepare sua Snake, envie o arquivo e acompanhe tudo em tempo real.</p><div class=\"mt-6 flex items-center justify-center gap-3\"><a href=\"/login\" class=\"px-5 py-2.5 bg-pink-600 text-white font-semibold rounded-lg shadow hover:bg-pink-700 transition\">Entrar</a> <a href=\"https://docs.battlesnake.com\" target=\"_blank\" class=\"px-5 py-2.5 rounded-lg border border-gray-300 text-gray-700 hover:bg-gray-50 transition\">Documentação</a></div></section></main></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "!</h1><p class=\"mt-2 text-sm text-gray-600\">Prepare sua Snake, envie o arquivo e acompanhe tudo em tempo real.</p><div class=\"mt-6 flex items-center justify-center gap-3\"><a href=\"/login\" class=\"px-5 py-2.5 bg-pink-600 text-white font-semibold rounded-lg shadow hover:bg-pink-700 transition\">Entrar</a> <a href=\"https://docs.battlesnake.com\" target=\"_blank\" class=\"px-5 py-2.5 rounded-lg border border-gray-300 text-gray-700 hover:bg-gray-50 transition\">Documentação</a> <a href=\"/live\" class=\"px-5 py-2.5 rounded-lg border border-gray-300 text-gray-700 hover:bg-gray-50 transition\">Ao vivo</a></div></section></main></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}